@@ -5,23 +5,115 @@ import (
 	"context"
 	"fmt"
 	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"time"
 )
 
+// defaultAddChunkSize bounds the members of one ZADD, larger additions are pipelined in chunks
+const defaultAddChunkSize = 500
+
 type ItemList struct {
 	skipList  *skiplist.SkipList
 	batchSize int
 	client    redis.UniversalClient
 	prefix    string
+
+	namespaceCheck   bool
+	namespaceStrict  bool
+	namespaceChecked bool
+	database         int
+	checkDatabase    bool
+
+	maintainNodeCount       bool
+	readOnly                bool
+	maxListingNodes         int
+	recentWrites            *recentNames
+	checksum                bool
+	onSplit                 func(oldNodeId, newNodeId int64, pivot string)
+	onMerge                 func(keptNodeId, removedNodeId int64)
+	commandStats            *commandStats
+	countCommands           bool
+	clientState             *ClientState
+	namePrefix              string
+	probeLex                bool
+	nodeKeyEncoding         NodeKeyEncoding
+	nodeKeyEncodingSet      bool
+	nodeKeysState           int8
+	previousNodeKeyEncoding NodeKeyEncoding
+	nodeKeys                map[int64]string
+	insertDirection         InsertDirection
+	lastInsert              string
+	insertTrend             int
+	tracer                  trace.Tracer
+	prefetchDepth           int
+	nameScores              bool
+	newMinimumInNewNode     bool
+	minSaneBatchSize        int
+	maxSaneBatchSize        int
+	maxChainNodes           int
+	nodeShardLength         int
+	listingVersionCheck     bool
+	maxAddChunk             int
+	orderKey                func(name string) string
+	safeMode                bool
+	caseInsensitive         bool
+	collisionPolicy         NameCollisionPolicy
+	clock                   Clock
+	mirrorClient            redis.UniversalClient
+	opts                    []ItemListOption
+	writeBuffer             *writeBuffer
+	nameCipher              NameCipher
+	maintenanceConfig       *MaintenanceConfig
+	maintenance             *maintenance
+	nodeReuseCheck          bool
+	strictNames             bool
+	directoryVersion        bool
+	// slowOperationThreshold is set by WithSlowOperationLog
+	slowOperationThreshold time.Duration
 }
 
-func newItemList(client redis.UniversalClient, prefix string, store skiplist.ListStore, batchSize int) *ItemList {
-	return &ItemList{
-		skipList:  skiplist.New(store),
-		batchSize: batchSize,
-		client:    client,
-		prefix:    prefix,
+func newItemList(client redis.UniversalClient, prefix string, store skiplist.ListStore, batchSize int, opts ...ItemListOption) (*ItemList, error) {
+	nl := &ItemList{
+		skipList:         skiplist.New(store),
+		batchSize:        batchSize,
+		client:           client,
+		prefix:           prefix,
+		minSaneBatchSize: defaultMinSaneBatchSize,
+		maxSaneBatchSize: defaultMaxSaneBatchSize,
+		clock:            realClock{},
+	}
+	for _, opt := range opts {
+		opt(nl)
+	}
+	nl.opts = opts
+	if err := nl.checkBatchSize(batchSize); err != nil {
+		return nil, err
+	}
+	if err := nl.resolveClientState(); err != nil {
+		return nil, err
 	}
+	if nl.nodeShardLength > 0 && nl.probeLex {
+		return nil, fmt.Errorf("%w: WithNodeShards needs the lex commands, WithLexFallback is not supported", ErrInvalidOptions)
+	}
+	if nl.nameCipher != nil && nl.orderKey != nil {
+		return nil, fmt.Errorf("%w: WithNameCipher can not be combined with WithOrderKey or WithCaseInsensitive", ErrInvalidOptions)
+	}
+	if nl.mirrorClient != nil {
+		if _, ok := store.(*SkipListElementStore); !ok {
+			return nil, fmt.Errorf("%w: WithMirror needs the redis list store, got %T", ErrInvalidOptions, store)
+		}
+	}
+	if nl.maintenanceConfig != nil {
+		if nl.maintenanceConfig.Compact && nl.maintenanceConfig.Lock == nil {
+			return nil, fmt.Errorf("%w: WithMaintenance needs a Lock to compact", ErrInvalidOptions)
+		}
+		nl.startMaintenance()
+	}
+	return nl, nil
 }
 
 /*
@@ -52,7 +144,9 @@ There are multiple cases after finding the name for greater or equal node
     }
 
 	// case 2.4
+	// now name sorts before all names, and nextNode is the first node
 	// merge into next node. Avoid too many nodes if adding data in reverse order.
+	// With WithNewMinimumInNewNode, skip to case 2.5.
 	if nextNode is not nil and nextNode has capacity
 	  delete nextNode.Key
       nextNode.Key = name
@@ -67,14 +161,60 @@ There are multiple cases after finding the name for greater or equal node
 
 */
 
-func (nl *ItemList) canAddMember(node *skiplist.SkipListElementReference, name string) (alreadyContains bool, nodeSize int, err error) {
-	ctx := context.Background()
+// resolveNeighbors finds the nodes around name: next is the first node with a key greater or equal to name,
+// or nil if found is false, and prev is the node before it, which would hold name, or nil if there is none.
+// prev is read from the skiplist references, without loading the element.
+func (nl *ItemList) resolveNeighbors(ctx context.Context, name string) (prev *skiplist.SkipListElementReference, next *skiplist.SkipListElement, found bool, err error) {
+	prevNode, next, found, err := nl.skipList.FindGreaterOrEqualContext(ctx, []byte(name))
+	if err != nil {
+		return nil, nil, false, err
+	}
+	switch {
+	case !found:
+		if prev, err = nl.largestNodeReference(ctx); err != nil {
+			return nil, nil, false, err
+		}
+	case prevNode != nil:
+		prev = prevNode.Reference()
+	default:
+		prev = next.Prev
+	}
+	return prev, next, found, nil
+}
+
+// largestNodeReference is GetLargestNodeReference, cross checked against the first node:
+// a list with a first node but no last one is walked to its last node, or fails with WithSafeMode,
+// instead of being taken as empty, which would start a new node before the existing ones.
+func (nl *ItemList) largestNodeReference(ctx context.Context) (*skiplist.SkipListElementReference, error) {
+	t := nl.skipList
+	if largest := t.GetLargestNodeReference(); largest != nil || t.StartLevels[0] == nil {
+		return largest, nil
+	}
+	glog.Errorf("skiplist %s: no last node, but first node %d", nl.prefix, t.StartLevels[0].ElementPointer)
+	if nl.safeMode {
+		return nil, fmt.Errorf("%s: %w: no last node", nl.prefix, ErrInconsistentSkiplist)
+	}
+	var last *skiplist.SkipListElementReference
+	err := nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		last = node.Reference()
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return last, nil
+}
+
+func (nl *ItemList) canAddMember(ctx context.Context, node *skiplist.SkipListElementReference, name string) (alreadyContains bool, nodeSize int, err error) {
+	if nl.maintainNodeCount {
+		return nl.canAddMemberByNodeCount(ctx, node, name)
+	}
 	pipe := nl.client.TxPipeline()
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
-	countOperation := pipe.ZLexCount(ctx, key, "-", "+")
-	scoreOperationt := pipe.ZScore(ctx, key, name)
+	key := nl.nodeKey(node.ElementPointer)
+	countOperation := nl.zLexCountAll(ctx, pipe, key)
+	scoreOperationt := pipe.ZScore(ctx, nl.memberKey(key, name), name)
 	if _, err = pipe.Exec(ctx); err != nil && err != redis.Nil {
-		return false, 0, err
+		return false, 0, wrapNodeKeyError(key, err)
 	}
 	if err == redis.Nil {
 		err = nil
@@ -84,116 +224,273 @@ func (nl *ItemList) canAddMember(node *skiplist.SkipListElementReference, name s
 	return
 }
 
-func (nl *ItemList) WriteName(name string) error {
+// WriteName adds name to the list, if ValidateName, or ValidateNameStrict with WithStrictNames, accepts it. The empty name is rejected with ErrEmptyName,
+// since "" is reserved as the "scan from the beginning" marker of ListNames
+// and can not be a skiplist key. Its Redis commands use ctx.
+func (nl *ItemList) WriteName(ctx context.Context, name string) error {
+	_, err := nl.addName(ctx, name)
+	return err
+}
 
-	lookupKey := []byte(name)
-	prevNode, nextNode, found, err := nl.skipList.FindGreaterOrEqual(lookupKey)
-	if err != nil {
-		return err
+// WriteNameWithSplit is WriteName, also telling whether adding name split a full node.
+// Adding to a node with room, or creating a new node, is not a split.
+func (nl *ItemList) WriteNameWithSplit(ctx context.Context, name string) (splitHappened bool, err error) {
+	outcome, err := nl.addName(ctx, name)
+	return outcome.split, err
+}
+
+func (nl *ItemList) addName(ctx context.Context, name string) (outcome writeOutcome, err error) {
+	span := nl.startSpan(ctx, "WriteName", attribute.String("redis3.name", name))
+	defer func() {
+		span.end(nl, err,
+			attribute.String("redis3.case", string(outcome.writeCase)),
+			attribute.Bool("redis3.added", outcome.added),
+			attribute.Bool("redis3.split", outcome.split),
+			attribute.Int64("redis3.node_id", outcome.nodeId))
+	}()
+	if nl.readOnly {
+		return writeOutcome{}, ErrReadOnly
 	}
-	// case 1: the name already exists as one leading key in the batch
-	if found && bytes.Compare(nextNode.Key, lookupKey) == 0 {
-		return nil
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return writeOutcome{}, err
+	}
+
+	if err := nl.validateName(name); err != nil {
+		return writeOutcome{}, err
 	}
+	if err := nl.checkOrderKey(name); err != nil {
+		return writeOutcome{}, err
+	}
+	if skip, err := nl.resolveCaseCollision(ctx, name); skip || err != nil {
+		return writeOutcome{}, err
+	}
+	realName := name
+	name = nl.storedName(name)
+	nl.observeInsert(name)
 
-	var prevNodeReference *skiplist.SkipListElementReference
-	if !found {
-		prevNodeReference = nl.skipList.GetLargestNodeReference()
+	now := nl.clock.Now()
+	if nl.recentWrites != nil && nl.recentWrites.contains(name, now) {
+		return writeOutcome{}, nil
+	}
+	if nl.writeBuffer != nil {
+		return writeOutcome{}, nl.bufferName(ctx, name, now)
+	}
+	outcome, err = nl.writeName(ctx, name)
+	if err != nil && !outcome.added {
+		return outcome, err
+	}
+	if outcome.added {
+		if err := nl.adjustChecksum(ctx, name, 1); err != nil {
+			return outcome, err
+		}
+		if err := nl.bumpDirectoryVersion(ctx); err != nil {
+			return outcome, err
+		}
+	}
+	if err != nil {
+		// the name was stored but the split failed, so a retry finds it and finishes as a write of a stored name
+		return outcome, err
+	}
+	if nl.recentWrites != nil {
+		nl.recentWrites.add(name, now)
 	}
+	nl.mirror(ctx, "write", realName, func(mirror *ItemList) error {
+		return mirror.WriteName(ctx, realName)
+	})
+	return outcome, nil
+}
 
-	if nextNode != nil && prevNode == nil {
-		prevNodeReference = nextNode.Prev
+// writeOutcome tells what one write did
+type writeOutcome struct {
+	writeCase WriteCase
+	added     bool
+	// split is set when a full node was split, case 2.3
+	split bool
+	// nodeId is the node holding the name, 0 if unknown
+	nodeId int64
+}
+
+func (nl *ItemList) writeName(ctx context.Context, name string) (outcome writeOutcome, err error) {
+
+	lookupKey := []byte(name)
+	prevNodeReference, nextNode, found, err := nl.resolveNeighbors(ctx, name)
+	if err != nil {
+		return writeOutcome{}, err
+	}
+	// case 1: the name already exists as one leading key in the batch
+	if found && bytes.Compare(nextNode.Key, lookupKey) == 0 {
+		return writeOutcome{writeCase: WriteCaseExists, nodeId: nextNode.Id}, nil
 	}
 
 	if prevNodeReference != nil {
-		alreadyContains, nodeSize, err := nl.canAddMember(prevNodeReference, name)
+		alreadyContains, nodeSize, err := nl.canAddMember(ctx, prevNodeReference, name)
 		if err != nil {
-			return err
+			return writeOutcome{}, err
 		}
 		if alreadyContains {
 			// case 2.1
-			return nil
+			return writeOutcome{writeCase: WriteCaseInNode, nodeId: prevNodeReference.ElementPointer}, nil
+		}
+
+		if nodeSize == 0 {
+			if preSplit, err := nl.isPreSplit(ctx, prevNodeReference); err != nil || preSplit {
+				if err == nil {
+					err = nl.fillPreSplitNode(ctx, prevNodeReference, name)
+				}
+				return writeOutcome{writeCase: WriteCaseAddToNode, added: err == nil, nodeId: prevNodeReference.ElementPointer}, err
+			}
+			// an empty node still referenced by the skiplist, e.g. left over by a failed split
+			glog.V(1).Infof("remove empty node %s%d with key %s", nl.prefix, prevNodeReference.ElementPointer, string(prevNodeReference.Key))
+			end, err := nl.beginStructuralChange(ctx)
+			if err != nil {
+				return writeOutcome{}, err
+			}
+			defer end()
+			id, err := nl.skipList.DeleteByKeyContext(ctx, prevNodeReference.Key)
+			if err != nil {
+				return writeOutcome{}, err
+			}
+			if id != prevNodeReference.ElementPointer {
+				// routing again would find the same node
+				return writeOutcome{}, fmt.Errorf("remove empty node %s%d with key %q: %w: not found by its key",
+					nl.prefix, prevNodeReference.ElementPointer, string(prevNodeReference.Key), ErrInconsistentSkiplist)
+			}
+			if err := nl.nodeDelete(ctx, prevNodeReference); err != nil {
+				return writeOutcome{}, err
+			}
+			return nl.writeName(ctx, name)
+		}
+
+		// with descending inserts, later names also go before nextNode, so keep prevNode full
+		if nl.effectiveInsertDirection() == InsertDescending {
+			x, err := nl.nodeInnerPosition(ctx, prevNodeReference, name)
+			if err != nil {
+				return writeOutcome{}, err
+			}
+			if x == nodeSize {
+				if added, err := nl.addToNextNode(ctx, nextNode, lookupKey, name); added || err != nil {
+					return writeOutcome{writeCase: WriteCaseAddToNextNode, added: added, nodeId: nextNode.Id}, err
+				}
+			}
 		}
 
 		// case 2.2
 		if nodeSize < nl.batchSize {
-			return nl.NodeAddMember(prevNodeReference, name)
+			if err := nl.nodeAddMember(ctx, prevNodeReference, name); err != nil {
+				return writeOutcome{}, err
+			}
+			return writeOutcome{writeCase: WriteCaseAddToNode, added: true, nodeId: prevNodeReference.ElementPointer}, nil
 		}
 
 		// case 2.3
-		x := nl.NodeInnerPosition(prevNodeReference, name)
+		if nl.safeMode && nodeSize > nl.batchSize {
+			return writeOutcome{}, nl.refuseOverCapacity(prevNodeReference.ElementPointer, nodeSize)
+		}
+		x, err := nl.nodeInnerPosition(ctx, prevNodeReference, name)
+		if err != nil {
+			return writeOutcome{}, err
+		}
 		y := nodeSize - x
-		addToX := x <= y
+		addToX := nl.splitToLowerHalf(x, y)
+		// name is after all names of the full prevNode, prefer the next node to a new node of one name
+		if y == 0 {
+			if added, err := nl.addToNextNode(ctx, nextNode, lookupKey, name); added || err != nil {
+				return writeOutcome{writeCase: WriteCaseAddToNextNode, added: added, nodeId: nextNode.Id}, err
+			}
+		}
+		// name is before all names of the full prevNode, whose key is before its smallest name, e.g. left by
+		// an interrupted re-key: re-key it and route the name again, to the previous node if it has capacity
+		if x == 0 {
+			if rekeyed, err := nl.rekeyStaleNode(ctx, prevNodeReference, nextNode); rekeyed || err != nil {
+				if err != nil {
+					return writeOutcome{}, err
+				}
+				return nl.writeName(ctx, name)
+			}
+		}
 		// add to a new node
 		if x == 0 || y == 0 {
-			if err := nl.ItemAdd(lookupKey, 0, name); err != nil {
-				return err
+			newNodeId, err := nl.itemAdd(ctx, lookupKey, 0, name)
+			if err != nil {
+				return writeOutcome{}, err
 			}
-			return nil
+			return writeOutcome{writeCase: WriteCaseSplit, added: true, nodeId: newNodeId}, nil
+		}
+		end, err := nl.beginStructuralChange(ctx)
+		if err != nil {
+			return writeOutcome{}, err
 		}
+		defer end()
 		if addToX {
 			// collect names before name, add them to X
-			namesToX, err := nl.NodeRangeBeforeExclusive(prevNodeReference, name)
+			namesToX, err := nl.nodeRangeBeforeExclusive(ctx, prevNodeReference, name)
 			if err != nil {
-				return nil
+				return writeOutcome{}, err
 			}
 			// delete skiplist reference to old node
-			if _, err := nl.skipList.DeleteByKey(prevNodeReference.Key); err != nil {
-				return err
+			if _, err := nl.skipList.DeleteByKeyContext(ctx, prevNodeReference.Key); err != nil {
+				return writeOutcome{}, err
 			}
 			// add namesToY and name to a new X
 			namesToX = append(namesToX, name)
-			if err := nl.ItemAdd([]byte(namesToX[0]), 0, namesToX...); err != nil {
-				return nil
+			newNodeId, err := nl.itemAdd(ctx, []byte(namesToX[0]), 0, namesToX...)
+			if err != nil {
+				return writeOutcome{}, err
 			}
+			// the name is stored from here on, a failure leaves names in both nodes, or Y out of the skiplist
+			outcome := writeOutcome{writeCase: WriteCaseSplit, added: true, split: true, nodeId: newNodeId}
 			// remove names less than name from current Y
-			if err := nl.NodeDeleteBeforeExclusive(prevNodeReference, name); err != nil {
-				return nil
+			if err := nl.nodeDeleteBeforeExclusive(ctx, prevNodeReference, name); err != nil {
+				return outcome, err
 			}
 
-			// point skip list to current Y
-			if err := nl.ItemAdd(lookupKey, prevNodeReference.ElementPointer); err != nil {
-				return nil
+			// point skip list to current Y, which now starts after name
+			minName, err := nl.nodeMin(ctx, prevNodeReference)
+			if err != nil {
+				return outcome, err
 			}
-			return nil
+			if _, err := nl.itemAdd(ctx, []byte(minName), prevNodeReference.ElementPointer); err != nil {
+				return outcome, err
+			}
+			nl.notifySplit(prevNodeReference.ElementPointer, newNodeId, name)
+			return outcome, nil
 		} else {
 			// collect names after name, add them to Y
-			namesToY, err := nl.NodeRangeAfterExclusive(prevNodeReference, name)
+			namesToY, err := nl.nodeRangeAfterExclusive(ctx, prevNodeReference, name)
 			if err != nil {
-				return nil
+				return writeOutcome{}, err
 			}
 			// add namesToY and name to a new Y
 			namesToY = append(namesToY, name)
-			if err := nl.ItemAdd(lookupKey, 0, namesToY...); err != nil {
-				return nil
+			newNodeId, err := nl.itemAdd(ctx, lookupKey, 0, namesToY...)
+			if err != nil {
+				return writeOutcome{}, err
 			}
+			outcome := writeOutcome{writeCase: WriteCaseSplit, added: true, split: true, nodeId: newNodeId}
 			// remove names after name from current X
-			if err := nl.NodeDeleteAfterExclusive(prevNodeReference, name); err != nil {
-				return nil
+			if err := nl.nodeDeleteAfterExclusive(ctx, prevNodeReference, name); err != nil {
+				return outcome, err
 			}
-			return nil
+			nl.notifySplit(prevNodeReference.ElementPointer, newNodeId, name)
+			return outcome, nil
 		}
 
 	}
 
 	// case 2.4
-	if nextNode != nil {
-		nodeSize := nl.NodeSize(nextNode.Reference())
-		if nodeSize < nl.batchSize {
-			if id, err := nl.skipList.DeleteByKey(nextNode.Key); err != nil {
-				return err
-			} else {
-				if err := nl.ItemAdd(lookupKey, id, name); err != nil {
-					return err
-				}
-			}
-			return nil
+	// now prevNode is nil, so name is a new minimum, and nextNode, if any, is the first node
+	if !nl.newMinimumInNewNode {
+		if added, err := nl.addToNextNode(ctx, nextNode, lookupKey, name); added || err != nil {
+			return writeOutcome{writeCase: WriteCaseAddToNextNode, added: added, nodeId: nextNode.Id}, err
 		}
 	}
 
 	// case 2.5
-	// now prevNode is nil
-	return nl.ItemAdd(lookupKey, 0, name)
+	newNodeId, err := nl.itemAdd(ctx, lookupKey, 0, name)
+	if err != nil {
+		return writeOutcome{}, err
+	}
+	return writeOutcome{writeCase: WriteCaseNewNode, added: true, nodeId: newNodeId}, nil
 }
 
 /*
@@ -238,112 +535,269 @@ else
 	// case 3.2
 	update prevNode
 */
-func (nl *ItemList) DeleteName(name string) error {
-	lookupKey := []byte(name)
-	prevNode, nextNode, found, err := nl.skipList.FindGreaterOrEqual(lookupKey)
+func (nl *ItemList) DeleteName(ctx context.Context, name string) (err error) {
+	var removed bool
+	span := nl.startSpan(ctx, "DeleteName", attribute.String("redis3.name", name))
+	defer func() {
+		span.end(nl, err, attribute.Bool("redis3.removed", removed))
+	}()
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+	if name == "" {
+		// the empty name is never stored
+		return nil
+	}
+	realName := name
+	name = nl.storedName(name)
+	if nl.recentWrites != nil {
+		nl.recentWrites.remove(name)
+	}
+	if nl.writeBuffer != nil {
+		delete(nl.writeBuffer.names, name)
+	}
+	removed, err = nl.deleteName(ctx, name)
 	if err != nil {
 		return err
 	}
-
-	// case 1
-	if found && bytes.Compare(nextNode.Key, lookupKey) == 0 {
-		if _, err := nl.skipList.DeleteByKey(nextNode.Key); err != nil {
+	if removed {
+		if err := nl.deleteNameScore(ctx, name); err != nil {
 			return err
 		}
-		if err := nl.NodeDeleteMember(nextNode.Reference(), name); err != nil {
+		if err := nl.adjustChecksum(ctx, name, -1); err != nil {
 			return err
 		}
-		minName := nl.NodeMin(nextNode.Reference())
-		if minName == "" {
-			return nl.NodeDelete(nextNode.Reference())
+		if err := nl.bumpDirectoryVersion(ctx); err != nil {
+			return err
 		}
-		return nl.ItemAdd([]byte(minName), nextNode.Id)
 	}
+	nl.mirror(ctx, "delete", realName, func(mirror *ItemList) error {
+		return mirror.DeleteName(ctx, realName)
+	})
+	return nil
+}
 
-	if !found {
-		prevNode, err = nl.skipList.GetLargestNode()
-		if err != nil {
-			return err
-		}
+// deleteName removes name, reporting whether it was present.
+func (nl *ItemList) deleteName(ctx context.Context, name string) (removed bool, err error) {
+	lookupKey := []byte(name)
+	prevNode, nextNode, found, err := nl.resolveNeighbors(ctx, name)
+	if err != nil {
+		return false, err
 	}
 
-	if nextNode != nil && prevNode == nil {
-		prevNode, err = nl.skipList.LoadElement(nextNode.Prev)
+	// case 1
+	if found && bytes.Compare(nextNode.Key, lookupKey) == 0 {
+		end, err := nl.beginStructuralChange(ctx)
 		if err != nil {
-			return err
+			return false, err
+		}
+		defer end()
+		if _, err := nl.skipList.DeleteByKeyContext(ctx, nextNode.Key); err != nil {
+			return false, err
+		}
+		if err := nl.nodeDeleteMember(ctx, nextNode.Reference(), name); err != nil {
+			return false, err
+		}
+		minName, err := nl.nodeMin(ctx, nextNode.Reference())
+		if err != nil {
+			return true, err
 		}
+		if minName == "" {
+			return true, nl.nodeDelete(ctx, nextNode.Reference())
+		}
+		_, err = nl.itemAdd(ctx, []byte(minName), nextNode.Id)
+		return true, err
 	}
 
 	// case 2
 	if prevNode == nil {
 		// case 2.1
-		return nil
+		return false, nil
 	}
-	if !nl.NodeContainsItem(prevNode.Reference(), name) {
-		return nil
+	if !nl.nodeContainsItem(ctx, prevNode, name) {
+		return false, nil
 	}
 
 	// case 3
-	if err := nl.NodeDeleteMember(prevNode.Reference(), name); err != nil {
-		return err
+	if err := nl.nodeDeleteMember(ctx, prevNode, name); err != nil {
+		return false, err
+	}
+	prevSize, err := nl.nodeSize(ctx, prevNode)
+	if err != nil {
+		return true, err
 	}
-	prevSize := nl.NodeSize(prevNode.Reference())
 	if prevSize == 0 {
-		if _, err := nl.skipList.DeleteByKey(prevNode.Key); err != nil {
-			return err
+		end, err := nl.beginStructuralChange(ctx)
+		if err != nil {
+			return true, err
 		}
-		return nil
+		defer end()
+		if err := nl.deleteNodeElement(ctx, prevNode); err != nil {
+			return true, err
+		}
+		return true, nl.nodeDelete(ctx, prevNode)
+	}
+	nextSize, err := nl.nodeSize(ctx, nextNode.Reference())
+	if err != nil {
+		return true, err
 	}
-	nextSize := nl.NodeSize(nextNode.Reference())
 	if nextSize > 0 && prevSize+nextSize < nl.batchSize {
 		// case 3.1 merge nextNode and prevNode
-		if _, err := nl.skipList.DeleteByKey(nextNode.Key); err != nil {
-			return err
-		}
-		nextNames, err := nl.NodeRangeBeforeExclusive(nextNode.Reference(), "")
+		end, err := nl.beginStructuralChange(ctx)
 		if err != nil {
-			return err
+			return true, err
 		}
-		if err := nl.NodeAddMember(prevNode.Reference(), nextNames...); err != nil {
-			return err
+		defer end()
+		if _, err := nl.skipList.DeleteByKeyContext(ctx, nextNode.Key); err != nil {
+			return true, err
+		}
+		if err := nl.mergeNodeMembers(ctx, prevNode, nextNode.Reference()); err != nil {
+			return true, err
 		}
-		return nl.NodeDelete(nextNode.Reference())
+		nl.notifyMerge(prevNode.ElementPointer, nextNode.Id)
+		return true, nil
 	} else {
 		// case 3.2 update prevNode
 		// no action to take
-		return nil
+		return true, nil
 	}
 
+	return true, nil
+}
+
+// deleteNodeElement removes node from the skiplist by the key of its stored element, found by its id,
+// since the key of a reference held by another element may be stale. The reference key is the fallback.
+func (nl *ItemList) deleteNodeElement(ctx context.Context, node *skiplist.SkipListElementReference) error {
+	var keys [][]byte
+	element, err := nl.skipList.LoadElementContext(ctx, node)
+	if err != nil {
+		glog.Warningf("load node %s%d: %v", nl.prefix, node.ElementPointer, err)
+	}
+	if element != nil {
+		keys = append(keys, element.Key)
+	}
+	if element == nil || !bytes.Equal(element.Key, node.Key) {
+		keys = append(keys, node.Key)
+	}
+	for _, key := range keys {
+		id, err := nl.skipList.DeleteByKeyContext(ctx, key)
+		if err != nil {
+			return err
+		}
+		if id == node.ElementPointer {
+			return nil
+		}
+	}
+	glog.Warningf("node %s%d with key %s is not in the skiplist", nl.prefix, node.ElementPointer, string(node.Key))
 	return nil
 }
 
-func (nl *ItemList) ListNames(startFrom string, visitNamesFn func(name string) bool) error {
+// ListNames visits names in order, starting from startFrom inclusively.
+// An empty startFrom lists from the beginning. Once ctx is done, the listing stops with its error before the next node.
+func (nl *ItemList) ListNames(ctx context.Context, startFrom string, visitNamesFn func(name string) bool) error {
+	return nl.ListNamesWithError(ctx, startFrom, func(name string) (bool, error) {
+		return visitNamesFn(name), nil
+	})
+}
+
+// ListNamesWithError is the same as ListNames, except that the visitor can fail.
+// The listing stops at the first error returned by visitNamesFn, and the error is returned.
+// With WithMaxListingNodes, a *ListingTooLargeError is returned once the cap is reached.
+// A member with the name prefix which is not a stored name, see WithOrderKey, fails it with ErrCorruptMember.
+func (nl *ItemList) ListNamesWithError(ctx context.Context, startFrom string, visitNamesFn func(name string) (bool, error)) (err error) {
+	var nodesScanned *int
+	if span := nl.startSpan(ctx, "ListNames", attribute.String("redis3.start_from", startFrom)); span != nil {
+		visited, scanned := 0, 0
+		fn := visitNamesFn
+		visitNamesFn = func(name string) (bool, error) {
+			visited++
+			return fn(name)
+		}
+		nodesScanned = &scanned
+		defer func() {
+			span.end(nl, err, attribute.Int("redis3.visited_names", visited), attribute.Int("redis3.nodes_scanned", scanned))
+		}()
+	}
+	if err := nl.checkRangeListing(startFrom); err != nil {
+		return err
+	}
+	if nl.namePrefix == "" && nl.orderKey == nil && nl.nameCipher == nil {
+		return nl.listNames(ctx, startFrom, nodesScanned, visitNamesFn)
+	}
+	return nl.listNames(ctx, nl.storedStart(startFrom), nodesScanned, func(name string) (bool, error) {
+		name, ok, err := nl.listedName(name)
+		if !ok || err != nil {
+			// sorted after all names with the prefix, or corrupt
+			return false, err
+		}
+		return visitNamesFn(name)
+	})
+}
+
+// listNames lists the stored names, ignoring WithNamePrefix, counting the nodes scanned into nodesScanned unless it is nil
+func (nl *ItemList) listNames(ctx context.Context, startFrom string, nodesScanned *int, visitNamesFn func(name string) (bool, error)) error {
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+	if !nl.listingVersionCheck {
+		return nl.listNodes(ctx, startFrom, nodesScanned, visitNamesFn)
+	}
+	before, err := nl.readListingVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if err := nl.listNodes(ctx, startFrom, nodesScanned, visitNamesFn); err != nil {
+		return err
+	}
+	return nl.checkListingVersion(ctx, before)
+}
+
+func (nl *ItemList) listNodes(ctx context.Context, startFrom string, scanned *int, visitNamesFn func(name string) (bool, error)) error {
 	lookupKey := []byte(startFrom)
-	prevNode, nextNode, found, err := nl.skipList.FindGreaterOrEqual(lookupKey)
+	prevNode, nextNode, found, err := nl.resolveNeighbors(ctx, startFrom)
 	if err != nil {
 		return err
 	}
 	if found && bytes.Compare(nextNode.Key, lookupKey) == 0 {
+		// startFrom is a node boundary: the previous node only holds smaller names,
+		// and the loop below scans nextNode, so the boundary name is visited once
 		prevNode = nil
 	}
-	if !found {
-		prevNode, err = nl.skipList.GetLargestNode()
-		if err != nil {
+
+	if prevNode != nil {
+		if shouldContinue, err := nl.nodeScanInclusiveAfter(ctx, prevNode, startFrom, visitNamesFn); !shouldContinue || err != nil {
 			return err
 		}
 	}
 
+	nodesScanned := 0
 	if prevNode != nil {
-		if !nl.NodeScanInclusiveAfter(prevNode.Reference(), startFrom, visitNamesFn) {
-			return nil
-		}
+		nodesScanned++
 	}
-
+	if nl.prefetchDepth > 0 {
+		return nl.listNodesPrefetched(ctx, nextNode, startFrom, nodesScanned, scanned, visitNamesFn)
+	}
+	if scanned != nil {
+		defer func() {
+			*scanned = nodesScanned
+		}()
+	}
+	guard := nl.newChainGuard(ctx)
 	for nextNode != nil {
-		if !nl.NodeScanInclusiveAfter(nextNode.Reference(), startFrom, visitNamesFn) {
-			return nil
+		if nl.maxListingNodes > 0 && nodesScanned >= nl.maxListingNodes {
+			return &ListingTooLargeError{NodesScanned: nodesScanned, ResumeFrom: string(nextNode.Key)}
+		}
+		if err := guard.step(); err != nil {
+			return err
 		}
-		nextNode, err = nl.skipList.LoadElement(nextNode.Next[0])
+		nodesScanned++
+		if shouldContinue, err := nl.nodeScanInclusiveAfter(ctx, nextNode.Reference(), startFrom, visitNamesFn); !shouldContinue || err != nil {
+			return err
+		}
+		nextNode, err = nl.skipList.LoadElementContext(ctx, nextNode.Next[0])
 		if err != nil {
 			return err
 		}
@@ -352,34 +806,75 @@ func (nl *ItemList) ListNames(startFrom string, visitNamesFn func(name string) b
 	return nil
 }
 
-func (nl *ItemList) RemoteAllListElement() error {
+// ListNodeBoundaries returns the leading key of each node in order, a coarse index of the names
+// read from the skiplist alone, without listing the member sets.
+// The keys are stored names, including any WithNamePrefix.
+func (nl *ItemList) ListNodeBoundaries(ctx context.Context) ([]string, error) {
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return nil, err
+	}
+	var boundaries []string
+	err := nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		boundaries = append(boundaries, string(node.Key))
+		return true, nil
+	})
+	if err != nil {
+		return boundaries, err
+	}
+	return boundaries, nil
+}
+
+// RemoteAllListElement deletes all nodes of the list, with their names. Once ctx is done, it stops before the next node.
+func (nl *ItemList) RemoteAllListElement(ctx context.Context) error {
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+
+	if nl.recentWrites != nil {
+		nl.recentWrites.clear()
+	}
 
 	t := nl.skipList
 
-	nodeRef := t.StartLevels[0]
-	for nodeRef != nil {
-		node, err := t.LoadElement(nodeRef)
-		if err != nil {
-			return err
-		}
-		if node == nil {
-			return nil
-		}
+	err := nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
 		if err := t.DeleteElement(node); err != nil {
-			return err
+			return false, err
 		}
-		if err := nl.NodeDelete(node.Reference()); err != nil {
-			return err
+		if err := nl.nodeDelete(ctx, node.Reference()); err != nil {
+			return false, err
 		}
-		nodeRef = node.Next[0]
+		return true, nil
+	})
+	if err != nil {
+		return err
 	}
-	return nil
+	if err := nl.deleteNameScores(ctx); err != nil {
+		return err
+	}
+	if err := nl.deleteListingVersion(ctx); err != nil {
+		return err
+	}
+	if err := nl.deletePreSplit(ctx); err != nil {
+		return err
+	}
+	if err := nl.bumpDirectoryVersionExpiring(ctx, truncatedVersionTTL); err != nil {
+		return err
+	}
+	return nl.deleteChecksum(ctx)
 
 }
 
 func (nl *ItemList) NodeContainsItem(node *skiplist.SkipListElementReference, item string) bool {
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
-	_, err := nl.client.ZScore(context.Background(), key, item).Result()
+	return nl.nodeContainsItem(context.Background(), node, item)
+}
+
+// nodeContainsItem is NodeContainsItem, its commands using ctx
+func (nl *ItemList) nodeContainsItem(ctx context.Context, node *skiplist.SkipListElementReference, item string) bool {
+	key := nl.nodeKey(node.ElementPointer)
+	_, err := nl.client.ZScore(ctx, nl.memberKey(key, item), item).Result()
 	if err == redis.Nil {
 		return false
 	}
@@ -390,15 +885,46 @@ func (nl *ItemList) NodeContainsItem(node *skiplist.SkipListElementReference, it
 }
 
 func (nl *ItemList) NodeSize(node *skiplist.SkipListElementReference) int {
+	size, _ := nl.nodeSize(context.Background(), node)
+	return size
+}
+
+// nodeSize is NodeSize, also returning the error of the read
+func (nl *ItemList) nodeSize(ctx context.Context, node *skiplist.SkipListElementReference) (int, error) {
 	if node == nil {
-		return 0
+		return 0, nil
+	}
+	if nl.maintainNodeCount {
+		return nl.maintainedNodeSize(ctx, node), nil
+	}
+	key := nl.nodeKey(node.ElementPointer)
+	size, err := nl.zLexCountAll(ctx, nl.client, key).Result()
+	if err != nil {
+		return 0, wrapNodeKeyError(key, err)
 	}
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
-	return int(nl.client.ZLexCount(context.Background(), key, "-", "+").Val())
+	return int(size), nil
 }
 
+// NodeAddMember adds names with score 0. All members of a node must keep the same score:
+// ZRANGEBYLEX and ZLEXCOUNT are only defined for equal scores, so the score can not carry
+// per name metadata, and splits and merges do not preserve it.
 func (nl *ItemList) NodeAddMember(node *skiplist.SkipListElementReference, names ...string) error {
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	return nl.nodeAddMember(context.Background(), node, names...)
+}
+
+// nodeAddMember is NodeAddMember, its commands using ctx
+func (nl *ItemList) nodeAddMember(ctx context.Context, node *skiplist.SkipListElementReference, names ...string) error {
+	key := nl.nodeKey(node.ElementPointer)
+	if nl.nodeShardLength > 0 {
+		added, err := nl.shardedAddMembers(ctx, key, names)
+		if err != nil {
+			return wrapNodeKeyError(key, err)
+		}
+		return nl.adjustNodeCount(ctx, node, added)
+	}
 	var members []redis.Z
 	for _, name := range names {
 		members = append(members, redis.Z{
@@ -406,111 +932,346 @@ func (nl *ItemList) NodeAddMember(node *skiplist.SkipListElementReference, names
 			Member: name,
 		})
 	}
-	return nl.client.ZAddNX(context.Background(), key, members...).Err()
+	if len(members) <= nl.addChunkSize() {
+		added, err := nl.client.ZAddNX(ctx, key, members...).Result()
+		if err != nil {
+			return wrapNodeKeyError(key, err)
+		}
+		return nl.adjustNodeCount(ctx, node, added)
+	}
+	pipe := nl.client.Pipeline()
+	cmds := nl.zAddNXChunked(ctx, pipe, key, members)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return wrapNodeKeyError(key, err)
+	}
+	var added int64
+	for _, cmd := range cmds {
+		added += cmd.Val()
+	}
+	return nl.adjustNodeCount(ctx, node, added)
+}
+
+// zAddNXChunked queues ZADD NX commands of at most addChunkSize members each, keeping every command bounded.
+func (nl *ItemList) zAddNXChunked(ctx context.Context, pipe redis.Pipeliner, key string, members []redis.Z) (cmds []*redis.IntCmd) {
+	chunkSize := nl.addChunkSize()
+	for start := 0; start < len(members); start += chunkSize {
+		cmds = append(cmds, pipe.ZAddNX(ctx, key, members[start:min(start+chunkSize, len(members))]...))
+	}
+	return cmds
+}
+
+func (nl *ItemList) addChunkSize() int {
+	if nl.maxAddChunk > 0 {
+		return nl.maxAddChunk
+	}
+	return defaultAddChunkSize
 }
+
 func (nl *ItemList) NodeDeleteMember(node *skiplist.SkipListElementReference, name string) error {
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
-	return nl.client.ZRem(context.Background(), key, name).Err()
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	return nl.nodeDeleteMember(context.Background(), node, name)
+}
+
+// nodeDeleteMember is NodeDeleteMember, its commands using ctx
+func (nl *ItemList) nodeDeleteMember(ctx context.Context, node *skiplist.SkipListElementReference, name string) error {
+	key := nl.nodeKey(node.ElementPointer)
+	removed, err := nl.client.ZRem(ctx, nl.memberKey(key, name), name).Result()
+	if err != nil {
+		return wrapNodeKeyError(key, err)
+	}
+	return nl.adjustNodeCount(ctx, node, -removed)
 }
 
 func (nl *ItemList) NodeDelete(node *skiplist.SkipListElementReference) error {
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
-	return nl.client.Del(context.Background(), key).Err()
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	return nl.nodeDelete(context.Background(), node)
+}
+
+// nodeDelete is NodeDelete, its commands using ctx
+func (nl *ItemList) nodeDelete(ctx context.Context, node *skiplist.SkipListElementReference) error {
+	key := nl.nodeKey(node.ElementPointer)
+	if nl.nodeShardLength > 0 {
+		if err := nl.shardedDelete(ctx, key); err != nil {
+			return wrapNodeKeyError(key, err)
+		}
+		return nl.deleteNodeCount(ctx, node)
+	}
+	if err := nl.client.Del(ctx, key).Err(); err != nil {
+		return wrapNodeKeyError(key, err)
+	}
+	return nl.deleteNodeCount(ctx, node)
 }
 
 func (nl *ItemList) NodeInnerPosition(node *skiplist.SkipListElementReference, name string) int {
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
-	return int(nl.client.ZLexCount(context.Background(), key, "-", "("+name).Val())
+	position, _ := nl.nodeInnerPosition(context.Background(), node, name)
+	return position
+}
+
+// nodeInnerPosition is NodeInnerPosition, also returning the error of the read
+func (nl *ItemList) nodeInnerPosition(ctx context.Context, node *skiplist.SkipListElementReference, name string) (int, error) {
+	key := nl.nodeKey(node.ElementPointer)
+	position, err := nl.zLexCount(ctx, key, "-", "("+name).Result()
+	if err != nil {
+		return 0, wrapNodeKeyError(key, err)
+	}
+	return int(position), nil
 }
 
 func (nl *ItemList) NodeMin(node *skiplist.SkipListElementReference) string {
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
-	slice := nl.client.ZRangeByLex(context.Background(), key, &redis.ZRangeBy{
-		Min:    "-",
-		Max:    "+",
-		Offset: 0,
-		Count:  1,
-	}).Val()
+	minName, _ := nl.nodeMin(context.Background(), node)
+	return minName
+}
+
+// nodeMin is NodeMin, also returning the error of the read
+func (nl *ItemList) nodeMin(ctx context.Context, node *skiplist.SkipListElementReference) (string, error) {
+	key := nl.nodeKey(node.ElementPointer)
+	slice, err := nl.zLexMin(ctx, nl.client, key).Result()
+	if err != nil {
+		return "", wrapNodeKeyError(key, err)
+	}
 	if len(slice) > 0 {
-		s := slice[0]
-		return s
+		return slice[0], nil
 	}
-	return ""
+	return "", nil
 }
 
 func (nl *ItemList) NodeScanInclusiveAfter(node *skiplist.SkipListElementReference, startFrom string, visitNamesFn func(name string) bool) bool {
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
+	shouldContinue, _ := nl.nodeScanInclusiveAfter(context.Background(), node, startFrom, func(name string) (bool, error) {
+		return visitNamesFn(name), nil
+	})
+	return shouldContinue
+}
+
+// nodeScanInclusiveAfter reads the node in chunks of nodeScanChunk names, so a node over batchSize,
+// e.g. left by a failed split, is streamed within bounded memory
+func (nl *ItemList) nodeScanInclusiveAfter(ctx context.Context, node *skiplist.SkipListElementReference, startFrom string, visitNamesFn func(name string) (bool, error)) (bool, error) {
+	key := nl.nodeKey(node.ElementPointer)
+	min := "-"
+	if startFrom != "" {
+		min = "[" + startFrom
+	}
+	chunk := nl.nodeScanChunk()
+	if nl.nodeShardLength == 0 && nl.lexFallback(ctx) {
+		return nl.nodeScanFallback(ctx, key, min, chunk, visitNamesFn)
+	}
+	for {
+		names, err := nl.zRangeByLex(ctx, key, &redis.ZRangeBy{Min: min, Max: "+", Count: chunk}).Result()
+		if err != nil {
+			return false, wrapNodeKeyError(key, err)
+		}
+		for _, n := range names {
+			if shouldContinue, err := visitNamesFn(n); !shouldContinue || err != nil {
+				return false, err
+			}
+		}
+		if int64(len(names)) < chunk {
+			return true, nil
+		}
+		nl.warnOversizedNode(key)
+		min = "(" + names[len(names)-1]
+	}
+}
+
+// nodeScanChunk is the names read at once by node scans, one more than batchSize so a full node takes one read
+func (nl *ItemList) nodeScanChunk() int64 {
+	return int64(nl.batchSize) + 1
+}
+
+// warnedOversizedNodes are the node keys found over batchSize by scans, warned about once per process
+var warnedOversizedNodes sync.Map // string -> struct{}
+
+func (nl *ItemList) warnOversizedNode(key string) {
+	if _, warned := warnedOversizedNodes.LoadOrStore(key, struct{}{}); !warned {
+		glog.Warningf("node %s holds more than batch size %d names, read in chunks until SplitOversizedNodes splits it", key, nl.batchSize)
+	}
+}
+
+func (nl *ItemList) nodeRangeInclusiveAfter(ctx context.Context, node *skiplist.SkipListElementReference, startFrom string) ([]string, error) {
+	key := nl.nodeKey(node.ElementPointer)
 	if startFrom == "" {
 		startFrom = "-"
 	} else {
 		startFrom = "[" + startFrom
 	}
-	names := nl.client.ZRangeByLex(context.Background(), key, &redis.ZRangeBy{
+	names, err := nl.zRangeByLex(ctx, key, &redis.ZRangeBy{
 		Min: startFrom,
 		Max: "+",
-	}).Val()
-	for _, n := range names {
-		if !visitNamesFn(n) {
-			return false
-		}
+	}).Result()
+	if err != nil {
+		return nil, wrapNodeKeyError(key, err)
 	}
-	return true
+	return names, nil
 }
 
 func (nl *ItemList) NodeRangeBeforeExclusive(node *skiplist.SkipListElementReference, stopAt string) ([]string, error) {
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
+	return nl.nodeRangeBeforeExclusive(context.Background(), node, stopAt)
+}
+
+// nodeRangeBeforeExclusive is NodeRangeBeforeExclusive, its commands using ctx
+func (nl *ItemList) nodeRangeBeforeExclusive(ctx context.Context, node *skiplist.SkipListElementReference, stopAt string) ([]string, error) {
+	key := nl.nodeKey(node.ElementPointer)
 	if stopAt == "" {
 		stopAt = "+"
 	} else {
 		stopAt = "(" + stopAt
 	}
-	return nl.client.ZRangeByLex(context.Background(), key, &redis.ZRangeBy{
+	names, err := nl.zRangeByLex(ctx, key, &redis.ZRangeBy{
 		Min: "-",
 		Max: stopAt,
 	}).Result()
+	return names, wrapNodeKeyError(key, err)
 }
 func (nl *ItemList) NodeRangeAfterExclusive(node *skiplist.SkipListElementReference, startFrom string) ([]string, error) {
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
+	return nl.nodeRangeAfterExclusive(context.Background(), node, startFrom)
+}
+
+// nodeRangeAfterExclusive is NodeRangeAfterExclusive, its commands using ctx
+func (nl *ItemList) nodeRangeAfterExclusive(ctx context.Context, node *skiplist.SkipListElementReference, startFrom string) ([]string, error) {
+	key := nl.nodeKey(node.ElementPointer)
 	if startFrom == "" {
 		startFrom = "-"
 	} else {
 		startFrom = "(" + startFrom
 	}
-	return nl.client.ZRangeByLex(context.Background(), key, &redis.ZRangeBy{
+	names, err := nl.zRangeByLex(ctx, key, &redis.ZRangeBy{
 		Min: startFrom,
 		Max: "+",
 	}).Result()
+	return names, wrapNodeKeyError(key, err)
 }
 
 func (nl *ItemList) NodeDeleteBeforeExclusive(node *skiplist.SkipListElementReference, stopAt string) error {
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	return nl.nodeDeleteBeforeExclusive(context.Background(), node, stopAt)
+}
+
+// nodeDeleteBeforeExclusive is NodeDeleteBeforeExclusive, its commands using ctx
+func (nl *ItemList) nodeDeleteBeforeExclusive(ctx context.Context, node *skiplist.SkipListElementReference, stopAt string) error {
+	key := nl.nodeKey(node.ElementPointer)
 	if stopAt == "" {
 		stopAt = "+"
 	} else {
 		stopAt = "(" + stopAt
 	}
-	return nl.client.ZRemRangeByLex(context.Background(), key, "-", stopAt).Err()
+	removed, err := nl.zRemRangeByLex(ctx, key, "-", stopAt).Result()
+	if err != nil {
+		return wrapNodeKeyError(key, err)
+	}
+	return nl.adjustNodeCount(ctx, node, -removed)
 }
 func (nl *ItemList) NodeDeleteAfterExclusive(node *skiplist.SkipListElementReference, startFrom string) error {
-	key := fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer)
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	return nl.nodeDeleteAfterExclusive(context.Background(), node, startFrom)
+}
+
+// nodeDeleteAfterExclusive is NodeDeleteAfterExclusive, its commands using ctx
+func (nl *ItemList) nodeDeleteAfterExclusive(ctx context.Context, node *skiplist.SkipListElementReference, startFrom string) error {
+	key := nl.nodeKey(node.ElementPointer)
 	if startFrom == "" {
 		startFrom = "-"
 	} else {
 		startFrom = "(" + startFrom
 	}
-	return nl.client.ZRemRangeByLex(context.Background(), key, startFrom, "+").Err()
+	removed, err := nl.zRemRangeByLex(ctx, key, startFrom, "+").Result()
+	if err != nil {
+		return wrapNodeKeyError(key, err)
+	}
+	return nl.adjustNodeCount(ctx, node, -removed)
 }
 
-func (nl *ItemList) ItemAdd(lookupKey []byte, idIfKnown int64, names ...string) error {
-	if id, err := nl.skipList.InsertByKey(lookupKey, idIfKnown, nil); err != nil {
-		return err
+// addToNextNode adds name, which is after all names of the previous node, to nextNode if it has capacity
+func (nl *ItemList) addToNextNode(ctx context.Context, nextNode *skiplist.SkipListElement, lookupKey []byte, name string) (added bool, err error) {
+	if nextNode == nil {
+		return false, nil
+	}
+	nodeSize, err := nl.nodeSize(ctx, nextNode.Reference())
+	if err != nil || nodeSize >= nl.batchSize {
+		return false, err
+	}
+	// re-keying takes the node out of the skiplist for a moment
+	end, err := nl.beginStructuralChange(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer end()
+	if id, err := nl.skipList.DeleteByKeyContext(ctx, nextNode.Key); err != nil {
+		return false, err
 	} else {
-		if len(names) > 0 {
-			return nl.NodeAddMember(&skiplist.SkipListElementReference{
-				ElementPointer: id,
-				Key:            lookupKey,
-			}, names...)
+		if _, err := nl.itemAdd(ctx, lookupKey, id, name); err != nil {
+			return false, err
 		}
 	}
+	return true, nil
+}
+
+func (nl *ItemList) notifySplit(oldNodeId, newNodeId int64, pivot string) {
+	if nl.onSplit != nil {
+		nl.onSplit(oldNodeId, newNodeId, pivot)
+	}
+}
+
+func (nl *ItemList) notifyMerge(keptNodeId, removedNodeId int64) {
+	if nl.onMerge != nil {
+		nl.onMerge(keptNodeId, removedNodeId)
+	}
+}
+
+func (nl *ItemList) ItemAdd(lookupKey []byte, idIfKnown int64, names ...string) error {
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	_, err := nl.itemAdd(context.Background(), lookupKey, idIfKnown, names...)
+	return err
+}
+
+func (nl *ItemList) itemAdd(ctx context.Context, lookupKey []byte, idIfKnown int64, names ...string) (id int64, err error) {
+	if idIfKnown != 0 && nl.nodeReuseCheck {
+		if err := nl.checkNodeReuse(ctx, lookupKey, idIfKnown, names); err != nil {
+			return 0, err
+		}
+	}
+	if id, err = nl.skipList.InsertByKeyContext(ctx, lookupKey, idIfKnown, nil); err != nil {
+		return 0, err
+	}
+	if len(names) > 0 {
+		return id, nl.nodeAddMember(ctx, &skiplist.SkipListElementReference{
+			ElementPointer: id,
+			Key:            lookupKey,
+		}, names...)
+	}
+	return id, nil
+}
+
+// checkNodeReuse rejects reusing the id of a node still in the skiplist, or whose names do not start at lookupKey,
+// so a stale or wrong id can not attach names to the storage of another node
+func (nl *ItemList) checkNodeReuse(ctx context.Context, lookupKey []byte, id int64, names []string) error {
+	element, err := nl.loadElement(ctx, id)
+	if err != nil {
+		return err
+	}
+	if element != nil {
+		return fmt.Errorf("reuse node %s%d for key %q: %w: still linked with key %q", nl.prefix, id, string(lookupKey), ErrNodeIdInUse, string(element.Key))
+	}
+	smallest, err := nl.nodeMin(ctx, &skiplist.SkipListElementReference{ElementPointer: id})
+	if err != nil {
+		return err
+	}
+	if smallest == "" {
+		return nil
+	}
+	for _, name := range names {
+		smallest = min(smallest, name)
+	}
+	if smallest != string(lookupKey) {
+		return fmt.Errorf("reuse node %s%d for key %q: %w: its smallest name is %q", nl.prefix, id, string(lookupKey), ErrNodeIdInUse, smallest)
+	}
 	return nil
 }
@@ -0,0 +1,37 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const maxReportedLeftoverKeys = 10
+
+// AssertEmpty scans the prefix for keys of the list layout, other than the list itself, the canary and the directory version,
+// and returns ErrKeysLeft naming them, e.g. to verify RemoteAllListElement left no member set behind.
+// Keys of a list whose prefix extends this one with digits look like node keys, and are reported too.
+func (nl *ItemList) AssertEmpty(ctx context.Context) error {
+	var leftover []string
+	err := nl.scanKeys(ctx, escapeScanPattern(nl.prefix)+"*", func(key string) error {
+		suffix := strings.TrimPrefix(key, nl.prefix)
+		if suffix == "" || suffix == namespaceCanarySuffix || suffix == directoryVersionSuffix || !isItemListKeySuffix(suffix) {
+			return nil
+		}
+		leftover = append(leftover, key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(leftover) == 0 {
+		return nil
+	}
+	sort.Strings(leftover)
+	reported := leftover
+	if len(reported) > maxReportedLeftoverKeys {
+		reported = reported[:maxReportedLeftoverKeys]
+	}
+	return fmt.Errorf("%w: %d keys under %q, %s", ErrKeysLeft, len(leftover), nl.prefix, strings.Join(reported, " "))
+}
@@ -0,0 +1,41 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteAllListElementLeavesNoKeys(t *testing.T) {
+	for _, opts := range [][]ItemListOption{
+		nil,
+		{WithMaintainedNodeCount(), WithChecksum(), WithNameScores()},
+		{WithNodeKeyEncoding(NodeKeyBase62), WithNamespaceCheck(true)},
+	} {
+		nl, server := newTestItemList(t, 3, opts...)
+		for i := 0; i < 30; i++ {
+			name := fmt.Sprintf("name%02d", (i*7)%30)
+			if nl.nameScores {
+				assert.NoError(t, nl.UpsertName(context.Background(), name, float64(i)))
+			} else {
+				assert.NoError(t, nl.WriteName(context.Background(), name))
+			}
+		}
+		for i := 0; i < 30; i += 4 {
+			assert.NoError(t, nl.DeleteName(context.Background(), fmt.Sprintf("name%02d", i)))
+		}
+		assert.ErrorIs(t, nl.AssertEmpty(context.Background()), ErrKeysLeft)
+
+		assert.NoError(t, nl.RemoteAllListElement(context.Background()))
+		assert.NoError(t, nl.AssertEmpty(context.Background()))
+
+		// an orphaned member set is reported
+		_, err := server.ZAdd(nl.nodeKey(42), 0, "orphan")
+		assert.NoError(t, err)
+		err = nl.AssertEmpty(context.Background())
+		assert.ErrorIs(t, err, ErrKeysLeft)
+		assert.Contains(t, err.Error(), nl.nodeKey(42))
+	}
+}
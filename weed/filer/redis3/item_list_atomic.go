@@ -0,0 +1,206 @@
+package redis3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+A group of names is written as one unit only when all of them fall into one node with room for them,
+since every other write touches several keys, and the skiplist store, which no script can reach.
+There, writeNodeNamesScript checks and adds the names in one script: other clients see all of them or none,
+and with WriteNamesIfAbsent none is added if one is stored.
+Otherwise the names are written one after the other, and those added are deleted again if a write fails:
+other clients may see some of the names before the group is complete, or a rollback in progress,
+a failure during the rollback leaves the names it did not delete, and the precondition of WriteNamesIfAbsent
+is checked before writing, so it only holds against writers holding the directory lock.
+*/
+
+// writeNodeNamesScript adds the names ARGV[3..] to the member set KEYS[1], unless they would take it over
+// ARGV[2] members, returning "full", or unless ARGV[1] is "1" and one is a member, returning "exists",
+// and returns "ok" followed by the names which were added
+var writeNodeNamesScript = redis.NewScript(`
+local size = redis.call('ZCARD', KEYS[1])
+local added = {}
+for i = 3, #ARGV do
+	if not redis.call('ZSCORE', KEYS[1], ARGV[i]) then
+		added[#added + 1] = ARGV[i]
+	end
+end
+if ARGV[1] == '1' and #added < #ARGV - 2 then
+	return {'exists'}
+end
+if size == 0 or size + #added > tonumber(ARGV[2]) then
+	return {'full'}
+end
+for _, name in ipairs(added) do
+	redis.call('ZADD', KEYS[1], 0, name)
+end
+table.insert(added, 1, 'ok')
+return added
+`)
+
+// WriteNamesAtomic adds all names, as WriteName does, as one unit when they fall into one node with room for them,
+// and otherwise one after the other, deleting those it added again if one fails, see the comment above.
+// It is not available with WithWriteBuffer, whose names are only written by Flush.
+func (nl *ItemList) WriteNamesAtomic(ctx context.Context, names []string) error {
+	return nl.writeNamesAtomic(ctx, names, false)
+}
+
+// WriteNamesIfAbsent is WriteNamesAtomic failing with ErrNameExists, and adding none of names, if one is stored.
+// Against writers not holding the directory lock, the precondition only holds when the names fall into one node.
+func (nl *ItemList) WriteNamesIfAbsent(ctx context.Context, names []string) error {
+	return nl.writeNamesAtomic(ctx, names, true)
+}
+
+func (nl *ItemList) writeNamesAtomic(ctx context.Context, names []string, ifAbsent bool) error {
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	if nl.writeBuffer != nil {
+		return fmt.Errorf("write names of %s: %w: names are buffered, see WithWriteBuffer", nl.prefix, ErrInvalidOptions)
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+	stored := make(map[string]string, len(names)) // stored name -> name
+	for _, name := range names {
+		if err := nl.validateName(name); err != nil {
+			return err
+		}
+		if err := nl.checkOrderKey(name); err != nil {
+			return err
+		}
+		stored[nl.storedName(name)] = name
+	}
+	if len(stored) == 0 {
+		return nil
+	}
+	sorted := make([]string, 0, len(stored))
+	for name := range stored {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	if written, err := nl.writeNodeNames(ctx, sorted, stored, ifAbsent); written || err != nil {
+		return err
+	}
+
+	if ifAbsent {
+		realNames := make([]string, 0, len(sorted))
+		for _, name := range sorted {
+			realNames = append(realNames, stored[name])
+		}
+		exists, err := nl.existsMany(ctx, realNames)
+		if err != nil {
+			return err
+		}
+		for _, name := range realNames {
+			if exists[name] {
+				return fmt.Errorf("write names of %s: %w: %q", nl.prefix, ErrNameExists, name)
+			}
+		}
+	}
+	var added []string
+	for _, name := range sorted {
+		outcome, err := nl.addName(ctx, stored[name])
+		if err == nil {
+			if outcome.added {
+				added = append(added, stored[name])
+			}
+			continue
+		}
+		var rollbackErrs []error
+		for _, name := range added {
+			if err := nl.DeleteName(ctx, name); err != nil {
+				rollbackErrs = append(rollbackErrs, fmt.Errorf("roll back %q: %w", name, err))
+			}
+		}
+		return errors.Join(fmt.Errorf("write names of %s: %q: %w", nl.prefix, stored[name], err), errors.Join(rollbackErrs...))
+	}
+	return nil
+}
+
+// writeNodeNames adds the sorted stored names with writeNodeNamesScript when they fall into one node,
+// reporting whether it did. It does not when they span nodes, or the node has no room for them.
+func (nl *ItemList) writeNodeNames(ctx context.Context, sorted []string, stored map[string]string, ifAbsent bool) (bool, error) {
+	if nl.nodeShardLength > 0 || nl.caseInsensitive {
+		return false, nil
+	}
+	node, bound, err := nl.nodeAndBoundOf(ctx, sorted[0])
+	if err != nil || node == nil {
+		return false, err
+	}
+	if bound != nil && sorted[len(sorted)-1] >= string(bound.Key) {
+		return false, nil
+	}
+
+	key := nl.nodeKey(node.ElementPointer)
+	argv := make([]interface{}, 0, len(sorted)+2)
+	flag := "0"
+	if ifAbsent {
+		flag = "1"
+	}
+	argv = append(argv, flag, nl.batchSize)
+	for _, name := range sorted {
+		argv = append(argv, name)
+	}
+	result, err := writeNodeNamesScript.Run(ctx, nl.client, []string{key}, argv...).StringSlice()
+	if err != nil {
+		return false, wrapNodeKeyError(key, err)
+	}
+	switch result[0] {
+	case "full":
+		return false, nil
+	case "exists":
+		return false, fmt.Errorf("write names of %s: %w", nl.prefix, ErrNameExists)
+	}
+
+	added := result[1:]
+	if err := nl.adjustNodeCount(ctx, node, int64(len(added))); err != nil {
+		return true, err
+	}
+	for _, name := range added {
+		nl.observeInsert(name)
+		if err := nl.adjustChecksum(ctx, name, 1); err != nil {
+			return true, err
+		}
+	}
+	if len(added) > 0 {
+		if err := nl.bumpDirectoryVersion(ctx); err != nil {
+			return true, err
+		}
+	}
+	now := nl.clock.Now()
+	for _, name := range added {
+		if nl.recentWrites != nil {
+			nl.recentWrites.add(name, now)
+		}
+		realName := stored[name]
+		nl.mirror(ctx, "write", realName, func(mirror *ItemList) error {
+			return mirror.WriteName(ctx, realName)
+		})
+	}
+	return true, nil
+}
+
+// nodeAndBoundOf is the node name is written to, unless it sorts before the first node, and the reference
+// to the next node, whose key bounds the names of the node
+func (nl *ItemList) nodeAndBoundOf(ctx context.Context, name string) (node, bound *skiplist.SkipListElementReference, err error) {
+	prev, next, found, err := nl.resolveNeighbors(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if found && string(next.Key) == name {
+		return next.Reference(), next.Next[0], nil
+	}
+	if found {
+		bound = next.Reference()
+	}
+	return prev, bound, nil
+}
@@ -0,0 +1,46 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteNamesAtomic(t *testing.T) {
+	nl, server := newTestItemList(t, 4, WithChecksum())
+	for _, name := range []string{"a", "c"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	// within one node, by one script
+	adds := &countCommands{name: "zadd"}
+	nl.client.AddHook(adds)
+	assert.NoError(t, nl.WriteNamesAtomic(context.Background(), []string{"d", "b", "b"}))
+	assert.Zero(t, adds.count.Load())
+	assert.Equal(t, []string{"a", "b", "c", "d"}, listAllNames(t, nl, ""))
+	assert.ErrorIs(t, nl.WriteNamesIfAbsent(context.Background(), []string{"b1", "c"}), ErrNameExists)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, listAllNames(t, nl, ""))
+
+	// the node is full, so the names are written one after the other
+	assert.NoError(t, nl.WriteNamesIfAbsent(context.Background(), []string{"b1", "e", "f", "g"}))
+	assert.Equal(t, []string{"a", "b", "b1", "c", "d", "e", "f", "g"}, listAllNames(t, nl, ""))
+	assert.Greater(t, countNodes(t, nl), 1)
+	assert.ErrorIs(t, nl.WriteNamesIfAbsent(context.Background(), []string{"a1", "g"}), ErrNameExists)
+	assert.Equal(t, []string{"a", "b", "b1", "c", "d", "e", "f", "g"}, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+	ok, err := nl.VerifyChecksum(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// a failed write rolls back the names added before it
+	first := nl.skipList.StartLevels[0].ElementPointer
+	last, err := nl.skipList.GetLargestNode()
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, last.Id)
+	server.Del(nl.nodeKey(last.Id))
+	assert.NoError(t, server.Set(nl.nodeKey(last.Id), "not a set"))
+	err = nl.WriteNamesAtomic(context.Background(), []string{"a1", "z"})
+	assert.ErrorIs(t, err, ErrWrongType)
+	assert.ErrorIs(t, nl.client.ZScore(context.Background(), nl.nodeKey(first), "a1").Err(), redis.Nil)
+}
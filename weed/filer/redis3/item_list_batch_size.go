@@ -0,0 +1,50 @@
+package redis3
+
+import (
+	"fmt"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+const (
+	// with 1 name per node, every name costs a skiplist element
+	defaultMinSaneBatchSize = 2
+	// larger nodes are read whole by splits and merges
+	defaultMaxSaneBatchSize = maxNameBatchSizeLimit
+)
+
+// checkBatchSize rejects a non-positive batch size, and warns about one outside the sane bounds.
+func (nl *ItemList) checkBatchSize(batchSize int) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("%w: %d for %s", ErrInvalidBatchSize, batchSize, nl.prefix)
+	}
+	if batchSize < nl.minSaneBatchSize || batchSize > nl.maxSaneBatchSize {
+		glog.Warningf("redis3 %s: batch size %d is outside %d to %d", nl.prefix, batchSize, nl.minSaneBatchSize, nl.maxSaneBatchSize)
+	}
+	return nil
+}
+
+const (
+	// a node is read whole by splits and merges, and by listings as one reply
+	suggestNodeBytes = 256 << 10
+	// the reply bytes of a member besides its name
+	suggestMemberOverhead = 16
+	// a full listing should take at most this many node reads
+	suggestListingNodes = 100
+	// smaller nodes cost a skiplist element per few names, for little gain in small directories
+	suggestMinBatchSize = 100
+)
+
+// SuggestBatchSize recommends a batch size for a list with the names counted by Verify and names of avgNameLen bytes,
+// e.g. for RebalanceToBatchSize. Nodes grow so a full listing reads about 100 nodes, from at least 100 names,
+// but stay within a 256 KiB reply, which wins for long names. It makes no Redis call.
+func SuggestBatchSize(report *VerifyReport, avgNameLen int) int {
+	avgNameLen = max(avgNameLen, 1)
+	var names int64
+	if report != nil {
+		names = report.Names
+	}
+	size := max((names+suggestListingNodes-1)/suggestListingNodes, suggestMinBatchSize)
+	size = min(size, int64(suggestNodeBytes/(avgNameLen+suggestMemberOverhead)), maxNameBatchSizeLimit)
+	return int(max(size, defaultMinSaneBatchSize))
+}
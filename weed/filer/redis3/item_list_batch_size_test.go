@@ -0,0 +1,61 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchSizeBounds(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	store := newSkipListElementStore("/test/dir", client)
+
+	for _, batchSize := range []int{0, -1} {
+		_, err := newItemList(client, "/test/dir", store, batchSize)
+		assert.ErrorIs(t, err, ErrInvalidBatchSize, "batch size %d", batchSize)
+		_, err = LoadItemList(nil, "/test/dir", client, store, batchSize)
+		assert.ErrorIs(t, err, ErrInvalidBatchSize, "batch size %d", batchSize)
+	}
+	// outside the sane bounds only warns
+	for _, batchSize := range []int{1, 2, maxNameBatchSizeLimit, maxNameBatchSizeLimit + 1} {
+		nl, err := newItemList(client, "/test/dir", store, batchSize)
+		assert.NoError(t, err, "batch size %d", batchSize)
+		assert.Equal(t, batchSize, nl.batchSize)
+	}
+	nl, err := newItemList(client, "/test/dir", store, 5, WithSaneBatchSize(10, 20))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, nl.RebalanceToBatchSize(context.Background(), 0), ErrInvalidBatchSize)
+	assert.NoError(t, nl.RebalanceToBatchSize(context.Background(), 10))
+}
+
+func TestSuggestBatchSize(t *testing.T) {
+	for _, tc := range []struct {
+		names      int64
+		avgNameLen int
+		expected   int
+	}{
+		// small directories keep the minimum
+		{0, 20, 100},
+		{5000, 20, 100},
+		// larger ones grow to about 100 nodes per listing
+		{100000, 20, 1000},
+		{123456, 20, 1235},
+		// until the node reply budget caps them
+		{1000000, 20, 7281},
+		{1000000000, 20, 7281},
+		// long names shrink nodes below the minimum, never below 2
+		{5000, 4000, 65},
+		{5000, 1 << 20, 2},
+		// an unknown name length counts as 1 byte
+		{100000000, 0, 15420},
+	} {
+		size := SuggestBatchSize(&VerifyReport{Names: tc.names}, tc.avgNameLen)
+		assert.Equal(t, tc.expected, size, "%d names of %d bytes", tc.names, tc.avgNameLen)
+	}
+	assert.Equal(t, 100, SuggestBatchSize(nil, 20))
+}
@@ -0,0 +1,98 @@
+package redis3
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// ListByNode visits the names from startFrom inclusively, one call per node with the node id and its names,
+// e.g. to warm a cache or build an index aligned to the nodes. The first node only has its names from startFrom,
+// and with WithNamePrefix the last one only those with the prefix. Nodes without such names are skipped.
+// The node ids change as nodes are split, merged or rebalanced.
+func (nl *ItemList) ListByNode(ctx context.Context, startFrom string, visitNodeFn func(nodeId int64, names []string) bool) error {
+	if err := nl.checkRangeListing(startFrom); err != nil {
+		return err
+	}
+	return nl.listByNode(ctx, startFrom, visitNodeFn)
+}
+
+func (nl *ItemList) listByNode(ctx context.Context, startFrom string, visitNodeFn func(nodeId int64, names []string) bool) error {
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+	startFrom = nl.storedStart(startFrom)
+	prevNode, nextNode, found, err := nl.resolveNeighbors(ctx, startFrom)
+	if err != nil {
+		return err
+	}
+	if found && bytes.Equal(nextNode.Key, []byte(startFrom)) {
+		prevNode = nil
+	}
+
+	nodesScanned := 0
+	if prevNode != nil {
+		nodesScanned++
+		if shouldContinue, err := nl.visitNodeNames(ctx, prevNode, startFrom, visitNodeFn); !shouldContinue || err != nil {
+			return err
+		}
+	}
+	guard := nl.newChainGuard(ctx)
+	for nextNode != nil {
+		if nl.maxListingNodes > 0 && nodesScanned >= nl.maxListingNodes {
+			return &ListingTooLargeError{NodesScanned: nodesScanned, ResumeFrom: string(nextNode.Key)}
+		}
+		if err := guard.step(); err != nil {
+			return err
+		}
+		nodesScanned++
+		if shouldContinue, err := nl.visitNodeNames(ctx, nextNode.Reference(), startFrom, visitNodeFn); !shouldContinue || err != nil {
+			return err
+		}
+		nextNode, err = nl.skipList.LoadElementContext(ctx, nextNode.Next[0])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// visitNodeNames visits the names of node from startFrom, stopping at the first name without the name prefix
+func (nl *ItemList) visitNodeNames(ctx context.Context, node *skiplist.SkipListElementReference, startFrom string, visitNodeFn func(nodeId int64, names []string) bool) (bool, error) {
+	stored, err := nl.nodeRangeInclusiveAfter(ctx, node, startFrom)
+	if err != nil {
+		return false, err
+	}
+	names := make([]string, 0, len(stored))
+	shouldContinue := true
+	for _, s := range stored {
+		name, ok, err := nl.listedName(s)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			// sorted after all names with the prefix
+			shouldContinue = false
+			break
+		}
+		names = append(names, name)
+	}
+	if len(names) > 0 && !visitNodeFn(node.ElementPointer, names) {
+		return false, nil
+	}
+	return shouldContinue, nil
+}
+
+// ListNamesWithNode is ListNames also passing the id of the node holding each name, e.g. to keep an index
+// of name locations aligned with the nodes, resynchronizing the nodes reported by WithOnSplit and WithOnMerge.
+func (nl *ItemList) ListNamesWithNode(ctx context.Context, startFrom string, visitFn func(name string, nodeId int64) bool) error {
+	return nl.ListByNode(ctx, startFrom, func(nodeId int64, names []string) bool {
+		for _, name := range names {
+			if !visitFn(name, nodeId) {
+				return false
+			}
+		}
+		return true
+	})
+}
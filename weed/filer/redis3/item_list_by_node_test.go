@@ -0,0 +1,105 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListByNode(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	var expected []string
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("name%02d", i)
+		expected = append(expected, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+
+	var listed []string
+	nodeIds := map[int64]bool{}
+	assert.NoError(t, nl.ListByNode(context.Background(), "", func(nodeId int64, names []string) bool {
+		assert.False(t, nodeIds[nodeId])
+		nodeIds[nodeId] = true
+		assert.Equal(t, mustNodeNames(t, nl, &skiplist.SkipListElementReference{ElementPointer: nodeId}), names)
+		listed = append(listed, names...)
+		return true
+	}))
+	assert.Equal(t, expected, listed)
+	assert.Equal(t, countNodes(t, nl), len(nodeIds))
+
+	// the first node starts from startFrom, and the listing stops when asked
+	var first []string
+	calls := 0
+	assert.NoError(t, nl.ListByNode(context.Background(), "name04", func(nodeId int64, names []string) bool {
+		calls++
+		first = names
+		return false
+	}))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "name04", first[0])
+	assert.Subset(t, expected[4:], first)
+}
+
+func TestListNamesWithNode(t *testing.T) {
+	var splitNodes []int64
+	nl, _ := newTestItemList(t, 3, WithOnSplit(func(oldNodeId, newNodeId int64, pivot string) {
+		splitNodes = append(splitNodes, oldNodeId, newNodeId)
+	}))
+	var expected []string
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("name%02d", i*2)
+		expected = append(expected, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	indexNames := func() map[string]int64 {
+		index := map[string]int64{}
+		assert.NoError(t, nl.ListNamesWithNode(context.Background(), "", func(name string, nodeId int64) bool {
+			index[name] = nodeId
+			return true
+		}))
+		return index
+	}
+
+	index := indexNames()
+	assert.Len(t, index, len(expected))
+	for name, nodeId := range index {
+		assert.Contains(t, mustNodeNames(t, nl, &skiplist.SkipListElementReference{ElementPointer: nodeId}), name)
+	}
+
+	// the index stays aligned by resynchronizing the nodes written to and those split
+	splitNodes = nil
+	var written []int64
+	for i := 0; i < 5; i++ {
+		result, err := nl.WriteNameDetailed(context.Background(), fmt.Sprintf("name%02d", i*2+1))
+		assert.NoError(t, err)
+		written = append(written, result.NodeId)
+	}
+	assert.NotEmpty(t, splitNodes)
+	for _, nodeId := range append(written, splitNodes...) {
+		for name, indexed := range index {
+			if indexed == nodeId {
+				delete(index, name)
+			}
+		}
+		for _, name := range mustNodeNames(t, nl, &skiplist.SkipListElementReference{ElementPointer: nodeId}) {
+			index[name] = nodeId
+		}
+	}
+	assert.Equal(t, indexNames(), index)
+
+	// from startFrom, in order, until visitFn stops
+	var listed []string
+	var nodeIds []int64
+	assert.NoError(t, nl.ListNamesWithNode(context.Background(), "name03", func(name string, nodeId int64) bool {
+		listed = append(listed, name)
+		nodeIds = append(nodeIds, nodeId)
+		return len(listed) < 5
+	}))
+	assert.Equal(t, []string{"name03", "name04", "name05", "name06", "name07"}, listed)
+	for i, name := range listed {
+		assert.Equal(t, index[name], nodeIds[i])
+	}
+}
@@ -0,0 +1,51 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// NameCollisionPolicy is what WriteName does with WithCaseInsensitive
+// when the name differs only in case from a stored name.
+type NameCollisionPolicy int
+
+const (
+	// NameCollisionReject fails the write with ErrNameCollision, the default
+	NameCollisionReject NameCollisionPolicy = iota
+	// NameCollisionOverwrite deletes the stored name and adds the new one
+	NameCollisionOverwrite
+	// NameCollisionKeepFirst keeps the stored name and skips the write
+	NameCollisionKeepFirst
+)
+
+// resolveCaseCollision applies the collision policy to name, skip telling the write to do nothing
+func (nl *ItemList) resolveCaseCollision(ctx context.Context, name string) (skip bool, err error) {
+	if !nl.caseInsensitive {
+		return false, nil
+	}
+	stored, err := nl.storedNameFolding(ctx, name)
+	if err != nil || stored == "" || stored == name {
+		return false, err
+	}
+	switch nl.collisionPolicy {
+	case NameCollisionOverwrite:
+		return false, nl.DeleteName(ctx, stored)
+	case NameCollisionKeepFirst:
+		return true, nil
+	}
+	return false, fmt.Errorf("write %q: %w: %q", name, ErrNameCollision, stored)
+}
+
+// storedNameFolding returns the stored name equal to name ignoring case, or "" if there is none.
+// Names equal ignoring case share the order key, so they are adjacent and the first one is enough.
+func (nl *ItemList) storedNameFolding(ctx context.Context, name string) (stored string, err error) {
+	orderKey := nl.namePrefix + strings.ToLower(name) + orderKeySeparator
+	err = nl.listNames(ctx, orderKey, nil, func(s string) (bool, error) {
+		if strings.HasPrefix(s, orderKey) {
+			stored, _ = nl.realName(s)
+		}
+		return false, nil
+	})
+	return stored, err
+}
@@ -0,0 +1,36 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseInsensitive(t *testing.T) {
+	for _, tc := range []struct {
+		policy   NameCollisionPolicy
+		expected []string
+	}{
+		{NameCollisionReject, []string{"a", "Readme", "z"}},
+		{NameCollisionOverwrite, []string{"a", "README", "z"}},
+		{NameCollisionKeepFirst, []string{"a", "Readme", "z"}},
+	} {
+		nl, _ := newTestItemList(t, 2, WithCaseInsensitive(tc.policy))
+		for _, name := range []string{"z", "Readme", "a"} {
+			assert.NoError(t, nl.WriteName(context.Background(), name))
+		}
+		err := nl.WriteName(context.Background(), "README")
+		if tc.policy == NameCollisionReject {
+			assert.ErrorIs(t, err, ErrNameCollision)
+		} else {
+			assert.NoError(t, err)
+		}
+		// the same name is not a collision
+		assert.NoError(t, nl.WriteName(context.Background(), tc.expected[1]))
+		assert.Equal(t, tc.expected, listAllNames(t, nl, ""), tc.policy)
+		assertItemListConsistent(t, nl)
+	}
+	// the default policy rejects
+	assert.Equal(t, NameCollisionReject, NameCollisionPolicy(0))
+}
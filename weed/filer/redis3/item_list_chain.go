@@ -0,0 +1,90 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+A corrupted skiplist store can link the nodes into a cycle, and walking node.Next[0] would never end.
+Each walk counts its nodes against a bound, and fails with ErrPossibleCycle once it is exceeded.
+The bound is WithMaxChainNodes, or else defaultMaxChainNodes, well above the nodes of any directory.
+With WithMaintainedNodeCount, larger lists are bounded by twice the counted nodes, which can lag behind
+since counts are created lazily.
+*/
+
+const (
+	defaultMaxChainNodes     = 1000000
+	chainNodesPerCountedNode = 2
+)
+
+type chainGuard struct {
+	ctx    context.Context
+	prefix string
+	limit  int
+	walked int
+}
+
+func (nl *ItemList) newChainGuard(ctx context.Context) *chainGuard {
+	guard := &chainGuard{ctx: ctx, prefix: nl.prefix, limit: nl.maxChainNodes}
+	if guard.limit > 0 {
+		return guard
+	}
+	guard.limit = defaultMaxChainNodes
+	if nl.maintainNodeCount {
+		if counted, err := nl.client.HLen(ctx, nl.nodeCountKey()).Result(); err == nil {
+			guard.limit = max(int(counted)*chainNodesPerCountedNode, defaultMaxChainNodes)
+		}
+	}
+	return guard
+}
+
+// step counts one more walked node, failing once the context of the walk is done
+func (g *chainGuard) step() error {
+	if err := g.ctx.Err(); err != nil {
+		return err
+	}
+	g.walked++
+	if g.walked > g.limit {
+		return fmt.Errorf("%w: %s has more than %d nodes", ErrPossibleCycle, g.prefix, g.limit)
+	}
+	return nil
+}
+
+// load loads the node at ref of a walk over t, nil at the end of the list, counting it with step
+func (g *chainGuard) load(t *skiplist.SkipList, ref *skiplist.SkipListElementReference) (*skiplist.SkipListElement, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	node, err := t.LoadElementContext(g.ctx, ref)
+	if err != nil || node == nil {
+		return nil, err
+	}
+	if err := g.step(); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// walkNodes visits the nodes of the list in order, until fn returns false or an error
+func (nl *ItemList) walkNodes(ctx context.Context, fn func(node *skiplist.SkipListElement) (bool, error)) error {
+	return nl.walkNodesOf(ctx, nl.skipList, fn)
+}
+
+// walkNodesOf is walkNodes over the nodes of t
+func (nl *ItemList) walkNodesOf(ctx context.Context, t *skiplist.SkipList, fn func(node *skiplist.SkipListElement) (bool, error)) error {
+	guard := nl.newChainGuard(ctx)
+	nodeRef := t.StartLevels[0]
+	for {
+		node, err := guard.load(t, nodeRef)
+		if err != nil || node == nil {
+			return err
+		}
+		if shouldContinue, err := fn(node); !shouldContinue || err != nil {
+			return err
+		}
+		nodeRef = node.Next[0]
+	}
+}
@@ -0,0 +1,58 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPossibleCycle(t *testing.T) {
+	nl, _ := newTestItemList(t, 2, WithMaxChainNodes(20))
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("name%02d", i)))
+	}
+	_, err := nl.ListNodeBoundaries(context.Background())
+	assert.NoError(t, err)
+
+	// link the last node back to the first one
+	last, err := nl.skipList.LoadElement(nl.skipList.EndLevels[0])
+	assert.NoError(t, err)
+	last.Next[0] = nl.skipList.StartLevels[0]
+	assert.NoError(t, nl.skipList.ListStore.SaveElement(last.Id, last))
+
+	assert.ErrorIs(t, nl.ListNames(context.Background(), "", func(name string) bool { return true }), ErrPossibleCycle)
+	assert.ErrorIs(t, withOptions(nl, WithListingPrefetch(2)).ListNames(context.Background(), "", func(name string) bool { return true }), ErrPossibleCycle)
+	_, err = nl.ListNodeBoundaries(context.Background())
+	assert.ErrorIs(t, err, ErrPossibleCycle)
+	it := nl.Iterator(context.Background(), "")
+	for it.Next() {
+	}
+	assert.ErrorIs(t, it.Err(), ErrPossibleCycle)
+	_, err = nl.Count(context.Background())
+	assert.ErrorIs(t, err, ErrPossibleCycle)
+	_, err = nl.Verify(context.Background())
+	assert.ErrorIs(t, err, ErrPossibleCycle)
+
+	// stopping early does not reach the bound
+	var names []string
+	assert.NoError(t, nl.ListNames(context.Background(), "", func(name string) bool {
+		names = append(names, name)
+		return len(names) < 30
+	}))
+
+	// without WithMaxChainNodes, the bound follows the maintained node count of larger lists
+	assert.Equal(t, defaultMaxChainNodes, withOptions(nl, WithMaxChainNodes(0)).newChainGuard(context.Background()).limit)
+	counted, _ := newTestItemList(t, 2, WithMaintainedNodeCount())
+	assert.NoError(t, counted.client.HSet(context.Background(), counted.nodeCountKey(), "1", 1).Err())
+	assert.Equal(t, defaultMaxChainNodes, counted.newChainGuard(context.Background()).limit)
+	nodes := defaultMaxChainNodes/chainNodesPerCountedNode + 1
+	fields := make(map[string]interface{}, nodes)
+	for i := 0; i < nodes; i++ {
+		fields[strconv.Itoa(i)] = 1
+	}
+	assert.NoError(t, counted.client.HSet(context.Background(), counted.nodeCountKey(), fields).Err())
+	assert.Equal(t, nodes*chainNodesPerCountedNode, counted.newChainGuard(context.Background()).limit)
+}
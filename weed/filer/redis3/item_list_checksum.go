@@ -0,0 +1,61 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/*
+With WithChecksum, "<prefix>checksum" holds the sum of the FNV-1a hashes of all names.
+Adding a name adds its hash and removing a name subtracts it, so the update is one INCRBY,
+and the sum stays order independent. Names moved between nodes by splits and merges do not count.
+*/
+
+const checksumSuffix = "checksum"
+
+func (nl *ItemList) checksumKey() string {
+	return nl.prefix + checksumSuffix
+}
+
+func nameChecksum(name string) int64 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int64(h.Sum32())
+}
+
+func (nl *ItemList) adjustChecksum(ctx context.Context, name string, sign int64) error {
+	if !nl.checksum {
+		return nil
+	}
+	if err := nl.client.IncrBy(ctx, nl.checksumKey(), sign*nameChecksum(name)).Err(); err != nil {
+		return fmt.Errorf("update checksum: %w", wrapRedisError(err))
+	}
+	return nil
+}
+
+func (nl *ItemList) deleteChecksum(ctx context.Context) error {
+	if !nl.checksum {
+		return nil
+	}
+	return wrapRedisError(nl.client.Del(ctx, nl.checksumKey()).Err())
+}
+
+// VerifyChecksum recomputes the checksum from all listed names and compares it with the stored one.
+// A list without a stored checksum matches only if it is empty.
+func (nl *ItemList) VerifyChecksum(ctx context.Context) (bool, error) {
+	stored, err := nl.client.Get(ctx, nl.checksumKey()).Int64()
+	if err != nil && err != redis.Nil {
+		return false, fmt.Errorf("read checksum: %w", wrapRedisError(err))
+	}
+	var computed int64
+	if err := nl.listNames(ctx, "", nil, func(name string) (bool, error) {
+		computed += nameChecksum(name)
+		return true, nil
+	}); err != nil {
+		return false, err
+	}
+	return stored == computed, nil
+}
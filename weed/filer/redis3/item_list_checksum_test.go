@@ -0,0 +1,40 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksum(t *testing.T) {
+	nl, server := newTestItemList(t, 3, WithChecksum())
+
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("name%02d", i)))
+	}
+	// re-adding and deleting missing names do not change the checksum
+	assert.NoError(t, nl.WriteName(context.Background(), "name05"))
+	assert.NoError(t, nl.DeleteName(context.Background(), "missing"))
+	for i := 0; i < 20; i += 3 {
+		assert.NoError(t, nl.DeleteName(context.Background(), fmt.Sprintf("name%02d", i)))
+	}
+	ok, err := nl.VerifyChecksum(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// a member set changed out of band is detected
+	node := nl.skipList.StartLevels[0]
+	_, err = server.ZAdd(fmt.Sprintf("%s%dm", nl.prefix, node.ElementPointer), 0, "name01x")
+	assert.NoError(t, err)
+	ok, err = nl.VerifyChecksum(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, nl.RemoteAllListElement(context.Background()))
+	assert.False(t, server.Exists(nl.checksumKey()))
+	ok, err = nl.VerifyChecksum(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
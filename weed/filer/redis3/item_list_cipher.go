@@ -0,0 +1,89 @@
+package redis3
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+/*
+With WithNameCipher, names are encrypted before they are stored as members, so Redis only holds ciphertexts.
+The name prefix of WithNamePrefix and the list prefix stay readable.
+
+The cipher must be deterministic, as NewDeterministicNameCipher, encrypting the same name to the same ciphertext,
+so names are still written, deleted and found by name, but nothing of a name is leaked except its length,
+and which stored names are equal over time. The ciphertexts sort randomly, so names are listed in no particular order,
+and listings from a startFrom, pages after a marker and common prefixes fail with ErrUnsupported.
+Listing in name order requires plaintext names: no order preserving encryption is offered, as any cipher
+keeping the order leaks so much of the names, their order and shared prefixes first, that it would protect little.
+*/
+
+// ErrInvalidCiphertext means a stored name could not be decrypted, e.g. with a different key.
+var ErrInvalidCiphertext = errors.New("redis3: stored name can not be decrypted")
+
+// NameCipher encrypts names for WithNameCipher. EncryptName must be deterministic.
+type NameCipher interface {
+	EncryptName(name string) string
+	DecryptName(ciphertext string) (string, error)
+}
+
+// deriveKey derives a 32 byte subkey of key for purpose
+func deriveKey(key []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+type deterministicNameCipher struct {
+	aead      cipher.AEAD
+	ivKey     []byte
+	nonceSize int
+}
+
+// NewDeterministicNameCipher encrypts names with AES-GCM under a nonce derived from the name by HMAC-SHA256,
+// a synthetic IV, so ciphertexts are deterministic and authenticated. The key should be at least 16 random bytes.
+func NewDeterministicNameCipher(key []byte) (NameCipher, error) {
+	if len(key) < 16 {
+		return nil, fmt.Errorf("name cipher key of %d bytes, at least 16 needed", len(key))
+	}
+	block, err := aes.NewCipher(deriveKey(key, "redis3 name encryption"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &deterministicNameCipher{aead: aead, ivKey: deriveKey(key, "redis3 name iv"), nonceSize: aead.NonceSize()}, nil
+}
+
+func (c *deterministicNameCipher) EncryptName(name string) string {
+	mac := hmac.New(sha256.New, c.ivKey)
+	mac.Write([]byte(name))
+	nonce := mac.Sum(nil)[:c.nonceSize]
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, []byte(name), nil))
+}
+
+func (c *deterministicNameCipher) DecryptName(ciphertext string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(ciphertext)
+	if err != nil || len(data) < c.nonceSize {
+		return "", ErrInvalidCiphertext
+	}
+	name, err := c.aead.Open(nil, data[:c.nonceSize], data[c.nonceSize:], nil)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(name), nil
+}
+
+// checkRangeListing rejects listings from a position when the stored names are not in name order
+func (nl *ItemList) checkRangeListing(startFrom string) error {
+	if startFrom != "" && nl.nameCipher != nil {
+		return fmt.Errorf("list %s from %q: %w: names are encrypted out of order", nl.prefix, startFrom, ErrUnsupported)
+	}
+	return nil
+}
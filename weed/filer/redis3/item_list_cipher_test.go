@@ -0,0 +1,68 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameCipher(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	deterministic, err := NewDeterministicNameCipher(key)
+	assert.NoError(t, err)
+	_, err = NewDeterministicNameCipher(key[:8])
+	assert.Error(t, err)
+
+	r := rand.New(rand.NewSource(1))
+	var names []string
+	for i := 0; i < 60; i++ {
+		name := fmt.Sprintf("dir%d/secret-%d.txt", i%4, r.Intn(1000))
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	names = append(names, "a", "a\x01", "ab", "名前")
+	sorted := slices.Clone(names)
+	sort.Strings(sorted)
+
+	nl, server := newTestItemList(t, 5, WithNameCipher(deterministic))
+	for _, name := range names {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+		decrypted, err := deterministic.DecryptName(deterministic.EncryptName(name))
+		assert.NoError(t, err)
+		assert.Equal(t, name, decrypted)
+	}
+	assert.NoError(t, nl.WriteName(context.Background(), names[0]))
+	for _, key := range server.Keys() {
+		if members, err := server.ZMembers(key); err == nil {
+			for _, member := range members {
+				assert.NotContains(t, member, "secret")
+			}
+		}
+	}
+	assert.ElementsMatch(t, sorted, listAllNames(t, nl, ""))
+	found, err := nl.ExistsMany(context.Background(), []string{names[1], "missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{names[1]: true, "missing": false}, found)
+	assert.NoError(t, nl.DeleteName(context.Background(), names[1]))
+	expected := slices.DeleteFunc(slices.Clone(sorted), func(name string) bool { return name == names[1] })
+	assert.ElementsMatch(t, expected, listAllNames(t, nl, ""))
+	assert.ErrorIs(t, nl.ListNames(context.Background(), "dir1", func(string) bool { return true }), ErrUnsupported)
+	_, _, _, err = nl.Page(context.Background(), expected[3], 2)
+	assert.ErrorIs(t, err, ErrUnsupported)
+	_, _, err = nl.NextCommonPrefix(context.Background(), "", "/")
+	assert.ErrorIs(t, err, ErrUnsupported)
+	assertItemListConsistent(t, nl)
+
+	other, _ := NewDeterministicNameCipher([]byte("fedcba9876543210"))
+	_, err = other.DecryptName(deterministic.EncryptName("name"))
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+
+	_, err = newItemList(nil, "/test/dir", nil, 5, WithNameCipher(deterministic), WithCaseInsensitive(NameCollisionReject))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
@@ -0,0 +1,61 @@
+package redis3
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/*
+A ClientState keeps what ItemLists learn about their Redis client: the result of the lex probe of WithLexFallback,
+and the counting hook of WithCommandStats, added to the client once. It lives as long as whoever holds it,
+e.g. a store keeping one next to its client, rather than for the life of the process.
+Lists loaded again and again for the same client, as for each operation on a directory, share one with WithClientState.
+A list without one keeps its own, so it probes again, and with WithCommandStats adds another hook to the client.
+*/
+
+// lex probe results of a ClientState
+const (
+	lexUnprobed int32 = iota
+	lexSupported
+	lexUnsupported
+)
+
+// ClientState is the state shared by the ItemLists of one Redis client, see WithClientState.
+type ClientState struct {
+	client redis.UniversalClient
+	lex    atomic.Int32
+	mu     sync.Mutex
+	stats  *commandStats
+}
+
+// NewClientState returns an empty state for the lists using client.
+func NewClientState(client redis.UniversalClient) *ClientState {
+	return &ClientState{client: client}
+}
+
+// commandStats returns the counting hook, adding it to the client on first use
+func (s *ClientState) commandStats() *commandStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stats == nil {
+		s.stats = &commandStats{}
+		s.client.AddHook(s.stats)
+	}
+	return s.stats
+}
+
+// resolveClientState gives nl its own ClientState unless one is shared, and the command counting hook once asked for
+func (nl *ItemList) resolveClientState() error {
+	if nl.clientState == nil {
+		nl.clientState = NewClientState(nl.client)
+	} else if nl.clientState.client != nl.client {
+		return fmt.Errorf("%w: WithClientState of another client", ErrInvalidOptions)
+	}
+	if nl.countCommands {
+		nl.commandStats = nl.clientState.commandStats()
+	}
+	return nil
+}
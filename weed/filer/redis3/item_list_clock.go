@@ -0,0 +1,15 @@
+package redis3
+
+import "time"
+
+// Clock tells the time to the time based features of an ItemList, e.g. the window of WithWriteDeduplication.
+// Times measured by Redis, like key expiry, and command timings are not affected.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,25 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemListWriteDeduplicationWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	nl, server := newTestItemList(t, 3, WithWriteDeduplication(time.Minute, 10), WithClock(clock))
+
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	clock.now = clock.now.Add(59 * time.Second)
+	commands := server.CommandCount()
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	assert.Equal(t, commands, server.CommandCount())
+
+	// the window is over
+	clock.now = clock.now.Add(time.Second)
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	assert.Less(t, commands, server.CommandCount())
+}
@@ -0,0 +1,56 @@
+package redis3
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// isCluster tells whether the client talks to a Redis Cluster,
+// where multi-key commands and transactions must stay within one hash slot, and SELECT is not available.
+func (nl *ItemList) isCluster() bool {
+	_, ok := nl.client.(*redis.ClusterClient)
+	return ok
+}
+
+// HashTaggedPrefix is prefix as a Redis Cluster hash tag, "{<prefix>}", so the list header, the skiplist elements
+// of SkipListElementStore, the member sets and all other keys of the list are in one hash slot,
+// and node merges stay atomic in cluster mode. A list moved to a tagged prefix is a new list.
+func HashTaggedPrefix(prefix string) string {
+	return "{" + prefix + "}"
+}
+
+// isColocated tells whether all keys of the list are in the hash slot of the first hash tag of the prefix
+func (nl *ItemList) isColocated() bool {
+	start := strings.IndexByte(nl.prefix, '{')
+	return start >= 0 && strings.IndexByte(nl.prefix[start+1:], '}') > 0
+}
+
+// scanKeys visits the keys matching pattern. A cluster client scans only one node,
+// so in cluster mode each master is scanned. visitFn is not called concurrently.
+func (nl *ItemList) scanKeys(ctx context.Context, pattern string, visitFn func(key string) error) error {
+	cluster, ok := nl.client.(*redis.ClusterClient)
+	if !ok {
+		return scanKeysOn(ctx, nl.client, pattern, visitFn)
+	}
+	var mu sync.Mutex
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		return scanKeysOn(ctx, client, pattern, func(key string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			return visitFn(key)
+		})
+	})
+}
+
+func scanKeysOn(ctx context.Context, client redis.UniversalClient, pattern string, visitFn func(key string) error) error {
+	iter := client.Scan(ctx, 0, pattern, 1000).Iterator()
+	for iter.Next(ctx) {
+		if err := visitFn(iter.Val()); err != nil {
+			return err
+		}
+	}
+	return wrapRedisError(iter.Err())
+}
@@ -0,0 +1,102 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterDetection(t *testing.T) {
+	nl, server := newTestItemList(t, 3)
+	assert.False(t, nl.Stats().Cluster)
+	assert.Equal(t, 3, nl.Stats().BatchSize)
+
+	cluster := redis.NewClusterClient(&redis.ClusterOptions{
+		Addrs: []string{server.Addr()},
+	})
+	defer cluster.Close()
+	clustered := mustNewItemList(t, cluster, nl.prefix, newSkipListElementStore(nl.prefix, cluster), 3, WithNamespaceCheck(true))
+	assert.True(t, clustered.Stats().Cluster)
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		assert.NoError(t, clustered.WriteName(context.Background(), name))
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d"}, listAllNames(t, clustered, ""))
+
+	// keys are scanned on every master
+	var keys []string
+	assert.NoError(t, clustered.scanKeys(context.Background(), escapeScanPattern(nl.prefix)+"*m", func(key string) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	assert.Len(t, keys, 2)
+
+	// a cluster has no other database
+	other := mustNewItemList(t, cluster, nl.prefix, newSkipListElementStore(nl.prefix, cluster), 3, WithDatabase(1))
+	assert.ErrorIs(t, other.WriteName(context.Background(), "e"), ErrNamespaceConflict)
+}
+
+func TestListStoresInterchangeable(t *testing.T) {
+	redisList, redisServer := newTestItemList(t, 3)
+	memServer := miniredis.RunT(t)
+	memClient := redis.NewClient(&redis.Options{Addr: memServer.Addr()})
+	t.Cleanup(func() {
+		memClient.Close()
+	})
+	memList := mustNewItemList(t, memClient, "/test/dir", skiplist.NewMemStore(), 3)
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		name, del := fmt.Sprintf("n%03d", rnd.Intn(60)), rnd.Intn(3) == 0
+		for _, nl := range []*ItemList{redisList, memList} {
+			if del {
+				assert.NoError(t, nl.DeleteName(context.Background(), name))
+			} else {
+				assert.NoError(t, nl.WriteName(context.Background(), name))
+			}
+		}
+	}
+	assert.Equal(t, listAllNames(t, redisList, ""), listAllNames(t, memList, ""))
+	redisBoundaries, err := redisList.ListNodeBoundaries(context.Background())
+	assert.NoError(t, err)
+	memBoundaries, err := memList.ListNodeBoundaries(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, redisBoundaries, memBoundaries)
+	assertItemListConsistent(t, redisList)
+	assertItemListConsistent(t, memList)
+	// the elements live next to the member sets, under the prefix
+	assert.Len(t, redisServer.Keys(), 2*len(redisBoundaries))
+	assert.Len(t, memServer.Keys(), len(memBoundaries))
+}
+
+func TestHashTaggedPrefix(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	nl, err := NewItemList(Config{Client: client, Prefix: "/test/dir", BatchSize: 3, HashTag: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "{/test/dir}", nl.prefix)
+	assert.True(t, nl.isColocated())
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	assert.NoError(t, nl.DeleteName(context.Background(), "d"))
+	assert.NoError(t, client.Set(context.Background(), nl.prefix, nl.ToBytes(), 0).Err())
+	for _, key := range server.Keys() {
+		assert.True(t, strings.HasPrefix(key, "{/test/dir}"), key)
+	}
+
+	plain, _ := newTestItemList(t, 3)
+	assert.False(t, plain.isColocated())
+	WithNamePrefix("{}")(plain)
+	assert.False(t, plain.isColocated())
+}
@@ -0,0 +1,52 @@
+package redis3
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// commandStats is a go-redis hook counting and timing the commands of one client.
+// It is added once per ClientState, and shared by the ItemLists using it with WithCommandStats.
+type commandStats struct {
+	commands   atomic.Int64
+	roundTrips atomic.Int64
+	nanos      atomic.Int64
+	// errors counts the failed commands by redisErrorTypes
+	errors [len(redisErrorTypes)]atomic.Int64
+}
+
+func (s *commandStats) record(commands int, elapsed time.Duration) {
+	s.commands.Add(int64(commands))
+	s.roundTrips.Add(1)
+	s.nanos.Add(int64(elapsed))
+}
+
+func (s *commandStats) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (s *commandStats) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		s.record(1, time.Since(start))
+		s.recordErrors(cmd)
+		return err
+	}
+}
+
+func (s *commandStats) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		s.record(len(cmds), time.Since(start))
+		s.recordErrors(cmds...)
+		return err
+	}
+}
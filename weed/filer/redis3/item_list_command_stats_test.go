@@ -0,0 +1,39 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandStats(t *testing.T) {
+	plain, _ := newTestItemList(t, 3)
+	assert.NoError(t, plain.WriteName(context.Background(), "a"))
+	assert.Zero(t, plain.Stats().Commands)
+
+	nl, _ := newTestItemList(t, 3, WithCommandStats())
+	state := nl.clientState
+	for _, name := range []string{"a", "b", "c", "d"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	stats := nl.Stats()
+	assert.Positive(t, stats.RoundTrips)
+	assert.Less(t, stats.RoundTrips, stats.Commands)
+	assert.Positive(t, stats.RedisTime)
+
+	// the hook is added once per client state
+	again := mustNewItemList(t, nl.client, nl.prefix, nl.skipList.ListStore, 3, WithCommandStats(), WithClientState(state))
+	assert.NoError(t, again.WriteName(context.Background(), "e"))
+	assert.Equal(t, again.Stats().Commands, nl.Stats().Commands)
+	assert.Less(t, stats.Commands, nl.Stats().Commands)
+
+	// a list without the shared state counts on its own
+	own := mustNewItemList(t, nl.client, nl.prefix, nl.skipList.ListStore, 3, WithCommandStats())
+	assert.NotSame(t, state, own.clientState)
+	assert.NoError(t, own.WriteName(context.Background(), "f"))
+	assert.Less(t, own.Stats().Commands, nl.Stats().Commands)
+
+	_, err := newItemList(plain.client, nl.prefix, nl.skipList.ListStore, 3, WithClientState(state))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
@@ -0,0 +1,63 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// NextCommonPrefix returns the first common prefix after after, a name truncated after its first delimiter,
+// to list a flat namespace by delimiter like S3 CommonPrefixes. Pass the returned prefix as after for the next one:
+// all names under a prefix, or under the prefix covering after, are skipped by starting the listing past them,
+// so listing the prefixes reads one node per prefix, plus the names without delimiter in between.
+// Names are only adjacent by prefix in name order, so WithOrderKey is not supported.
+func (nl *ItemList) NextCommonPrefix(ctx context.Context, after string, delimiter string) (prefix string, found bool, err error) {
+	if delimiter == "" {
+		return "", false, fmt.Errorf("empty delimiter")
+	}
+	if nl.orderKey != nil {
+		return "", false, fmt.Errorf("common prefixes of %s: %w: WithOrderKey", nl.prefix, ErrInvalidOptions)
+	}
+	if nl.nameCipher != nil {
+		return "", false, fmt.Errorf("common prefixes of %s: %w: names are encrypted out of order", nl.prefix, ErrUnsupported)
+	}
+	startFrom := after
+	if strings.HasSuffix(after, delimiter) {
+		if startFrom, found = prefixEnd(after); !found {
+			return "", false, nil
+		}
+	}
+	for {
+		found = false
+		err = nl.ListNamesWithError(ctx, startFrom, func(name string) (bool, error) {
+			if name == after {
+				return true, nil
+			}
+			i := strings.Index(name, delimiter)
+			if i < 0 {
+				return true, nil
+			}
+			prefix, found = name[:i+len(delimiter)], true
+			return false, nil
+		})
+		if err != nil || !found || !strings.HasPrefix(after, prefix) {
+			return prefix, found, err
+		}
+		// after is under prefix, continue after all its names
+		if startFrom, found = prefixEnd(prefix); !found {
+			return "", false, nil
+		}
+	}
+}
+
+// prefixEnd returns the smallest string after all strings with prefix, false if there is none
+func prefixEnd(prefix string) (string, bool) {
+	b := []byte(prefix)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xff {
+			b[i]++
+			return string(b[:i+1]), true
+		}
+	}
+	return "", false
+}
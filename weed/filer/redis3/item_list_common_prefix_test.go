@@ -0,0 +1,47 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextCommonPrefix(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	for _, name := range []string{"a", "b/1", "b/2", "b/3", "b/4/5", "c", "d/x/1", "d/y", "e--1", "e/1", "f"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	var prefixes []string
+	after := ""
+	for {
+		prefix, found, err := nl.NextCommonPrefix(context.Background(), after, "/")
+		assert.NoError(t, err)
+		if !found {
+			break
+		}
+		prefixes = append(prefixes, prefix)
+		after = prefix
+	}
+	assert.Equal(t, []string{"b/", "d/", "e/"}, prefixes)
+
+	// a name under a prefix resumes after the prefix
+	prefix, found, err := nl.NextCommonPrefix(context.Background(), "b/2", "/")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "d/", prefix)
+
+	prefix, found, err = nl.NextCommonPrefix(context.Background(), "", "--")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "e--", prefix)
+
+	end, ok := prefixEnd("b/")
+	assert.True(t, ok)
+	assert.Equal(t, "b0", end)
+	end, ok = prefixEnd("a\xff\xff")
+	assert.True(t, ok)
+	assert.Equal(t, "b", end)
+	_, ok = prefixEnd("\xff")
+	assert.False(t, ok)
+}
@@ -0,0 +1,177 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// CompactRange merges the underfull nodes whose keys are in [from, to), the empty to meaning up to the last node,
+// so a large directory is defragmented in bounded chunks instead of one RebalanceToBatchSize.
+// A node absorbs its next nodes in the range while they together hold fewer than batchSize names, as DeleteName merges.
+// Empty nodes are removed, except those of PreSplit, and a node whose key is not its smallest name is rekeyed.
+// Running it again over the same or sliding ranges leaves the compacted nodes as they are.
+// Persist the list header afterwards, as after WriteName.
+func (nl *ItemList) CompactRange(ctx context.Context, from, to string) error {
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	if err := nl.checkRangeListing(from); err != nil {
+		return err
+	}
+	if err := nl.checkRangeListing(to); err != nil {
+		return err
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+	lo, hi := nl.storedStart(from), ""
+	if to != "" {
+		hi = nl.storedName(to)
+		if hi <= lo {
+			return fmt.Errorf("compact %s: %w: range [%q, %q) is empty", nl.prefix, ErrInvalidOptions, from, to)
+		}
+	}
+	inRange := func(key []byte) bool {
+		return hi == "" || string(key) < hi
+	}
+	preSplit, err := nl.preSplitNodes(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, node, _, err := nl.skipList.FindGreaterOrEqualContext(ctx, []byte(lo))
+	if err != nil {
+		return err
+	}
+	merged, removed, rekeyed := 0, 0, 0
+	guard := nl.newChainGuard(ctx)
+	for node != nil && inRange(node.Key) {
+		if err := guard.step(); err != nil {
+			return err
+		}
+		next, err := nl.loadNextNode(ctx, node)
+		if err != nil {
+			return err
+		}
+		if preSplit[node.Id] {
+			node = next
+			continue
+		}
+		size, err := nl.nodeSize(ctx, node.Reference())
+		if err != nil {
+			return err
+		}
+		if size == 0 {
+			if err := nl.removeEmptyNode(ctx, node); err != nil {
+				return err
+			}
+			removed++
+			node = next
+			continue
+		}
+		if ok, err := nl.rekeyNode(ctx, node, next); err != nil {
+			return err
+		} else if ok {
+			rekeyed++
+		}
+
+		for next != nil && inRange(next.Key) && !preSplit[next.Id] {
+			nextSize, err := nl.nodeSize(ctx, next.Reference())
+			if err != nil {
+				return err
+			}
+			if size+nextSize >= nl.batchSize {
+				break
+			}
+			if err := nl.mergeNextNode(ctx, node, next); err != nil {
+				return err
+			}
+			merged++
+			size += nextSize
+			if next, err = nl.loadNextNode(ctx, next); err != nil {
+				return err
+			}
+		}
+		node = next
+	}
+	if merged+removed+rekeyed > 0 {
+		glog.V(0).Infof("compact %s [%q, %q): merged %d nodes, removed %d empty nodes, rekeyed %d nodes",
+			nl.prefix, from, to, merged, removed, rekeyed)
+	}
+	return nil
+}
+
+// loadNextNode loads the node after node by its id, as the key of the next reference may be stale
+func (nl *ItemList) loadNextNode(ctx context.Context, node *skiplist.SkipListElement) (*skiplist.SkipListElement, error) {
+	if node.Next[0] == nil {
+		return nil, nil
+	}
+	return nl.loadElement(ctx, node.Next[0].ElementPointer)
+}
+
+// removeEmptyNode removes an empty node from the skiplist and deletes its member set
+func (nl *ItemList) removeEmptyNode(ctx context.Context, node *skiplist.SkipListElement) error {
+	end, err := nl.beginStructuralChange(ctx)
+	if err != nil {
+		return err
+	}
+	defer end()
+	if err := nl.deleteNodeElement(ctx, node.Reference()); err != nil {
+		return err
+	}
+	return nl.nodeDelete(ctx, node.Reference())
+}
+
+// rekeyNode sets the key of node to its smallest name when that sorts after the key and before the next node,
+// reporting whether it did. A smallest name outside of that range is misplaced and left to Reconcile.
+func (nl *ItemList) rekeyNode(ctx context.Context, node, next *skiplist.SkipListElement) (bool, error) {
+	minName, err := nl.nodeMin(ctx, node.Reference())
+	if err != nil {
+		return false, err
+	}
+	if minName == "" || minName <= string(node.Key) || next != nil && minName >= string(next.Key) {
+		return false, nil
+	}
+	end, err := nl.beginStructuralChange(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer end()
+	if err := nl.deleteNodeElement(ctx, node.Reference()); err != nil {
+		return false, err
+	}
+	if _, err := nl.itemAdd(ctx, []byte(minName), node.Id); err != nil {
+		return false, err
+	}
+	node.Key = []byte(minName)
+	return true, nil
+}
+
+// rekeyStaleNode is rekeyNode for a node known by reference
+func (nl *ItemList) rekeyStaleNode(ctx context.Context, node *skiplist.SkipListElementReference, next *skiplist.SkipListElement) (bool, error) {
+	element, err := nl.loadElement(ctx, node.ElementPointer)
+	if err != nil || element == nil {
+		return false, err
+	}
+	return nl.rekeyNode(ctx, element, next)
+}
+
+// mergeNextNode moves the names of next into node and removes next, as case 3.1 of DeleteName
+func (nl *ItemList) mergeNextNode(ctx context.Context, node, next *skiplist.SkipListElement) error {
+	end, err := nl.beginStructuralChange(ctx)
+	if err != nil {
+		return err
+	}
+	defer end()
+	if err := nl.deleteNodeElement(ctx, next.Reference()); err != nil {
+		return err
+	}
+	if err := nl.mergeNodeMembers(ctx, node.Reference(), next.Reference()); err != nil {
+		return err
+	}
+	nl.notifyMerge(node.Id, next.Id)
+	return nil
+}
@@ -0,0 +1,43 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactRange(t *testing.T) {
+	nl, _ := newTestItemList(t, 4)
+	// underfull nodes, an empty node and a node keyed before its smallest name, as left by failed writes
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		_, err := nl.itemAdd(context.Background(), []byte(name), 0, name)
+		assert.NoError(t, err)
+	}
+	_, err := nl.itemAdd(context.Background(), []byte("w"), 0)
+	assert.NoError(t, err)
+	_, err = nl.itemAdd(context.Background(), []byte("x0"), 0, "x1")
+	assert.NoError(t, err)
+	expected := []string{"a", "b", "c", "d", "e", "f", "g", "h", "x1"}
+
+	assert.NoError(t, nl.CompactRange(context.Background(), "c", "g"))
+	// c absorbs d and e, f is full with them
+	assert.Equal(t, 8, countNodes(t, nl))
+	owner, err := nl.nodeOf(context.Background(), "d")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "d", "e"}, mustNodeNames(t, nl, owner))
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assert.NoError(t, nl.CompactRange(context.Background(), "c", "g"))
+	assert.Equal(t, 8, countNodes(t, nl))
+
+	assert.NoError(t, nl.CompactRange(context.Background(), "", ""))
+	assert.Equal(t, 4, countNodes(t, nl))
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+	assert.NoError(t, nl.CompactRange(context.Background(), "", ""))
+	assert.Equal(t, 4, countNodes(t, nl))
+
+	assert.ErrorIs(t, nl.CompactRange(context.Background(), "g", "c"), ErrInvalidOptions)
+	WithReadOnly()(nl)
+	assert.ErrorIs(t, nl.CompactRange(context.Background(), "", ""), ErrReadOnly)
+}
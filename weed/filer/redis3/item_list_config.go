@@ -0,0 +1,149 @@
+package redis3
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config gathers the settings of an ItemList for NewItemList, as one documented alternative to
+// LoadItemList with positional arguments and options. The zero value of each setting is the default.
+//
+// Some settings belong to the client rather than the list, and have no field: timeouts and retries are
+// the ReadTimeout, WriteTimeout and MaxRetries of the redis options of Client, and read replicas are
+// its ReadOnly and RouteByLatency cluster options. The list holds no lock itself, writers hold the directory lock,
+// which Maintenance takes as its Lock. Keys of a list never expire, so there is no TTL.
+type Config struct {
+	Client redis.UniversalClient
+	// Prefix is the key of the list header, and the prefix of all keys of the list,
+	// wrapped by HashTaggedPrefix when HashTag is set
+	Prefix  string
+	HashTag bool
+	// Store holds the skiplist elements, by default a SkipListElementStore under Prefix on Client
+	Store     skiplist.ListStore
+	BatchSize int
+	// Header is the list header as persisted from ToBytes, empty for a new list
+	Header []byte
+
+	// ReadOnly is WithReadOnly, and NamespaceCheck with NamespaceStrict is WithNamespaceCheck
+	ReadOnly        bool
+	NamespaceCheck  bool
+	NamespaceStrict bool
+	// NamePrefix is WithNamePrefix
+	NamePrefix string
+	// MaintainedNodeCount, Checksum, NameScores and SafeMode are the options of the same names
+	MaintainedNodeCount bool
+	Checksum            bool
+	NameScores          bool
+	SafeMode            bool
+	// MaxListingNodes is WithMaxListingNodes, 0 unlimited
+	MaxListingNodes int
+	// CommandStats, Tracer and Clock are WithCommandStats, WithTracer and WithClock
+	CommandStats bool
+	Tracer       trace.Tracer
+	Clock        Clock
+	// ClientState is WithClientState
+	ClientState *ClientState
+	// SlowOperationThreshold is WithSlowOperationLog, 0 not logging
+	SlowOperationThreshold time.Duration
+	// Mirror is WithMirror, and DirectoryVersion WithDirectoryVersion
+	Mirror           redis.UniversalClient
+	DirectoryVersion bool
+	// Maintenance is WithMaintenance, nil not maintaining, and Close must stop it
+	Maintenance *MaintenanceConfig
+	// Options are applied after the settings above, for the options without a field
+	Options []ItemListOption
+}
+
+// NewItemList loads the list configured by config, rejecting missing and contradictory settings
+// with ErrInvalidOptions, and an invalid batch size with ErrInvalidBatchSize.
+func NewItemList(config Config) (*ItemList, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if config.HashTag {
+		config.Prefix = HashTaggedPrefix(config.Prefix)
+	}
+	store := config.Store
+	if store == nil {
+		store = newSkipListElementStore(config.Prefix, config.Client)
+	}
+	return LoadItemList(config.Header, config.Prefix, config.Client, store, config.BatchSize, config.options()...)
+}
+
+func (config *Config) validate() error {
+	switch {
+	case config.Client == nil:
+		return fmt.Errorf("%w: no client", ErrInvalidOptions)
+	case config.Prefix == "":
+		return fmt.Errorf("%w: no prefix", ErrInvalidOptions)
+	case config.NamespaceStrict && !config.NamespaceCheck:
+		return fmt.Errorf("%w: NamespaceStrict needs NamespaceCheck", ErrInvalidOptions)
+	case config.ReadOnly && config.Mirror != nil:
+		return fmt.Errorf("%w: a read only list has no writes to mirror", ErrInvalidOptions)
+	case config.Mirror != nil && config.Mirror == config.Client:
+		return fmt.Errorf("%w: the mirror is the client", ErrInvalidOptions)
+	case config.MaxListingNodes < 0:
+		return fmt.Errorf("%w: negative MaxListingNodes %d", ErrInvalidOptions, config.MaxListingNodes)
+	case config.Maintenance != nil && config.ReadOnly && config.Maintenance.Compact:
+		return fmt.Errorf("%w: a read only list is not compacted", ErrInvalidOptions)
+	case config.SlowOperationThreshold < 0:
+		return fmt.Errorf("%w: negative SlowOperationThreshold %v", ErrInvalidOptions, config.SlowOperationThreshold)
+	}
+	return nil
+}
+
+func (config *Config) options() (opts []ItemListOption) {
+	if config.ReadOnly {
+		opts = append(opts, WithReadOnly())
+	}
+	if config.NamespaceCheck {
+		opts = append(opts, WithNamespaceCheck(config.NamespaceStrict))
+	}
+	if config.NamePrefix != "" {
+		opts = append(opts, WithNamePrefix(config.NamePrefix))
+	}
+	if config.MaintainedNodeCount {
+		opts = append(opts, WithMaintainedNodeCount())
+	}
+	if config.Checksum {
+		opts = append(opts, WithChecksum())
+	}
+	if config.NameScores {
+		opts = append(opts, WithNameScores())
+	}
+	if config.SafeMode {
+		opts = append(opts, WithSafeMode())
+	}
+	if config.MaxListingNodes > 0 {
+		opts = append(opts, WithMaxListingNodes(config.MaxListingNodes))
+	}
+	if config.CommandStats {
+		opts = append(opts, WithCommandStats())
+	}
+	if config.Tracer != nil {
+		opts = append(opts, WithTracer(config.Tracer))
+	}
+	if config.ClientState != nil {
+		opts = append(opts, WithClientState(config.ClientState))
+	}
+	if config.Clock != nil {
+		opts = append(opts, WithClock(config.Clock))
+	}
+	if config.SlowOperationThreshold > 0 {
+		opts = append(opts, WithSlowOperationLog(config.SlowOperationThreshold))
+	}
+	if config.Mirror != nil {
+		opts = append(opts, WithMirror(config.Mirror))
+	}
+	if config.DirectoryVersion {
+		opts = append(opts, WithDirectoryVersion())
+	}
+	if config.Maintenance != nil {
+		opts = append(opts, WithMaintenance(*config.Maintenance))
+	}
+	return append(opts, config.Options...)
+}
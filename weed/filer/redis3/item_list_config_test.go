@@ -0,0 +1,63 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewItemListConfig(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	mirror := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		mirror.Close()
+	})
+
+	for _, config := range []Config{
+		{Prefix: "/test/dir", BatchSize: 4},
+		{Client: client, BatchSize: 4},
+		{Client: client, Prefix: "/test/dir", BatchSize: 4, NamespaceStrict: true},
+		{Client: client, Prefix: "/test/dir", BatchSize: 4, ReadOnly: true, Mirror: mirror},
+		{Client: client, Prefix: "/test/dir", BatchSize: 4, Mirror: client},
+		{Client: client, Prefix: "/test/dir", BatchSize: 4, MaxListingNodes: -1},
+		{Client: client, Prefix: "/test/dir", BatchSize: 4, Maintenance: &MaintenanceConfig{Interval: time.Hour, Compact: true}},
+		{Client: client, Prefix: "/test/dir", BatchSize: 4, ReadOnly: true, Maintenance: &MaintenanceConfig{Interval: time.Hour, Compact: true, Lock: noLock}},
+	} {
+		_, err := NewItemList(config)
+		assert.ErrorIs(t, err, ErrInvalidOptions, "%+v", config)
+	}
+	_, err := NewItemList(Config{Client: client, Prefix: "/test/dir"})
+	assert.ErrorIs(t, err, ErrInvalidBatchSize)
+
+	maintained, err := NewItemList(Config{Client: client, Prefix: "/test/dir", BatchSize: 4, Maintenance: &MaintenanceConfig{Interval: time.Hour}})
+	assert.NoError(t, err)
+	assert.NotNil(t, maintained.maintenance)
+	assert.NoError(t, maintained.Close())
+
+	nl, err := NewItemList(Config{Client: client, Prefix: "/test/dir", BatchSize: 4, Checksum: true, NamePrefix: "p/"})
+	assert.NoError(t, err)
+	assert.True(t, nl.checksum)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	assert.NoError(t, client.Set(context.Background(), nl.prefix, nl.ToBytes(), 0).Err())
+
+	header, err := client.Get(context.Background(), "/test/dir").Bytes()
+	assert.NoError(t, err)
+	loaded, err := NewItemList(Config{Client: client, Prefix: "/test/dir", BatchSize: 4, Header: header, ReadOnly: true, NamePrefix: "p/"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, listAllNames(t, loaded, ""))
+	assert.ErrorIs(t, loaded.WriteName(context.Background(), "f"), ErrReadOnly)
+}
+
+func noLock() (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,25 @@
+package redis3
+
+import (
+	"context"
+
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+The operations of the list take the context of the caller, e.g. of a filer request, as their first parameter,
+and pass it down to every Redis command and skiplist store operation they make, so node walks stop at the next node
+once it is done. A NameIterator keeps the context given to Iterator for its Next calls.
+The original node helpers, NodeAddMember and the like, keep their signatures and use the background context,
+as do the commands made outside of an operation, e.g. by background maintenance.
+The context is passed as a parameter rather than kept on the list, so concurrent operations, prefetch goroutines
+and visitors calling back into the list each keep their own.
+*/
+
+// loadElement loads the skiplist element id, passing ctx to a skiplist.ContextListStore
+func (nl *ItemList) loadElement(ctx context.Context, id int64) (*skiplist.SkipListElement, error) {
+	if store, ok := nl.skipList.ListStore.(skiplist.ContextListStore); ok {
+		return store.LoadElementContext(ctx, id)
+	}
+	return nl.skipList.ListStore.LoadElement(id)
+}
@@ -0,0 +1,107 @@
+package redis3
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey struct{}
+
+// contextCommands counts the commands whose context does not hold contextKey
+type contextCommands struct {
+	without atomic.Int64
+}
+
+func (c *contextCommands) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (c *contextCommands) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if ctx.Value(contextKey{}) == nil {
+			c.without.Add(1)
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (c *contextCommands) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if ctx.Value(contextKey{}) == nil {
+			c.without.Add(int64(len(cmds)))
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func TestContext(t *testing.T) {
+	for _, opts := range [][]ItemListOption{
+		nil,
+		{WithListingPrefetch(2)},
+		{WithChecksum(), WithNameScores(), WithMaintainedNodeCount(), WithListingVersionCheck()},
+	} {
+		nl, _ := newTestItemList(t, 3, opts...)
+		commands := &contextCommands{}
+		nl.client.AddHook(commands)
+		ctx := context.WithValue(context.Background(), contextKey{}, true)
+
+		// every command of the operations, including the skiplist store, uses their context
+		for _, name := range []string{"e", "a", "i", "c", "g", "b", "h", "d", "f", "j"} {
+			assert.NoError(t, nl.WriteName(ctx, name))
+		}
+		assert.NoError(t, nl.DeleteName(ctx, "f"))
+		var listed []string
+		assert.NoError(t, nl.ListNames(ctx, "", func(name string) bool {
+			listed = append(listed, name)
+			return true
+		}))
+		assert.Equal(t, []string{"a", "b", "c", "d", "e", "g", "h", "i", "j"}, listed)
+		assert.Zero(t, commands.without.Load())
+		assert.Greater(t, countNodes(t, nl), 2)
+
+		// a listing stops at the next node once its context is done
+		cancelled, cancel := context.WithCancel(ctx)
+		listed = nil
+		err := nl.ListNames(cancelled, "", func(name string) bool {
+			listed = append(listed, name)
+			cancel()
+			return true
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, nl.NodeSize(nl.skipList.StartLevels[0]), len(listed))
+		assert.Equal(t, []string{"a", "b", "c", "d", "e", "g", "h", "i", "j"}[:len(listed)], listed)
+
+		// a done context fails the operation without changing the list
+		assert.ErrorIs(t, nl.WriteName(cancelled, "k"), context.Canceled)
+		assert.ErrorIs(t, nl.RemoteAllListElement(cancelled), context.Canceled)
+		assert.Equal(t, []string{"a", "b", "c", "d", "e", "g", "h", "i", "j"}, listAllNames(t, nl, ""))
+
+		// concurrent listings, and a visitor calling back into the list, each keep their own context
+		errs := make([]error, 2)
+		var listings sync.WaitGroup
+		for i, listCtx := range []context.Context{ctx, cancelled} {
+			listings.Add(1)
+			go func(i int, listCtx context.Context) {
+				defer listings.Done()
+				errs[i] = nl.ListNames(listCtx, "", func(string) bool { return true })
+			}(i, listCtx)
+		}
+		listings.Wait()
+		assert.NoError(t, errs[0])
+		assert.ErrorIs(t, errs[1], context.Canceled)
+		listed = nil
+		assert.NoError(t, nl.ListNames(ctx, "", func(name string) bool {
+			listed = append(listed, name)
+			_, err := nl.Count(cancelled)
+			assert.ErrorIs(t, err, context.Canceled)
+			return true
+		}))
+		assert.Equal(t, []string{"a", "b", "c", "d", "e", "g", "h", "i", "j"}, listed)
+		assertItemListConsistent(t, nl)
+	}
+}
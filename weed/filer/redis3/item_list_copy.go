@@ -0,0 +1,60 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+// CopyTo writes the names of this list for which keep returns true into dst, e.g. to migrate a directory
+// while dropping the names of a pattern, and returns how many names it copied and skipped.
+// The names are streamed in chunks of dst's batch size, each sorted in dst's order and written as Flush writes,
+// the names of each node at once, so memory holds one chunk and one source node. Names already in dst count as copied.
+// Persist the list header of dst afterwards, as after WriteName.
+func (nl *ItemList) CopyTo(ctx context.Context, dst *ItemList, keep func(name string) bool) (copied, skipped int, err error) {
+	if dst == nl {
+		return 0, 0, fmt.Errorf("copy %s: %w: the destination is the source", nl.prefix, ErrInvalidOptions)
+	}
+	if dst.readOnly {
+		return 0, 0, ErrReadOnly
+	}
+	if err := dst.ensureNamespace(ctx); err != nil {
+		return 0, 0, err
+	}
+	chunk := make([]string, 0, dst.batchSize)
+	flush := func() error {
+		sort.Strings(chunk)
+		if _, err := dst.writeSorted(ctx, chunk); err != nil {
+			return fmt.Errorf("copy %s to %s: %w", nl.prefix, dst.prefix, err)
+		}
+		copied += len(chunk)
+		chunk = chunk[:0]
+		return nil
+	}
+	err = nl.ListNamesWithError(ctx, "", func(name string) (bool, error) {
+		if !keep(name) {
+			skipped++
+			return true, nil
+		}
+		if err := dst.validateName(name); err != nil {
+			return false, err
+		}
+		if err := dst.checkOrderKey(name); err != nil {
+			return false, err
+		}
+		chunk = append(chunk, dst.storedName(name))
+		if len(chunk) < dst.batchSize {
+			return true, nil
+		}
+		return true, flush()
+	})
+	if err == nil && len(chunk) > 0 {
+		err = flush()
+	}
+	if copied+skipped > 0 {
+		glog.V(0).Infof("copy %s to %s: copied %d names, skipped %d", nl.prefix, dst.prefix, copied, skipped)
+	}
+	return copied, skipped, err
+}
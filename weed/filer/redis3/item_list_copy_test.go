@@ -0,0 +1,58 @@
+package redis3
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyTo(t *testing.T) {
+	src, server := newTestItemList(t, 3, WithOrderKey(Int64OrderKey))
+	var want []string
+	for i := 1; i <= 20; i++ {
+		name := strconv.Itoa(i)
+		assert.NoError(t, src.WriteName(context.Background(), name))
+		if i%3 != 0 {
+			want = append(want, name)
+		}
+	}
+	var tmp []string
+	for i := 1; i <= 20; i++ {
+		tmp = append(tmp, strconv.Itoa(i)+".tmp")
+		assert.NoError(t, src.WriteName(context.Background(), tmp[i-1]))
+	}
+	keep := func(name string) bool {
+		n, err := strconv.Atoi(name)
+		return err == nil && n%3 != 0
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	// a destination sorting names by their bytes, holding one of the names already
+	dst := mustNewItemList(t, client, "/test/copy", newSkipListElementStore("/test/copy", client), 4, WithChecksum())
+	assert.NoError(t, dst.WriteName(context.Background(), "2"))
+	copied, skipped, err := src.CopyTo(context.Background(), dst, keep)
+	assert.NoError(t, err)
+	assert.Equal(t, len(want), copied)
+	assert.Equal(t, 20-len(want)+len(tmp), skipped)
+	sort.Strings(want)
+	assert.Equal(t, want, listAllNames(t, dst, ""))
+	assertItemListConsistent(t, dst)
+	ok, err := dst.VerifyChecksum(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	// the source is unchanged
+	assert.Len(t, listAllNames(t, src, ""), 40)
+
+	_, _, err = src.CopyTo(context.Background(), src, keep)
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+	readOnly := mustNewItemList(t, client, "/test/copy", dst.skipList.ListStore, 4, WithReadOnly())
+	_, _, err = src.CopyTo(context.Background(), readOnly, keep)
+	assert.ErrorIs(t, err, ErrReadOnly)
+}
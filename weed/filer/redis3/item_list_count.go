@@ -0,0 +1,134 @@
+package redis3
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+Count counts the names of each node within its key range, from its key up to the key of the next node,
+the first node from the smallest name on. A split moving the upper names of a full node links the new node
+at the split name before removing them from the full node, so for a moment they are members of both;
+bounded by the key of the new node, the full node does not count them again, and when the walk passed
+the full node before the new node was linked, they are only counted there. A split moving the lower names
+unlinks the full node first. With concurrent writers the count is eventually consistent: a name written or deleted
+during the walk may or may not be counted, and the names of a node being relinked may be missed, but none is counted twice.
+The counts of WithMaintainedNodeCount are not used, as they include the names of a node beyond its range.
+*/
+
+// countPipelineNodes bounds the nodes counted by one pipeline of Count
+const countPipelineNodes = 1000
+
+// nodeRange is the member set of a node and the lex range of the names counted there
+type nodeRange struct {
+	key, min, max string
+}
+
+// Count returns the number of names, without listing them. The nodes are walked in the skiplist, and counted
+// in pipelines of up to countPipelineNodes nodes, or one after the other with WithNodeShards or WithLexFallback.
+// Once ctx is done, it stops with its error before the next node.
+func (nl *ItemList) Count(ctx context.Context) (int64, error) {
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return 0, err
+	}
+	lo, hi := nl.namePrefix, ""
+	if nl.namePrefix != "" {
+		hi, _ = prefixEnd(nl.namePrefix)
+	}
+
+	var total int64
+	var ranges []nodeRange
+	// count adds the range [from, to) of node id, the empty from and to meaning unbounded
+	count := func(id int64, from, to string) error {
+		from, to = max(from, lo), boundedMin(to, hi)
+		if to != "" && from >= to {
+			return nil
+		}
+		r := nodeRange{key: nl.nodeKey(id), min: "-", max: "+"}
+		if from != "" {
+			r.min = "[" + from
+		}
+		if to != "" {
+			r.max = "(" + to
+		}
+		ranges = append(ranges, r)
+		if len(ranges) < countPipelineNodes {
+			return nil
+		}
+		counted, err := nl.countRanges(ctx, ranges)
+		total += counted
+		ranges = ranges[:0]
+		return err
+	}
+
+	var prev *skiplist.SkipListElement
+	from := ""
+	err := nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		if prev != nil {
+			if err := count(prev.Id, from, string(node.Key)); err != nil {
+				return false, err
+			}
+			from = string(node.Key)
+		}
+		prev = node
+		return true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if prev != nil {
+		if err := count(prev.Id, from, ""); err != nil {
+			return 0, err
+		}
+	}
+	counted, err := nl.countRanges(ctx, ranges)
+	if err != nil {
+		return 0, err
+	}
+	return total + counted, nil
+}
+
+// countRanges sums the names of the node ranges, in one pipeline unless sharded nodes or the lex fallback need more
+func (nl *ItemList) countRanges(ctx context.Context, ranges []nodeRange) (int64, error) {
+	if len(ranges) == 0 {
+		return 0, nil
+	}
+	var total int64
+	if nl.nodeShardLength > 0 || nl.lexFallback(ctx) {
+		for _, r := range ranges {
+			counted, err := nl.zLexCount(ctx, r.key, r.min, r.max).Result()
+			if err != nil {
+				return 0, wrapNodeKeyError(r.key, err)
+			}
+			total += counted
+		}
+		return total, nil
+	}
+	pipe := nl.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(ranges))
+	for i, r := range ranges {
+		cmds[i] = pipe.ZLexCount(ctx, r.key, r.min, r.max)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		for i, cmd := range cmds {
+			if cmd.Err() != nil {
+				return 0, wrapNodeKeyError(ranges[i].key, cmd.Err())
+			}
+		}
+		return 0, wrapRedisError(err)
+	}
+	for _, cmd := range cmds {
+		total += cmd.Val()
+	}
+	return total, nil
+}
+
+// boundedMin is the smaller of two upper bounds, the empty bound meaning unbounded
+func boundedMin(a, b string) string {
+	if a == "" || b != "" && b < a {
+		return b
+	}
+	return a
+}
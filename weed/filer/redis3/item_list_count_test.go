@@ -0,0 +1,78 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCount(t *testing.T) {
+	ctx := context.Background()
+	for _, opts := range [][]ItemListOption{
+		nil,
+		{WithNodeShards(1)},
+		{WithLexFallback(), WithMaintainedNodeCount()},
+	} {
+		nl, _ := newTestItemList(t, 3, append(opts, WithCommandStats())...)
+		count, err := nl.Count(ctx)
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, nl.WriteName(ctx, fmt.Sprintf("name%02d", (i*7)%20)))
+		}
+		for _, name := range []string{"name00", "name07", "name13"} {
+			assert.NoError(t, nl.DeleteName(ctx, name))
+		}
+		count, err = nl.Count(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(listAllNames(t, nl, ""))), count)
+		if opts == nil {
+			// the nodes are read one by one, and counted in one pipeline
+			nodes := countNodes(t, nl)
+			before := nl.Stats().RoundTrips
+			_, err = nl.Count(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(nodes+1), nl.Stats().RoundTrips-before)
+		}
+
+		// a split in progress: the upper names of the first node are in a new node, and still in the first node
+		first := nl.skipList.StartLevels[0]
+		names := mustNodeNames(t, nl, first)
+		assert.GreaterOrEqual(t, len(names), 2)
+		_, err = nl.itemAdd(context.Background(), []byte(names[1]), 0, names[1:]...)
+		assert.NoError(t, err)
+		var sizes int64
+		for ref := nl.skipList.StartLevels[0]; ref != nil; {
+			sizes += int64(nl.NodeSize(ref))
+			node, err := nl.skipList.LoadElement(ref)
+			assert.NoError(t, err)
+			ref = node.Next[0]
+		}
+		assert.Greater(t, sizes, count)
+		again, err := nl.Count(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, count, again)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = nl.Count(cancelled)
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+
+	// only the names of the prefix count
+	raw, _ := newTestItemList(t, 3)
+	dirA := mustNewItemList(t, raw.client, raw.prefix, raw.skipList.ListStore, 3, WithNamePrefix("a/"))
+	dirA.skipList = raw.skipList
+	for _, name := range []string{"0", "a/w", "a/x", "a/y", "a/z", "b/w", "b/x", "c"} {
+		assert.NoError(t, raw.WriteName(ctx, name))
+	}
+	count, err := dirA.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+	count, err = raw.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), count)
+}
@@ -0,0 +1,59 @@
+package redis3
+
+import "time"
+
+// recentNames remembers recently written names for a short window,
+// bounded to maxSize names, evicting the oldest first.
+type recentNames struct {
+	window  time.Duration
+	maxSize int
+	names   map[string]time.Time
+	queue   []recentName
+}
+
+type recentName struct {
+	name string
+	at   time.Time
+}
+
+func newRecentNames(window time.Duration, maxSize int) *recentNames {
+	return &recentNames{
+		window:  window,
+		maxSize: maxSize,
+		names:   make(map[string]time.Time),
+	}
+}
+
+func (r *recentNames) contains(name string, now time.Time) bool {
+	at, found := r.names[name]
+	return found && now.Sub(at) < r.window
+}
+
+func (r *recentNames) add(name string, now time.Time) {
+	r.names[name] = now
+	r.queue = append(r.queue, recentName{name: name, at: now})
+	r.evict(now)
+}
+
+func (r *recentNames) remove(name string) {
+	delete(r.names, name)
+}
+
+func (r *recentNames) clear() {
+	r.names = make(map[string]time.Time)
+	r.queue = nil
+}
+
+func (r *recentNames) evict(now time.Time) {
+	for len(r.queue) > 0 {
+		oldest := r.queue[0]
+		if now.Sub(oldest.at) < r.window && len(r.names) <= r.maxSize {
+			break
+		}
+		r.queue = r.queue[1:]
+		// the name may have been written again, or deleted, since
+		if at, found := r.names[oldest.name]; found && at.Equal(oldest.at) {
+			delete(r.names, oldest.name)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemListWriteDeduplication(t *testing.T) {
+	nl, server := newTestItemList(t, 3, WithWriteDeduplication(time.Minute, 2))
+
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	commands := server.CommandCount()
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	assert.Equal(t, commands, server.CommandCount())
+
+	// a delete is not masked
+	assert.NoError(t, nl.DeleteName(context.Background(), "a"))
+	assert.Empty(t, listAllNames(t, nl, ""))
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	assert.Equal(t, []string{"a"}, listAllNames(t, nl, ""))
+
+	// bounded size evicts the oldest names
+	assert.NoError(t, nl.WriteName(context.Background(), "b"))
+	assert.NoError(t, nl.WriteName(context.Background(), "c"))
+	commands = server.CommandCount()
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	assert.Less(t, commands, server.CommandCount())
+	commands = server.CommandCount()
+	assert.NoError(t, nl.WriteName(context.Background(), "c"))
+	assert.Equal(t, commands, server.CommandCount())
+}
+
+func TestRecentNamesWindow(t *testing.T) {
+	r := newRecentNames(time.Second, 10)
+	now := time.Now()
+	r.add("a", now)
+	assert.True(t, r.contains("a", now.Add(500*time.Millisecond)))
+	assert.False(t, r.contains("a", now.Add(time.Second)))
+	r.add("b", now.Add(2*time.Second))
+	assert.Equal(t, 1, len(r.names))
+}
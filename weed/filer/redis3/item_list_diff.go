@@ -0,0 +1,28 @@
+package redis3
+
+import "context"
+
+// DiffLists walks a and b together in name order, returning the names only in a and only in b,
+// e.g. to verify a copied directory before deleting the source. Only one node of each list is held at a time.
+// With limit > 0, it stops once limit differences are found, so the result is partial if it returns limit of them.
+// With WithOrderKey, both lists must use the same order key function.
+func DiffLists(ctx context.Context, a, b *ItemList, limit int) (onlyInA []string, onlyInB []string, err error) {
+	itA, itB := a.Iterator(ctx, ""), b.Iterator(ctx, "")
+	hasA, hasB := itA.Next(), itB.Next()
+	for (hasA || hasB) && (limit <= 0 || len(onlyInA)+len(onlyInB) < limit) {
+		switch {
+		case !hasB || hasA && a.sortKey(itA.stored) < b.sortKey(itB.stored):
+			onlyInA = append(onlyInA, itA.Value())
+			hasA = itA.Next()
+		case !hasA || b.sortKey(itB.stored) < a.sortKey(itA.stored):
+			onlyInB = append(onlyInB, itB.Value())
+			hasB = itB.Next()
+		default:
+			hasA, hasB = itA.Next(), itB.Next()
+		}
+	}
+	if err := itA.Err(); err != nil {
+		return onlyInA, onlyInB, err
+	}
+	return onlyInA, onlyInB, itB.Err()
+}
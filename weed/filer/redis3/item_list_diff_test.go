@@ -0,0 +1,43 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLists(t *testing.T) {
+	a, _ := newTestItemList(t, 3)
+	b, _ := newTestItemList(t, 2)
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("name%02d", i)
+		if i%7 != 3 {
+			assert.NoError(t, a.WriteName(context.Background(), name))
+		}
+		if i%5 != 1 {
+			assert.NoError(t, b.WriteName(context.Background(), name))
+		}
+	}
+	onlyInA, onlyInB, err := DiffLists(context.Background(), a, b, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name01", "name06", "name11", "name16"}, onlyInA)
+	assert.Equal(t, []string{"name03", "name10", "name17"}, onlyInB)
+
+	onlyInA, onlyInB, err = DiffLists(context.Background(), a, b, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name01", "name06"}, onlyInA)
+	assert.Equal(t, []string{"name03"}, onlyInB)
+
+	onlyInA, onlyInB, err = DiffLists(context.Background(), a, a, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, onlyInA)
+	assert.Empty(t, onlyInB)
+
+	empty, _ := newTestItemList(t, 3)
+	onlyInA, onlyInB, err = DiffLists(context.Background(), empty, b, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, onlyInA)
+	assert.Len(t, onlyInB, 16)
+}
@@ -0,0 +1,68 @@
+package redis3
+
+/*
+A full node split at the new name leaves two partially filled nodes. By default, the name goes
+to the smaller half, so both halves have room for later names around the split point.
+
+With a sorted insertion stream, only the side the stream moves to receives later names:
+  InsertAscending puts the name at the end of the lower half, which fills up, while the upper half never grows.
+  InsertDescending puts the name at the start of the upper half, and the lower half fills up.
+  It also prefers the next node over the previous one while the next node has room.
+Either way, nodes behind the stream end up full instead of half full. A wrong hint does not
+lose names, but leaves nodes as half filled as random insertions do.
+Names appended beyond the last node, or before the first one, already fill nodes completely.
+*/
+
+type InsertDirection int
+
+const (
+	// InsertBalanced splits full nodes around the middle, the default
+	InsertBalanced InsertDirection = iota
+	InsertAscending
+	InsertDescending
+	// InsertAuto follows the direction of the recent WriteName calls of this ItemList
+	InsertAuto
+)
+
+// insertTrendThreshold is how many more ascending than descending inserts, or the reverse, make a trend
+const insertTrendThreshold = 4
+
+// observeInsert tracks the insertion direction for InsertAuto
+func (nl *ItemList) observeInsert(name string) {
+	if nl.insertDirection != InsertAuto {
+		return
+	}
+	if nl.lastInsert != "" {
+		if name > nl.lastInsert && nl.insertTrend < 2*insertTrendThreshold {
+			nl.insertTrend++
+		} else if name < nl.lastInsert && nl.insertTrend > -2*insertTrendThreshold {
+			nl.insertTrend--
+		}
+	}
+	nl.lastInsert = name
+}
+
+func (nl *ItemList) effectiveInsertDirection() InsertDirection {
+	if nl.insertDirection != InsertAuto {
+		return nl.insertDirection
+	}
+	switch {
+	case nl.insertTrend >= insertTrendThreshold:
+		return InsertAscending
+	case nl.insertTrend <= -insertTrendThreshold:
+		return InsertDescending
+	}
+	return InsertBalanced
+}
+
+// splitToLowerHalf tells whether a full node split at a name with x names before it and y after it
+// puts the name into the lower half
+func (nl *ItemList) splitToLowerHalf(x, y int) bool {
+	switch nl.effectiveInsertDirection() {
+	case InsertAscending:
+		return true
+	case InsertDescending:
+		return false
+	}
+	return x <= y
+}
@@ -0,0 +1,37 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertDirection(t *testing.T) {
+	insert := func(direction InsertDirection, descending bool) int {
+		nl, _ := newTestItemList(t, 4, WithInsertDirection(direction))
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("k%02d", i)))
+		}
+		// a sorted stream into the middle of a full node
+		for i := 0; i < 24; i++ {
+			j := i
+			if descending {
+				j = 23 - i
+			}
+			assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("k02-%02d", j)))
+		}
+		assertItemListConsistent(t, nl)
+		assert.Len(t, listAllNames(t, nl, ""), 44)
+		return countNodes(t, nl)
+	}
+
+	balanced := insert(InsertBalanced, false)
+	assert.Less(t, insert(InsertAscending, false), balanced)
+	assert.Less(t, insert(InsertAuto, false), balanced)
+
+	balanced = insert(InsertBalanced, true)
+	assert.Less(t, insert(InsertDescending, true), balanced)
+	assert.Less(t, insert(InsertAuto, true), balanced)
+}
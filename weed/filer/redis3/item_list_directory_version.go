@@ -0,0 +1,76 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/*
+With WithDirectoryVersion, "<prefix>modified" holds the directory version, bumped by each write adding a name,
+each removed name, each UpsertName and ReplaceAll, so a cached listing is stale once the version changed.
+A bump sets the version to the current time in microseconds, or one more than the previous version if that is
+not before it, in one script, so the version never goes back and is also, up to clock skew, the time of the last change.
+RemoteAllListElement bumps it too, and lets it expire after truncatedVersionTTL, so a deleted directory leaves
+no key behind while the versions after a truncation still never repeat one seen before it.
+Splits, merges and repairs move names without changing the listing, and do not bump it.
+*/
+
+const (
+	directoryVersionSuffix = "modified"
+	truncatedVersionTTL    = 24 * time.Hour
+)
+
+// bumpDirectoryVersionScript sets KEYS[1] to ARGV[1], the time in microseconds, or to one more than its value
+// if that is not before it, expiring after ARGV[2] milliseconds unless 0. Microseconds fit the 53 bits of a Lua number.
+var bumpDirectoryVersionScript = redis.NewScript(`
+local version = tonumber(ARGV[1])
+local previous = tonumber(redis.call('GET', KEYS[1]) or '0')
+if version <= previous then
+	version = previous + 1
+end
+if tonumber(ARGV[2]) > 0 then
+	redis.call('SET', KEYS[1], version, 'PX', ARGV[2])
+else
+	redis.call('SET', KEYS[1], version)
+end
+return version
+`)
+
+func (nl *ItemList) directoryVersionKey() string {
+	return nl.prefix + directoryVersionSuffix
+}
+
+// Version returns the directory version kept by WithDirectoryVersion, 0 for a directory never changed,
+// or truncated over truncatedVersionTTL ago. It is one GET, no listing.
+func (nl *ItemList) Version(ctx context.Context) (uint64, error) {
+	if !nl.directoryVersion {
+		return 0, fmt.Errorf("version of %s: %w: see WithDirectoryVersion", nl.prefix, ErrInvalidOptions)
+	}
+	version, err := nl.client.Get(ctx, nl.directoryVersionKey()).Uint64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("version of %s: %w", nl.prefix, wrapRedisError(err))
+	}
+	return version, nil
+}
+
+func (nl *ItemList) bumpDirectoryVersion(ctx context.Context) error {
+	return nl.bumpDirectoryVersionExpiring(ctx, 0)
+}
+
+func (nl *ItemList) bumpDirectoryVersionExpiring(ctx context.Context, ttl time.Duration) error {
+	if !nl.directoryVersion {
+		return nil
+	}
+	now := nl.clock.Now().UnixMicro()
+	err := bumpDirectoryVersionScript.Run(ctx, nl.client, []string{nl.directoryVersionKey()}, now, ttl.Milliseconds()).Err()
+	if err != nil {
+		return fmt.Errorf("bump version of %s: %w", nl.prefix, wrapRedisError(err))
+	}
+	return nil
+}
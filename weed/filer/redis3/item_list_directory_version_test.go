@@ -0,0 +1,53 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectoryVersion(t *testing.T) {
+	clock := &fakeClock{now: time.UnixMicro(1000)}
+	nl, server := newTestItemList(t, 3, WithDirectoryVersion(), WithNameScores(), WithClock(clock))
+	version := func() uint64 {
+		v, err := nl.Version(context.Background())
+		assert.NoError(t, err)
+		return v
+	}
+	assert.Equal(t, uint64(0), version())
+
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	assert.Equal(t, uint64(1000), version())
+	// the clock did not move, the version still does
+	assert.NoError(t, nl.WriteName(context.Background(), "b"))
+	assert.Equal(t, uint64(1001), version())
+	bumps := &countCommands{name: "evalsha"}
+	nl.client.AddHook(bumps)
+	assert.NoError(t, nl.WriteName(context.Background(), "b"))
+	assert.NoError(t, nl.DeleteName(context.Background(), "missing"))
+	assert.Equal(t, uint64(1001), version())
+	assert.Equal(t, int64(0), bumps.count.Load())
+
+	clock.now = time.UnixMicro(5000)
+	assert.NoError(t, nl.DeleteName(context.Background(), "a"))
+	assert.Equal(t, uint64(5000), version())
+	assert.NoError(t, nl.UpsertName(context.Background(), "b", 1))
+	assert.Equal(t, uint64(5001), version())
+	assert.Equal(t, int64(2), bumps.count.Load())
+
+	assert.NoError(t, nl.RemoteAllListElement(context.Background()))
+	assert.Equal(t, uint64(5002), version())
+	assert.NoError(t, nl.AssertEmpty(context.Background()))
+	assert.Greater(t, server.TTL(nl.directoryVersionKey()), time.Duration(0))
+	// the list written after the truncation
+	nl = mustNewItemList(t, nl.client, nl.prefix, nl.skipList.ListStore, 3, WithDirectoryVersion(), WithClock(clock))
+	assert.NoError(t, nl.WriteName(context.Background(), "c"))
+	assert.Equal(t, uint64(5003), version())
+	assert.Equal(t, time.Duration(0), server.TTL(nl.directoryVersionKey()))
+
+	plain, _ := newTestItemList(t, 3)
+	_, err := plain.Version(context.Background())
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
@@ -0,0 +1,83 @@
+package redis3
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// DumpDOT is a diagnostic tool writing the nodes as a Graphviz DOT graph, e.g. for `dot -Tsvg`,
+// each node labeled with its id, key and size and linked to its next node, with the higher skiplist levels dashed.
+// Nodes over batchSize are red, empty ones gray, and those whose key is not their smallest name orange.
+// It only reads, one element and two commands per node, so keep it for debugging rather than large lists.
+func (nl *ItemList) DumpDOT(ctx context.Context, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph %s {\n\trankdir=LR;\n\tnode [shape=record];\n", strconv.Quote(nl.prefix))
+	fmt.Fprintf(bw, "\tstart [label=%s];\n", dotRecord("start", fmt.Sprintf("max level %d", nl.skipList.MaxLevel)))
+	for level, ref := range nl.skipList.StartLevels {
+		if ref != nil {
+			writeDOTEdge(bw, "start", ref.ElementPointer, level)
+		}
+	}
+
+	err := nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		size, _ := nl.nodeSize(ctx, node.Reference())
+		minName, _ := nl.nodeMin(ctx, node.Reference())
+		color := "black"
+		switch {
+		case size > nl.batchSize:
+			color = "red"
+		case size == 0:
+			color = "gray"
+		case minName != string(node.Key):
+			color = "orange"
+		}
+		fields := []string{strconv.FormatInt(node.Id, 10), "key " + strconv.Quote(string(node.Key)), "size " + strconv.Itoa(size)}
+		if color == "orange" {
+			fields = append(fields, "min "+strconv.Quote(minName))
+		}
+		fmt.Fprintf(bw, "\tn%d [label=%s, color=%s];\n", node.Id, dotRecord(fields...), color)
+		for level, next := range node.Next {
+			if next != nil {
+				writeDOTEdge(bw, fmt.Sprintf("n%d", node.Id), next.ElementPointer, level)
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	bw.WriteString("}\n")
+	return bw.Flush()
+}
+
+func writeDOTEdge(w io.Writer, from string, to int64, level int) {
+	if level == 0 {
+		fmt.Fprintf(w, "\t%s -> n%d;\n", from, to)
+		return
+	}
+	fmt.Fprintf(w, "\t%s -> n%d [style=dashed, label=\"L%d\"];\n", from, to, level)
+}
+
+// dotRecord is the quoted label of a record of fields, escaping the characters with a meaning in records
+func dotRecord(fields ...string) string {
+	escaped := []byte{'"'}
+	for i, field := range fields {
+		if i > 0 {
+			escaped = append(escaped, '|')
+		}
+		for j := 0; j < len(field); j++ {
+			switch c := field[j]; c {
+			case '\\', '"', '{', '}', '<', '>', '|':
+				escaped = append(escaped, '\\', c)
+			default:
+				escaped = append(escaped, c)
+			}
+		}
+	}
+	return string(append(escaped, '"'))
+}
@@ -0,0 +1,32 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpDOT(t *testing.T) {
+	nl, server := newTestItemList(t, 3)
+	for _, name := range []string{"a|b", "c", "d", "e", "f", "g"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	first := nl.skipList.StartLevels[0]
+	assert.NoError(t, nl.NodeAddMember(first, "a1", "a2", "a3"))
+	before := server.Dump()
+
+	var dot strings.Builder
+	assert.NoError(t, nl.DumpDOT(context.Background(), &dot))
+	assert.Equal(t, before, server.Dump())
+	out := dot.String()
+	assert.True(t, strings.HasPrefix(out, `digraph "/test/dir" {`), out)
+	assert.True(t, strings.HasSuffix(out, "}\n"), out)
+	nodes := countNodes(t, nl)
+	assert.Equal(t, nodes, strings.Count(out, "[label=")-1)
+	assert.Equal(t, nodes, strings.Count(out, ";\n")-strings.Count(out, "[label=")-strings.Count(out, "dashed")-2)
+	assert.Contains(t, out, fmt.Sprintf(`n%d [label="%d|key \"a\|b\"|size %d", color=red];`,
+		first.ElementPointer, first.ElementPointer, len(mustNodeNames(t, nl, first))))
+}
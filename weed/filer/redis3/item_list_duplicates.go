@@ -0,0 +1,102 @@
+package redis3
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+A name belongs to the node with the largest key not after it, where WriteName and DeleteName route it.
+A member of another node, outside the range from its node key to the next node key, is misplaced,
+e.g. left by a split which added names to the new node but failed to remove them from the old one.
+Listings then visit the name twice when it is also in the node it belongs to.
+*/
+
+// FindDuplicates returns the names stored in more than one node, as stored, including any WithNamePrefix.
+// It walks the nodes in order and only reads the members outside the range of each node,
+// so it holds one node's misplaced names at a time, whatever the size of the list.
+func (nl *ItemList) FindDuplicates(ctx context.Context) (duplicates []string, err error) {
+	err = nl.walkDuplicates(ctx, func(node *skiplist.SkipListElementReference, name string) error {
+		duplicates = append(duplicates, name)
+		return nil
+	})
+	return duplicates, err
+}
+
+// RemoveDuplicates removes each name found by FindDuplicates from the nodes it does not belong to,
+// keeping the copy in the node WriteName routes it to. It returns the number of removed copies.
+func (nl *ItemList) RemoveDuplicates(ctx context.Context) (removed int, err error) {
+	if nl.readOnly {
+		return 0, ErrReadOnly
+	}
+	err = nl.walkDuplicates(ctx, func(node *skiplist.SkipListElementReference, name string) error {
+		if err := nl.nodeDeleteMember(ctx, node, name); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+	if removed > 0 {
+		glog.V(0).Infof("duplicates %s: removed %d misplaced copies", nl.prefix, removed)
+	}
+	return removed, err
+}
+
+// walkDuplicates calls fn with each misplaced name of a node which is also in the node it belongs to
+func (nl *ItemList) walkDuplicates(ctx context.Context, fn func(node *skiplist.SkipListElementReference, name string) error) error {
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+	return nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		var nextKey []byte
+		if node.Next[0] != nil {
+			nextKey = node.Next[0].Key
+		}
+		misplaced, err := nl.misplacedNames(ctx, node.Reference(), nextKey)
+		if err != nil {
+			return false, err
+		}
+		for _, name := range misplaced {
+			owner, err := nl.nodeOf(ctx, name)
+			if err != nil {
+				return false, err
+			}
+			if owner == nil || owner.ElementPointer == node.Id {
+				continue
+			}
+			ownerKey := nl.nodeKey(owner.ElementPointer)
+			err = nl.client.ZScore(ctx, nl.memberKey(ownerKey, name), name).Err()
+			if err == redis.Nil {
+				// only misplaced, listings still visit it once
+				continue
+			}
+			if err != nil {
+				return false, wrapNodeKeyError(ownerKey, err)
+			}
+			if err := fn(node.Reference(), name); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// misplacedNames returns the members of node before its key, or from nextKey on
+func (nl *ItemList) misplacedNames(ctx context.Context, node *skiplist.SkipListElementReference, nextKey []byte) ([]string, error) {
+	key := nl.nodeKey(node.ElementPointer)
+	before, err := nl.zRangeByLex(ctx, key, &redis.ZRangeBy{Min: "-", Max: "(" + string(node.Key)}).Result()
+	if err != nil {
+		return nil, wrapNodeKeyError(key, err)
+	}
+	if nextKey == nil {
+		return before, nil
+	}
+	after, err := nl.zRangeByLex(ctx, key, &redis.ZRangeBy{Min: "[" + string(nextKey), Max: "+"}).Result()
+	if err != nil {
+		return nil, wrapNodeKeyError(key, err)
+	}
+	return append(before, after...), nil
+}
@@ -0,0 +1,46 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	nl, _ := newTestItemList(t, 3, WithMaintainedNodeCount())
+	var expected []string
+	for i := 0; i < 9; i++ {
+		name := fmt.Sprintf("name%02d", i)
+		expected = append(expected, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	duplicates, err := nl.FindDuplicates(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, duplicates)
+
+	// a copy of a name of the last node left in the first node, and a name only in the wrong node
+	first := nl.skipList.StartLevels[0]
+	last := nl.skipList.GetLargestNodeReference()
+	assert.NotEqual(t, first.ElementPointer, last.ElementPointer)
+	duplicate := mustNodeNames(t, nl, last)[0]
+	assert.NoError(t, nl.NodeAddMember(first, duplicate, "zzz"))
+	assert.Len(t, listAllNames(t, nl, ""), len(expected)+2)
+
+	duplicates, err = nl.FindDuplicates(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{duplicate}, duplicates)
+
+	removed, err := nl.RemoveDuplicates(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Contains(t, mustNodeNames(t, nl, last), duplicate)
+	assert.NotContains(t, mustNodeNames(t, nl, first), duplicate)
+	duplicates, err = nl.FindDuplicates(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, duplicates)
+	report, err := nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, report.NodeCountFixed)
+}
@@ -0,0 +1,64 @@
+package redis3
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+)
+
+// the types of failed commands counted by WithCommandStats, telling apart network trouble,
+// cluster resharding and type conflicts
+var redisErrorTypes = [...]string{"timeout", "connection_refused", "moved", "wrong_type", "noscript", "other"}
+
+// redisErrorType is the index in redisErrorTypes of a command error, -1 for no error or redis.Nil
+func redisErrorType(err error) int {
+	if err == nil || err == redis.Nil {
+		return -1
+	}
+	var netErr net.Error
+	msg := err.Error()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout():
+		return 0
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return 1
+	case strings.HasPrefix(msg, "MOVED "), strings.HasPrefix(msg, "ASK "):
+		return 2
+	case strings.HasPrefix(msg, "WRONGTYPE"):
+		return 3
+	case strings.HasPrefix(msg, "NOSCRIPT"):
+		return 4
+	}
+	return 5
+}
+
+// recordErrors counts the failed commands by type, and in the filer store request metric as "redis_error_<type>"
+func (s *commandStats) recordErrors(cmds ...redis.Cmder) {
+	for _, cmd := range cmds {
+		if i := redisErrorType(cmd.Err()); i >= 0 {
+			s.errors[i].Add(1)
+			stats.FilerStoreCounter.WithLabelValues("redis3", "redis_error_"+redisErrorTypes[i]).Inc()
+		}
+	}
+}
+
+// redisErrors returns the non zero counts of failed commands by type
+func (s *commandStats) redisErrors() map[string]int64 {
+	var counts map[string]int64
+	for i, name := range redisErrorTypes {
+		if n := s.errors[i].Load(); n > 0 {
+			if counts == nil {
+				counts = make(map[string]int64)
+			}
+			counts[name] = n
+		}
+	}
+	return counts
+}
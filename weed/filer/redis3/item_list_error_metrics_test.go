@@ -0,0 +1,47 @@
+package redis3
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisErrorTypes(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected string
+	}{
+		{context.DeadlineExceeded, "timeout"},
+		{&net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, "timeout"},
+		{&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, "connection_refused"},
+		{errors.New("MOVED 3999 127.0.0.1:6381"), "moved"},
+		{errors.New("ASK 3999 127.0.0.1:6381"), "moved"},
+		{errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), "wrong_type"},
+		{errors.New("NOSCRIPT No matching script"), "noscript"},
+		{errors.New("ERR unknown command"), "other"},
+	} {
+		assert.Equal(t, tc.expected, redisErrorTypes[redisErrorType(tc.err)], tc.err.Error())
+	}
+	assert.Equal(t, -1, redisErrorType(nil))
+	assert.Equal(t, -1, redisErrorType(redis.Nil))
+
+	wrongType := stats.FilerStoreCounter.WithLabelValues("redis3", "redis_error_wrong_type")
+	wrongTypeBefore := testutil.ToFloat64(wrongType)
+	nl, server := newTestItemList(t, 3, WithCommandStats())
+	assert.Nil(t, nl.Stats().RedisErrors)
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	nodeKey := nl.nodeKey(nl.skipList.StartLevels[0].ElementPointer)
+	server.Del(nodeKey)
+	assert.NoError(t, server.Set(nodeKey, "not a sorted set"))
+	assert.Error(t, nl.WriteName(context.Background(), "b"))
+	assert.NotZero(t, nl.Stats().RedisErrors["wrong_type"])
+	assert.Equal(t, wrongTypeBefore+float64(nl.Stats().RedisErrors["wrong_type"]), testutil.ToFloat64(wrongType))
+}
@@ -0,0 +1,173 @@
+package redis3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// errors.Is compatible classes of the underlying Redis errors, see wrapRedisError
+var (
+	ErrNotFound         = errors.New("redis3: not found")
+	ErrRedisUnavailable = errors.New("redis3: redis is unavailable")
+	ErrConflict         = errors.New("redis3: conflict, e.g. cluster resharding or transaction failure")
+	ErrWrongType        = errors.New("redis3: key holds the wrong kind of value")
+)
+
+var (
+	ErrEmptyName = errors.New("redis3: empty name can not be stored")
+	// ErrInvalidName means a name can not be stored, see ValidateName.
+	ErrInvalidName = errors.New("redis3: invalid name")
+	ErrUnsupported = errors.New("redis3: command not supported by the redis server")
+	// ErrNamespaceConflict means the key prefix is shared with something else, e.g. another deployment.
+	ErrNamespaceConflict = errors.New("redis3: key prefix is not owned by this list")
+	ErrReadOnly          = errors.New("redis3: list is read only")
+	ErrSkiplistNotEmpty  = errors.New("redis3: skiplist is not empty")
+	ErrListingTooLarge   = errors.New("redis3: listing scanned too many nodes")
+	ErrNoNameScores      = errors.New("redis3: name scores are not enabled, see WithNameScores")
+	ErrKeysLeft          = errors.New("redis3: keys are left under the list prefix")
+	ErrInvalidBatchSize  = errors.New("redis3: invalid batch size")
+	ErrInvalidOptions    = errors.New("redis3: contradictory item list options")
+	ErrInvalidOrderKey   = errors.New("redis3: order key contains a NUL byte")
+	ErrNameCollision     = errors.New("redis3: name differs from a stored name only in case")
+	// ErrInconsistentSkiplist means the skiplist header contradicts itself, e.g. a first node without a last one.
+	ErrInconsistentSkiplist = errors.New("redis3: inconsistent skiplist")
+	// ErrConcurrentModification means the list was split or merged during a listing, which can be retried.
+	ErrConcurrentModification = errors.New("redis3: list changed during the listing")
+	ErrKeyTypeConflict        = errors.New("redis3: a node key holds another kind of value")
+	// ErrOverCapacity means WithSafeMode refused to split a node already over batchSize.
+	ErrOverCapacity = errors.New("redis3: node is over capacity, run SplitOversizedNodes")
+	// ErrInvalidCursor means a ScanCursor cursor names a node which is no longer in the list.
+	ErrInvalidCursor = errors.New("redis3: invalid scan cursor")
+	// ErrPossibleCycle means a walk over the nodes exceeded its bound, likely a corrupted skiplist store.
+	ErrPossibleCycle = errors.New("redis3: too many nodes, the skiplist may contain a cycle")
+	// ErrNodeIdInUse means a node id was reused for a key while it still holds another node.
+	ErrNodeIdInUse = errors.New("redis3: node id is in use by another node")
+	// ErrNameExists means WriteNamesIfAbsent found one of its names already stored.
+	ErrNameExists = errors.New("redis3: name already exists")
+	// ErrNameNotFound means CompareAndSetScore was given a name which is not in the list.
+	ErrNameNotFound = errors.New("redis3: name not found")
+	// ErrCorruptMember means a listing found a member with the name prefix which is not a stored name,
+	// e.g. written without the order key of WithOrderKey.
+	ErrCorruptMember = errors.New("redis3: member is not a stored name")
+)
+
+// ListingTooLargeError is returned when a listing reaches the maximum number of nodes to scan.
+// The listing can be resumed by passing ResumeFrom as the inclusive startFrom.
+type ListingTooLargeError struct {
+	NodesScanned int
+	ResumeFrom   string
+}
+
+func (e *ListingTooLargeError) Error() string {
+	return fmt.Sprintf("%v: %d nodes, resume from %q", ErrListingTooLarge, e.NodesScanned, e.ResumeFrom)
+}
+
+func (e *ListingTooLargeError) Is(target error) bool {
+	return target == ErrListingTooLarge
+}
+
+// OverCapacityError is returned by WithSafeMode instead of splitting a node holding more than batchSize names.
+type OverCapacityError struct {
+	NodeId    int64
+	NodeSize  int
+	BatchSize int
+}
+
+func (e *OverCapacityError) Error() string {
+	return fmt.Sprintf("%v: node %d has %d names, batch size %d", ErrOverCapacity, e.NodeId, e.NodeSize, e.BatchSize)
+}
+
+func (e *OverCapacityError) Is(target error) bool {
+	return target == ErrOverCapacity
+}
+
+// KeyTypeConflictError is returned when a member set key of the list holds another kind of value,
+// usually because another system writes to the same key space.
+type KeyTypeConflictError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyTypeConflictError) Error() string {
+	return fmt.Sprintf("%v: %s, check no other system uses the key prefix, e.g. with WithNamespaceCheck: %v", ErrKeyTypeConflict, e.Key, e.Err)
+}
+
+func (e *KeyTypeConflictError) Is(target error) bool {
+	return target == ErrKeyTypeConflict || target == ErrWrongType
+}
+
+func (e *KeyTypeConflictError) Unwrap() error {
+	return e.Err
+}
+
+// WriteNamesError is returned by WriteNames when a write fails, telling the names written before it.
+type WriteNamesError struct {
+	// Written are the names in the list before the failure, already stored ones included.
+	// Names of the failed write may be written too, e.g. when a node was filled but not split.
+	Written []string
+	Err     error
+}
+
+func (e *WriteNamesError) Error() string {
+	return fmt.Sprintf("write names: failed after %d names: %v", len(e.Written), e.Err)
+}
+
+func (e *WriteNamesError) Unwrap() error {
+	return e.Err
+}
+
+// wrapNodeKeyError is wrapRedisError for a command on the member set at key, naming key on WRONGTYPE.
+func wrapNodeKeyError(key string, err error) error {
+	if err != nil && classifyRedisError(err) == ErrWrongType {
+		return &KeyTypeConflictError{Key: key, Err: err}
+	}
+	return wrapRedisError(err)
+}
+
+// wrapRedisError wraps a go-redis error with its class sentinel, keeping the original error.
+// Nil and unclassified errors are returned as is.
+func wrapRedisError(err error) error {
+	if err == nil {
+		return nil
+	}
+	kind := classifyRedisError(err)
+	if kind == nil || errors.Is(err, kind) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", kind, err)
+}
+
+func classifyRedisError(err error) error {
+	if err == redis.Nil {
+		return ErrNotFound
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "WRONGTYPE"):
+		return ErrWrongType
+	case strings.HasPrefix(msg, "MOVED "), strings.HasPrefix(msg, "ASK "),
+		strings.HasPrefix(msg, "TRYAGAIN"), strings.HasPrefix(msg, "CLUSTERDOWN"),
+		errors.Is(err, redis.TxFailedErr):
+		return ErrConflict
+	case strings.HasPrefix(msg, "LOADING "), strings.HasPrefix(msg, "MASTERDOWN"),
+		strings.HasPrefix(msg, "READONLY "),
+		errors.Is(err, redis.ErrClosed), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET):
+		return ErrRedisUnavailable
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrRedisUnavailable
+	}
+	return nil
+}
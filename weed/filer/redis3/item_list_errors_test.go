@@ -0,0 +1,85 @@
+package redis3
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemListErrorClasses(t *testing.T) {
+	nl, server := newTestItemList(t, 3)
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+
+	for _, key := range server.Keys() {
+		if _, isNode := nl.parseNodeKey(key); isNode {
+			server.Del(key)
+			assert.NoError(t, server.Set(key, "not a sorted set"))
+		}
+	}
+	err := nl.WriteName(context.Background(), "b")
+	assert.ErrorIs(t, err, ErrWrongType)
+	assert.True(t, strings.Contains(err.Error(), "WRONGTYPE"))
+
+	server.Close()
+	assert.ErrorIs(t, nl.WriteName(context.Background(), "c"), ErrRedisUnavailable)
+
+	assert.ErrorIs(t, wrapRedisError(redis.Nil), ErrNotFound)
+	assert.ErrorIs(t, wrapRedisError(redis.TxFailedErr), ErrConflict)
+	assert.ErrorIs(t, wrapRedisError(redis.TxFailedErr), redis.TxFailedErr)
+	assert.ErrorIs(t, wrapRedisError(errors.New("MOVED 3999 127.0.0.1:6381")), ErrConflict)
+	other := errors.New("ERR something else")
+	assert.Equal(t, other, wrapRedisError(other))
+	assert.Nil(t, wrapRedisError(nil))
+}
+
+func TestKeyTypeConflict(t *testing.T) {
+	nl, server := newTestItemList(t, 3)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	// another system writing a string to the member set key of the first node
+	key := nl.nodeKey(nl.skipList.StartLevels[0].ElementPointer)
+	server.Del(key)
+	assert.NoError(t, server.Set(key, "not a sorted set"))
+
+	assertConflict := func(err error) {
+		t.Helper()
+		if !assert.Error(t, err) {
+			return
+		}
+		var conflict *KeyTypeConflictError
+		if assert.ErrorAs(t, err, &conflict) {
+			assert.Equal(t, key, conflict.Key)
+			assert.True(t, strings.HasPrefix(conflict.Err.Error(), "WRONGTYPE"))
+		}
+		assert.ErrorIs(t, err, ErrKeyTypeConflict)
+		assert.ErrorIs(t, err, ErrWrongType)
+		assert.Contains(t, err.Error(), key)
+		assert.Contains(t, err.Error(), "WithNamespaceCheck")
+	}
+	assertConflict(nl.ListNames(context.Background(), "", func(name string) bool { return true }))
+	_, err := nl.Count(context.Background())
+	assertConflict(err)
+	_, err = nl.Rank(context.Background(), "d")
+	assertConflict(err)
+	assertConflict(nl.WriteName(context.Background(), "a0"))
+
+	// the other nodes are still readable
+	var names []string
+	assert.NoError(t, nl.ListNames(context.Background(), "d", func(name string) bool {
+		names = append(names, name)
+		return true
+	}))
+	assert.Equal(t, []string{"d"}, names)
+
+	// other keys and other errors are not reported as a conflict
+	wrongType := errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	assert.NotErrorIs(t, wrapRedisError(wrongType), ErrKeyTypeConflict)
+	assert.ErrorIs(t, wrapRedisError(wrongType), ErrWrongType)
+	assert.NotErrorIs(t, wrapNodeKeyError(key, redis.TxFailedErr), ErrKeyTypeConflict)
+	assert.Nil(t, wrapNodeKeyError(key, nil))
+}
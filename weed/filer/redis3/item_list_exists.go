@@ -0,0 +1,133 @@
+package redis3
+
+import (
+	"context"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// ExistsMany tells for each of names whether it is in the list.
+// The names are grouped by the node they would fall into, and each node is queried with one ZMSCORE,
+// or with ZSCORE per name on servers without ZMSCORE, all in one pipeline.
+// On error, the returned map is partial: it only holds the names resolved before the error.
+func (nl *ItemList) ExistsMany(ctx context.Context, names []string) (map[string]bool, error) {
+	return nl.existsMany(ctx, names)
+}
+
+// existsMany is ExistsMany, its commands using ctx
+func (nl *ItemList) existsMany(ctx context.Context, names []string) (map[string]bool, error) {
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(names))
+
+	sorted := make([]string, 0, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		if name == "" {
+			found[name] = false
+			continue
+		}
+		sorted = append(sorted, nl.storedName(name))
+	}
+	sort.Strings(sorted)
+
+	var nodeIds []int64
+	nodeNames := make(map[int64][]string)
+	for _, name := range sorted {
+		node, err := nl.nodeOf(ctx, name)
+		if err != nil {
+			return found, err
+		}
+		if node == nil {
+			found[nl.realNameOf(name)] = false
+			continue
+		}
+		if _, ok := nodeNames[node.ElementPointer]; !ok {
+			nodeIds = append(nodeIds, node.ElementPointer)
+		}
+		nodeNames[node.ElementPointer] = append(nodeNames[node.ElementPointer], name)
+	}
+
+	if nl.nodeShardLength > 0 {
+		// the names of a node are in different shards
+		return found, nl.existsByZScore(ctx, nodeIds, nodeNames, found)
+	}
+	err := nl.existsByZMScore(ctx, nodeIds, nodeNames, found)
+	if isUnsupportedCommandError(err) {
+		err = nl.existsByZScore(ctx, nodeIds, nodeNames, found)
+	}
+	return found, err
+}
+
+// nodeOf returns the node which holds name if it exists, or nil if name is before all nodes
+func (nl *ItemList) nodeOf(ctx context.Context, name string) (*skiplist.SkipListElementReference, error) {
+	prev, next, found, err := nl.resolveNeighbors(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if found && string(next.Key) == name {
+		return next.Reference(), nil
+	}
+	return prev, nil
+}
+
+func (nl *ItemList) existsByZMScore(ctx context.Context, nodeIds []int64, nodeNames map[int64][]string, found map[string]bool) error {
+	if len(nodeIds) == 0 {
+		return nil
+	}
+	pipe := nl.client.Pipeline()
+	cmds := make([]*redis.Cmd, len(nodeIds))
+	for i, id := range nodeIds {
+		// the typed ZMScore reads missing members as score 0, which all members have
+		args := []interface{}{"ZMSCORE", nl.nodeKey(id)}
+		for _, name := range nodeNames[id] {
+			args = append(args, name)
+		}
+		cmds[i] = pipe.Do(ctx, args...)
+	}
+	_, err := pipe.Exec(ctx)
+	for i, id := range nodeIds {
+		scores, cmdErr := cmds[i].Slice()
+		if cmdErr != nil {
+			continue
+		}
+		for j, name := range nodeNames[id] {
+			found[nl.realNameOf(name)] = j < len(scores) && scores[j] != nil
+		}
+	}
+	if err != nil && err != redis.Nil {
+		return wrapRedisError(err)
+	}
+	return nil
+}
+
+func (nl *ItemList) existsByZScore(ctx context.Context, nodeIds []int64, nodeNames map[int64][]string, found map[string]bool) error {
+	pipe := nl.client.Pipeline()
+	cmds := make(map[string]*redis.FloatCmd)
+	for _, id := range nodeIds {
+		key := nl.nodeKey(id)
+		for _, name := range nodeNames[id] {
+			cmds[name] = pipe.ZScore(ctx, nl.memberKey(key, name), name)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	for name, cmd := range cmds {
+		switch cmd.Err() {
+		case nil:
+			found[nl.realNameOf(name)] = true
+		case redis.Nil:
+			found[nl.realNameOf(name)] = false
+		}
+	}
+	if err != nil && err != redis.Nil {
+		return wrapRedisError(err)
+	}
+	return nil
+}
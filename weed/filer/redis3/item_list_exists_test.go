@@ -0,0 +1,30 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExistsMany(t *testing.T) {
+	nl, server := newTestItemList(t, 3)
+	for i := 0; i < 20; i += 2 {
+		assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("name%02d", i)))
+	}
+
+	queried := []string{"name00", "name01", "name04", "name10", "name18", "name19", "name04", "a", "z", ""}
+	found, err := nl.ExistsMany(context.Background(), queried)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		"name00": true, "name01": false, "name04": true, "name10": true,
+		"name18": true, "name19": false, "a": false, "z": false, "": false,
+	}, found)
+
+	// connection errors return a partial map
+	server.Close()
+	found, err = nl.ExistsMany(context.Background(), []string{"name00", "name02"})
+	assert.ErrorIs(t, err, ErrRedisUnavailable)
+	assert.NotContains(t, found, "name02")
+}
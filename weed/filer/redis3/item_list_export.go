@@ -0,0 +1,21 @@
+package redis3
+
+import (
+	"context"
+)
+
+// ExportWithBackpressure passes all names in order to sink, e.g. to copy a directory to a queue or an object store,
+// stopping at the first sink error or when ctx is done, and returning that error.
+// Nodes are read as sink consumes them: a blocked sink stops the listing, and with WithListingPrefetch
+// at most the prefetch depth plus one nodes are read ahead, so memory stays bounded however slow the sink is.
+func (nl *ItemList) ExportWithBackpressure(ctx context.Context, sink func(name string) error) error {
+	return nl.ListNamesWithError(ctx, "", func(name string) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		if err := sink(name); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
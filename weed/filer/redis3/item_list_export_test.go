@@ -0,0 +1,61 @@
+package redis3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportWithBackpressure(t *testing.T) {
+	const prefetchDepth = 2
+	nl, _ := newTestItemList(t, 3, WithListingPrefetch(prefetchDepth))
+	var expected []string
+	for i := 0; i < 30; i++ {
+		name := fmt.Sprintf("name%02d", i)
+		expected = append(expected, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	nodeReads := &countCommands{name: "zrangebylex"}
+	nl.client.AddHook(nodeReads)
+
+	// a sink blocked on its first name stops the node reads after the prefetch depth
+	unblock := make(chan struct{})
+	var exported []string
+	done := make(chan error)
+	go func() {
+		done <- nl.ExportWithBackpressure(context.Background(), func(name string) error {
+			if len(exported) == 0 {
+				<-unblock
+			}
+			exported = append(exported, name)
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+	}()
+	time.Sleep(100 * time.Millisecond)
+	assert.LessOrEqual(t, nodeReads.count.Load(), int64(prefetchDepth+2))
+	close(unblock)
+	assert.NoError(t, <-done)
+	assert.Equal(t, expected, exported)
+	assert.Equal(t, int64(countNodes(t, nl)), nodeReads.count.Load())
+
+	// the sink error and the cancellation stop the export
+	sinkErr := errors.New("sink is full")
+	exported = nil
+	err := nl.ExportWithBackpressure(context.Background(), func(name string) error {
+		if len(exported) == 5 {
+			return sinkErr
+		}
+		exported = append(exported, name)
+		return nil
+	})
+	assert.ErrorIs(t, err, sinkErr)
+	assert.Equal(t, expected[:5], exported)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, nl.ExportWithBackpressure(ctx, func(name string) error { return nil }), context.Canceled)
+}
@@ -0,0 +1,165 @@
+package redis3
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func newTestItemList(t *testing.T, batchSize int, opts ...ItemListOption) (*ItemList, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{
+		Addr: server.Addr(),
+	})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	store := newSkipListElementStore("/test/dir", client)
+	return mustNewItemList(t, client, "/test/dir", store, batchSize, opts...), server
+}
+
+func mustNewItemList(t *testing.T, client redis.UniversalClient, prefix string, store skiplist.ListStore, batchSize int, opts ...ItemListOption) *ItemList {
+	nl, err := newItemList(client, prefix, store, batchSize, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nl
+}
+
+func listAllNames(t *testing.T, nl *ItemList, startFrom string) (names []string) {
+	err := nl.ListNames(context.Background(), startFrom, func(name string) bool {
+		names = append(names, name)
+		return true
+	})
+	assert.NoError(t, err)
+	return
+}
+
+// assertItemListConsistent checks every node is non-empty, starts with its skiplist key,
+// and that names are in order across nodes.
+func assertItemListConsistent(t *testing.T, nl *ItemList) {
+	var lastName string
+	nodeRef := nl.skipList.StartLevels[0]
+	for nodeRef != nil {
+		node, err := nl.skipList.LoadElement(nodeRef)
+		assert.NoError(t, err)
+		if node == nil {
+			break
+		}
+		names, err := nl.NodeRangeBeforeExclusive(node.Reference(), "")
+		assert.NoError(t, err)
+		if assert.NotEmpty(t, names, "node %d with key %s is empty", node.Id, string(node.Key)) {
+			assert.Equal(t, string(node.Key), names[0], "node %d key", node.Id)
+			assert.Less(t, lastName, names[0], "node %d order", node.Id)
+			lastName = names[len(names)-1]
+		}
+		nodeRef = node.Next[0]
+	}
+}
+
+func countNodes(t *testing.T, nl *ItemList) (n int) {
+	for ref := nl.skipList.StartLevels[0]; ref != nil; n++ {
+		node, err := nl.skipList.LoadElement(ref)
+		assert.NoError(t, err)
+		ref = node.Next[0]
+	}
+	return n
+}
+
+func mustNodeNames(t *testing.T, nl *ItemList, node *skiplist.SkipListElementReference) []string {
+	names, err := nl.NodeRangeBeforeExclusive(node, "")
+	assert.NoError(t, err)
+	return names
+}
+
+// fakeClock is a Clock advanced by the test
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+// recordingTracer keeps the attributes of each ended span, by span name
+type recordingTracer struct {
+	noop.Tracer
+	ended []recordedSpan
+}
+
+type recordedSpan struct {
+	noop.Span
+	tracer *recordingTracer
+	name   string
+	attrs  map[attribute.Key]attribute.Value
+	err    error
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &recordedSpan{tracer: r, name: name, attrs: map[attribute.Key]attribute.Value{}}
+	config := trace.NewSpanStartConfig(opts...)
+	span.SetAttributes(config.Attributes()...)
+	return ctx, span
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, attr := range kv {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.err = err
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.tracer.ended = append(s.tracer.ended, *s)
+}
+
+// withOptions returns a copy of nl sharing its skiplist, with opts applied
+func withOptions(nl *ItemList, opts ...ItemListOption) *ItemList {
+	copied := *nl
+	for _, opt := range opts {
+		opt(&copied)
+	}
+	return &copied
+}
+
+// countCommands counts the commands of one name sent by a client
+type countCommands struct {
+	name  string
+	count atomic.Int64
+}
+
+func (c *countCommands) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (c *countCommands) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == c.name {
+			c.count.Add(1)
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (c *countCommands) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if cmd.Name() == c.name {
+				c.count.Add(1)
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
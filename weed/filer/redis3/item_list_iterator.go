@@ -0,0 +1,138 @@
+package redis3
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// NameIterator visits the names of an ItemList in order, loading one node at a time.
+//
+//	it := nl.Iterator(ctx, "")
+//	for it.Next() {
+//		name := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type NameIterator struct {
+	nl           *ItemList
+	ctx          context.Context
+	startFrom    string
+	started      bool
+	nextNode     *skiplist.SkipListElementReference
+	names        []string
+	name         string
+	stored       string
+	nodesScanned int
+	guard        *chainGuard
+	err          error
+}
+
+// Iterator returns an iterator over the names, starting from startFrom inclusively.
+// An empty startFrom iterates from the beginning. Its commands use ctx.
+func (nl *ItemList) Iterator(ctx context.Context, startFrom string) *NameIterator {
+	return &NameIterator{
+		nl:        nl,
+		ctx:       ctx,
+		startFrom: nl.storedStart(startFrom),
+		err:       nl.checkRangeListing(startFrom),
+	}
+}
+
+// Next advances to the next name, and returns false at the end or on error.
+func (it *NameIterator) Next() bool {
+	ctx := it.ctx
+	for len(it.names) == 0 {
+		if it.err != nil {
+			return false
+		}
+		if !it.started {
+			it.started = true
+			if it.err = it.start(ctx); it.err != nil {
+				return false
+			}
+		}
+		if it.nextNode == nil {
+			return false
+		}
+		if it.err = it.loadNode(ctx); it.err != nil {
+			return false
+		}
+	}
+	name := it.names[0]
+	it.names = it.names[1:]
+	realName, ok, err := it.nl.listedName(name)
+	if err != nil {
+		it.err = err
+	}
+	if !ok {
+		// sorted after all names with the prefix
+		it.names, it.nextNode = nil, nil
+		return false
+	}
+	it.name, it.stored = realName, name
+	return true
+}
+
+// Value returns the current name.
+func (it *NameIterator) Value() string {
+	return it.name
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *NameIterator) Err() error {
+	return it.err
+}
+
+func (it *NameIterator) start(ctx context.Context) error {
+	nl := it.nl
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+	if it.startFrom != "" {
+		node, err := nl.nodeOf(ctx, it.startFrom)
+		if err != nil {
+			return err
+		}
+		if node != nil {
+			it.nextNode = node
+			return nil
+		}
+	}
+	it.nextNode = nl.skipList.StartLevels[0]
+	return nil
+}
+
+func (it *NameIterator) loadNode(ctx context.Context) error {
+	nl := it.nl
+	if nl.maxListingNodes > 0 && it.nodesScanned >= nl.maxListingNodes {
+		return &ListingTooLargeError{NodesScanned: it.nodesScanned, ResumeFrom: string(it.nextNode.Key)}
+	}
+	if it.guard == nil {
+		it.guard = nl.newChainGuard(ctx)
+	}
+	node, err := it.guard.load(nl.skipList, it.nextNode)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		it.nextNode = nil
+		return nil
+	}
+	it.nodesScanned++
+	min := "-"
+	if it.startFrom != "" {
+		min = "[" + it.startFrom
+	}
+	key := nl.nodeKey(node.Id)
+	it.names, err = nl.zRangeByLex(ctx, key, &redis.ZRangeBy{
+		Min: min,
+		Max: "+",
+	}).Result()
+	if err != nil {
+		return wrapNodeKeyError(key, err)
+	}
+	it.nextNode = node.Next[0]
+	return nil
+}
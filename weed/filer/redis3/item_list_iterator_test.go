@@ -0,0 +1,56 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameIterator(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	var expected []string
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("name%02d", i)
+		expected = append(expected, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	iterate := func(it *NameIterator, limit int) (names []string) {
+		for it.Next() {
+			names = append(names, it.Value())
+			if len(names) == limit {
+				break
+			}
+		}
+		assert.NoError(t, it.Err())
+		return names
+	}
+
+	assert.Equal(t, expected, iterate(nl.Iterator(context.Background(), ""), -1))
+	assert.Equal(t, expected[:5], iterate(nl.Iterator(context.Background(), ""), 5))
+	// start inside a node, on a leading key, before and after all names
+	for _, startFrom := range []string{"name07", "name09", "name10", "name10x", "a", "z"} {
+		var want []string
+		for _, name := range expected {
+			if name >= startFrom {
+				want = append(want, name)
+			}
+		}
+		assert.Equal(t, want, iterate(nl.Iterator(context.Background(), startFrom), -1), startFrom)
+	}
+
+	empty, _ := newTestItemList(t, 3)
+	it := empty.Iterator(context.Background(), "")
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+
+	capped, _ := newTestItemList(t, 2, WithMaxListingNodes(2))
+	for _, name := range expected {
+		assert.NoError(t, capped.WriteName(context.Background(), name))
+	}
+	it = capped.Iterator(context.Background(), "")
+	for it.Next() {
+	}
+	assert.ErrorIs(t, it.Err(), ErrListingTooLarge)
+}
@@ -0,0 +1,210 @@
+package redis3
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+/*
+Some Redis compatible proxies do not support ZLEXCOUNT, ZRANGEBYLEX and ZREMRANGEBYLEX.
+With WithLexFallback, the first use of a ClientState probes ZLEXCOUNT. If it is unsupported, node sizes use ZCARD,
+and lex ranges read the whole member set with ZRANGE by rank, which is in lex order since all scores are 0,
+and filter it in Go. Removing a range becomes a read followed by ZREM, which is not atomic.
+*/
+
+func (nl *ItemList) lexFallback(ctx context.Context) bool {
+	if !nl.probeLex {
+		return false
+	}
+	switch nl.clientState.lex.Load() {
+	case lexSupported:
+		return false
+	case lexUnsupported:
+		return true
+	}
+	err := nl.client.ZLexCount(ctx, nl.prefix+"lexprobe", "-", "+").Err()
+	if err != nil && !isUnsupportedCommandError(err) {
+		// probe again next time
+		return false
+	}
+	fallback := err != nil
+	if fallback {
+		glog.Warningf("redis3: lex commands are not supported, %v. Fall back to ZCARD and ZRANGE, which read whole nodes", err)
+	}
+	if fallback {
+		nl.clientState.lex.Store(lexUnsupported)
+	} else {
+		nl.clientState.lex.Store(lexSupported)
+	}
+	return fallback
+}
+
+// zLexCountAll counts the members of a node, and can be pipelined
+func (nl *ItemList) zLexCountAll(ctx context.Context, c redis.Cmdable, key string) *redis.IntCmd {
+	if nl.nodeShardLength > 0 {
+		// not pipelined, the shards are read first
+		return nl.shardedSum(ctx, key, func(pipe redis.Pipeliner, shardKey string) redis.Cmder {
+			return pipe.ZCard(ctx, shardKey)
+		})
+	}
+	if nl.lexFallback(ctx) {
+		return c.ZCard(ctx, key)
+	}
+	return c.ZLexCount(ctx, key, "-", "+")
+}
+
+// zLexMin reads the smallest member of a node, and can be pipelined
+func (nl *ItemList) zLexMin(ctx context.Context, c redis.Cmdable, key string) *redis.StringSliceCmd {
+	if nl.nodeShardLength > 0 {
+		return nl.shardedLexMin(ctx, key)
+	}
+	if nl.lexFallback(ctx) {
+		return c.ZRange(ctx, key, 0, 0)
+	}
+	return c.ZRangeByLex(ctx, key, &redis.ZRangeBy{Min: "-", Max: "+", Offset: 0, Count: 1})
+}
+
+func (nl *ItemList) zLexCount(ctx context.Context, key, min, max string) *redis.IntCmd {
+	if nl.nodeShardLength > 0 {
+		return nl.shardedLexCount(ctx, key, min, max)
+	}
+	if !nl.lexFallback(ctx) {
+		return nl.client.ZLexCount(ctx, key, min, max)
+	}
+	if min == "-" && max == "+" {
+		return nl.client.ZCard(ctx, key)
+	}
+	cmd := redis.NewIntCmd(ctx)
+	names, err := nl.zRangeByLexFallback(ctx, key, &redis.ZRangeBy{Min: min, Max: max})
+	cmd.SetVal(int64(len(names)))
+	cmd.SetErr(err)
+	return cmd
+}
+
+func (nl *ItemList) zRangeByLex(ctx context.Context, key string, opt *redis.ZRangeBy) *redis.StringSliceCmd {
+	if nl.nodeShardLength > 0 {
+		return nl.shardedRangeByLex(ctx, key, opt)
+	}
+	if !nl.lexFallback(ctx) {
+		return nl.client.ZRangeByLex(ctx, key, opt)
+	}
+	cmd := redis.NewStringSliceCmd(ctx)
+	names, err := nl.zRangeByLexFallback(ctx, key, opt)
+	cmd.SetVal(names)
+	cmd.SetErr(err)
+	return cmd
+}
+
+// zRevRangeByLex is zRangeByLex in descending order. Sharded nodes and the fallback read the range ascending first.
+func (nl *ItemList) zRevRangeByLex(ctx context.Context, key string, opt *redis.ZRangeBy) *redis.StringSliceCmd {
+	if nl.nodeShardLength == 0 && !nl.lexFallback(ctx) {
+		return nl.client.ZRevRangeByLex(ctx, key, opt)
+	}
+	cmd := redis.NewStringSliceCmd(ctx)
+	names, err := nl.zRangeByLex(ctx, key, &redis.ZRangeBy{Min: opt.Min, Max: opt.Max}).Result()
+	slices.Reverse(names)
+	if opt.Count > 0 && int64(len(names)) > opt.Count {
+		names = names[:opt.Count]
+	}
+	cmd.SetVal(names)
+	cmd.SetErr(err)
+	return cmd
+}
+
+func (nl *ItemList) zRemRangeByLex(ctx context.Context, key, min, max string) *redis.IntCmd {
+	if nl.nodeShardLength > 0 {
+		return nl.shardedRemRangeByLex(ctx, key, min, max)
+	}
+	if !nl.lexFallback(ctx) {
+		return nl.client.ZRemRangeByLex(ctx, key, min, max)
+	}
+	names, err := nl.zRangeByLexFallback(ctx, key, &redis.ZRangeBy{Min: min, Max: max})
+	if err != nil || len(names) == 0 {
+		cmd := redis.NewIntCmd(ctx)
+		cmd.SetErr(err)
+		return cmd
+	}
+	members := make([]interface{}, len(names))
+	for i, name := range names {
+		members[i] = name
+	}
+	return nl.client.ZRem(ctx, key, members...)
+}
+
+func (nl *ItemList) zRangeByLexFallback(ctx context.Context, key string, opt *redis.ZRangeBy) ([]string, error) {
+	all, err := nl.client.ZRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	skipped := int64(0)
+	for _, name := range all {
+		if !lexAboveMin(name, opt.Min) || !lexBelowMax(name, opt.Max) {
+			continue
+		}
+		if skipped < opt.Offset {
+			skipped++
+			continue
+		}
+		names = append(names, name)
+		if opt.Count > 0 && int64(len(names)) >= opt.Count {
+			break
+		}
+	}
+	return names, nil
+}
+
+// nodeScanFallback is nodeScanInclusiveAfter without the lex commands. The node is read by rank in chunks,
+// in lex order since all scores are 0, instead of whole for each chunk, and the last name visited bounds the next chunk,
+// so a name is not visited twice when names before it were added between the chunks.
+func (nl *ItemList) nodeScanFallback(ctx context.Context, key, min string, chunk int64, visitNamesFn func(name string) (bool, error)) (bool, error) {
+	for start := int64(0); ; start += chunk {
+		names, err := nl.client.ZRange(ctx, key, start, start+chunk-1).Result()
+		if err != nil {
+			return false, wrapNodeKeyError(key, err)
+		}
+		for _, name := range names {
+			if !lexAboveMin(name, min) {
+				continue
+			}
+			if shouldContinue, err := visitNamesFn(name); !shouldContinue || err != nil {
+				return false, err
+			}
+			min = "(" + name
+		}
+		if int64(len(names)) < chunk {
+			return true, nil
+		}
+		nl.warnOversizedNode(key)
+	}
+}
+
+// lexAboveMin tells whether name is within the lower lex bound "-", "[x" or "(x"
+func lexAboveMin(name, min string) bool {
+	switch {
+	case min == "-":
+		return true
+	case strings.HasPrefix(min, "["):
+		return name >= min[1:]
+	case strings.HasPrefix(min, "("):
+		return name > min[1:]
+	}
+	return false
+}
+
+// lexBelowMax tells whether name is within the upper lex bound "+", "[x" or "(x"
+func lexBelowMax(name, max string) bool {
+	switch {
+	case max == "+":
+		return true
+	case strings.HasPrefix(max, "["):
+		return name <= max[1:]
+	case strings.HasPrefix(max, "("):
+		return name < max[1:]
+	}
+	return false
+}
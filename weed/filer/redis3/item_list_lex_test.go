@@ -0,0 +1,155 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// rejectLexCommands makes the client behave like a proxy without the lex commands
+type rejectLexCommands struct{}
+
+func isLexCommand(cmd redis.Cmder) bool {
+	switch cmd.Name() {
+	case "zlexcount", "zrangebylex", "zremrangebylex":
+		return true
+	}
+	return false
+}
+
+func (rejectLexCommands) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (rejectLexCommands) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if isLexCommand(cmd) {
+			err := fmt.Errorf("ERR unknown command '%s'", cmd.Name())
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (rejectLexCommands) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if isLexCommand(cmd) {
+				err := fmt.Errorf("ERR unknown command '%s'", cmd.Name())
+				cmd.SetErr(err)
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func TestLexFallback(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	client.AddHook(rejectLexCommands{})
+	store := newSkipListElementStore("/test/dir", client)
+
+	nl := mustNewItemList(t, client, "/test/dir", store, 3)
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	assert.Error(t, nl.WriteName(context.Background(), "b"))
+
+	nl = mustNewItemList(t, client, "/test/dir2", store, 3, WithLexFallback())
+	r := rand.New(rand.NewSource(1))
+	model := make(map[string]bool)
+	for step := 0; step < 200; step++ {
+		name := fmt.Sprintf("%03d", r.Intn(40))
+		if r.Intn(3) == 0 {
+			assert.NoError(t, nl.DeleteName(context.Background(), name))
+			delete(model, name)
+		} else {
+			assert.NoError(t, nl.WriteName(context.Background(), name))
+			model[name] = true
+		}
+	}
+	var expected []string
+	for name := range model {
+		expected = append(expected, name)
+	}
+	sort.Strings(expected)
+	assertItemListConsistent(t, nl)
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assert.Equal(t, expected[3:], listAllNames(t, nl, expected[3]))
+
+	report, err := nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, report.IsConsistent())
+
+	// the probe result is kept on the client state, not on the client
+	assert.Equal(t, int32(lexUnsupported), nl.clientState.lex.Load())
+	shared := mustNewItemList(t, client, "/test/dir2", store, 3, WithLexFallback(), WithClientState(nl.clientState))
+	assert.Same(t, nl.clientState, shared.clientState)
+	fresh := mustNewItemList(t, client, "/test/dir2", store, 3)
+	assert.Equal(t, int32(lexUnprobed), fresh.clientState.lex.Load())
+}
+
+func TestLexFallbackScansNodeOnce(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	client.AddHook(rejectLexCommands{})
+	nl := mustNewItemList(t, client, "/test/dir", newSkipListElementStore("/test/dir", client), 3, WithLexFallback())
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	// an oversized node of 10 names, scanned in chunks of batchSize+1
+	first := nl.skipList.StartLevels[0]
+	var names []string
+	for i := 0; i < 9; i++ {
+		names = append(names, fmt.Sprintf("b%d", i))
+	}
+	assert.NoError(t, nl.NodeAddMember(first, names...))
+
+	read := &rangedMembers{}
+	client.AddHook(read)
+	assert.Equal(t, append([]string{"a"}, names...), listAllNames(t, nl, ""))
+	assert.Equal(t, int64(10), read.members.Load())
+	read.members.Store(0)
+	assert.Equal(t, names[4:], listAllNames(t, nl, "b4"))
+	assert.Equal(t, int64(10), read.members.Load())
+}
+
+// rangedMembers counts the members returned by ZRANGE
+type rangedMembers struct {
+	members atomic.Int64
+}
+
+func (r *rangedMembers) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (r *rangedMembers) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		if cmd, ok := cmd.(*redis.StringSliceCmd); ok && cmd.Name() == "zrange" {
+			r.members.Add(int64(len(cmd.Val())))
+		}
+		return err
+	}
+}
+
+func (r *rangedMembers) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestLexBounds(t *testing.T) {
+	assert.True(t, lexAboveMin("b", "-"))
+	assert.True(t, lexAboveMin("b", "[b"))
+	assert.False(t, lexAboveMin("b", "(b"))
+	assert.True(t, lexBelowMax("b", "+"))
+	assert.True(t, lexBelowMax("b", "[b"))
+	assert.False(t, lexBelowMax("b", "(b"))
+	assert.False(t, lexBelowMax("c", "(b"))
+}
@@ -0,0 +1,163 @@
+package redis3
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// MaintenanceConfig configures the background maintenance of WithMaintenance
+type MaintenanceConfig struct {
+	// Interval between runs, each delayed by up to Jitter more, so lists started together do not run together
+	Interval time.Duration
+	Jitter   time.Duration
+	// Compact runs CompactRange over the whole list after Verify, merging underfull nodes and removing empty ones.
+	// Without it, runs only verify.
+	Compact bool
+	// Lock is held during a run, e.g. the directory lock writers of the list take.
+	// It is required with Compact, which would otherwise race the writers.
+	Lock func() (unlock func(), err error)
+}
+
+// maintenance runs in the background until stopped. It only holds copies of the list configuration,
+// and loads the list from its header in Redis for each run, so it shares no state with the list it was started by.
+type maintenance struct {
+	config    MaintenanceConfig
+	client    redis.UniversalClient
+	prefix    string
+	store     skiplist.ListStore
+	batchSize int
+	opts      []ItemListOption
+	state     *ClientState
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+type maintenanceKey struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// maintenanceRunning guards against two runs for the same list in this process
+var maintenanceRunning sync.Map // maintenanceKey -> struct{}
+
+func (nl *ItemList) startMaintenance() {
+	m := &maintenance{
+		config:    *nl.maintenanceConfig,
+		client:    nl.client,
+		prefix:    nl.prefix,
+		store:     nl.skipList.ListStore,
+		batchSize: nl.batchSize,
+		opts:      nl.opts,
+		state:     nl.clientState,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	nl.maintenance = m
+	go m.loop()
+}
+
+func (m *maintenance) loop() {
+	defer close(m.done)
+	for {
+		delay := m.config.Interval
+		if m.config.Jitter > 0 {
+			delay += time.Duration(rand.Int63n(int64(m.config.Jitter)))
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-m.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := m.run(); err != nil {
+			stats.FilerStoreCounter.WithLabelValues("redis3", "maintenance_error").Inc()
+			glog.Warningf("maintenance %s: %v", m.prefix, err)
+		}
+	}
+}
+
+// run verifies, and with Compact compacts, the list as stored in Redis
+func (m *maintenance) run() error {
+	key := maintenanceKey{client: m.client, prefix: m.prefix}
+	if _, running := maintenanceRunning.LoadOrStore(key, struct{}{}); running {
+		glog.V(1).Infof("maintenance %s: skipped, already running", m.prefix)
+		return nil
+	}
+	defer maintenanceRunning.Delete(key)
+	if m.config.Lock != nil {
+		unlock, err := m.config.Lock()
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+	start := time.Now()
+	defer func() {
+		stats.FilerStoreHistogram.WithLabelValues("redis3", "maintenance").Observe(time.Since(start).Seconds())
+	}()
+	stats.FilerStoreCounter.WithLabelValues("redis3", "maintenance_run").Inc()
+
+	ctx := context.Background()
+	data, err := m.client.Get(ctx, m.prefix).Bytes()
+	if err != nil && err != redis.Nil {
+		return wrapRedisError(err)
+	}
+	opts := append(append([]ItemListOption(nil), m.opts...), func(nl *ItemList) {
+		nl.maintenanceConfig = nil
+		nl.clientState = m.state
+	})
+	nl, err := LoadItemList(data, m.prefix, m.client, m.store, m.batchSize, opts...)
+	if err != nil {
+		return err
+	}
+	report, err := nl.Verify(ctx)
+	if err != nil {
+		return err
+	}
+	glog.V(0).Infof("maintenance %s: %d nodes, %d names, %d empty, %d key mismatches, %d over capacity, %d single name",
+		m.prefix, report.Nodes, report.Names, len(report.EmptyNodes), len(report.KeyMismatchNodes),
+		len(report.OverCapacityNodes), report.SingleNameNodes)
+	if !m.config.Compact || nl.readOnly {
+		return nil
+	}
+
+	if err := nl.CompactRange(ctx, "", ""); err != nil {
+		return err
+	}
+	if nl.HasChanges() {
+		if err := m.client.Set(ctx, m.prefix, nl.ToBytes(), 0).Err(); err != nil {
+			return wrapRedisError(err)
+		}
+	}
+	nodes := 0
+	if err := nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		nodes++
+		return true, nil
+	}); err != nil {
+		return err
+	}
+	if removed := report.Nodes - nodes; removed > 0 {
+		stats.FilerStoreCounter.WithLabelValues("redis3", "maintenance_compact").Add(float64(removed))
+		glog.V(0).Infof("maintenance %s: compacted %d nodes away", m.prefix, removed)
+	}
+	return nil
+}
+
+// Close stops the background maintenance of WithMaintenance, waiting for a running run to finish.
+// Without it, there is nothing to close.
+func (nl *ItemList) Close() error {
+	if nl.maintenance != nil {
+		close(nl.maintenance.stop)
+		<-nl.maintenance.done
+		nl.maintenance = nil
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package redis3
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenance(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	assert.Nil(t, nl.maintenance)
+	assert.NoError(t, nl.Close())
+
+	// compacting races the writers without a lock
+	_, err := newItemList(nl.client, nl.prefix, nl.skipList.ListStore, 3, WithMaintenance(MaintenanceConfig{
+		Interval: time.Second,
+		Compact:  true,
+	}))
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	runs := stats.FilerStoreCounter.WithLabelValues("redis3", "maintenance_run")
+	compactions := stats.FilerStoreCounter.WithLabelValues("redis3", "maintenance_compact")
+	compactionsBefore := testutil.ToFloat64(compactions)
+	locked := atomic.Int64{}
+	nl, _ = newTestItemList(t, 3, WithMaintenance(MaintenanceConfig{
+		Interval: 5 * time.Millisecond,
+		Jitter:   5 * time.Millisecond,
+		Compact:  true,
+		Lock: func() (func(), error) {
+			locked.Add(1)
+			return func() {}, nil
+		},
+	}))
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	// underfull nodes, as left by deletes which did not merge, of the first name of each node
+	var kept []string
+	for ref := nl.skipList.StartLevels[0]; ref != nil; {
+		node, err := nl.skipList.LoadElement(ref)
+		assert.NoError(t, err)
+		names := mustNodeNames(t, nl, ref)
+		kept = append(kept, names[0])
+		for _, name := range names[1:] {
+			assert.NoError(t, nl.NodeDeleteMember(ref, name))
+		}
+		ref = node.Next[0]
+	}
+	assert.Greater(t, len(kept), 1)
+	assert.NoError(t, nl.client.Set(context.Background(), nl.prefix, nl.ToBytes(), 0).Err())
+
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(compactions) > compactionsBefore
+	}, 5*time.Second, 5*time.Millisecond)
+	assert.NoError(t, nl.Close())
+	assert.Nil(t, nl.maintenance)
+	assert.NotZero(t, locked.Load())
+
+	data, err := nl.client.Get(context.Background(), nl.prefix).Bytes()
+	assert.NoError(t, err)
+	compacted, err := LoadItemList(data, nl.prefix, nl.client, nl.skipList.ListStore, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, kept, listAllNames(t, compacted, ""))
+	assert.Less(t, countNodes(t, compacted), len(kept))
+	assertItemListConsistent(t, compacted)
+
+	// stopped
+	runsAfterClose := testutil.ToFloat64(runs)
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, runsAfterClose, testutil.ToFloat64(runs))
+}
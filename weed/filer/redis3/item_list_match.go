@@ -0,0 +1,39 @@
+package redis3
+
+import (
+	"context"
+	"path"
+	"strings"
+)
+
+// ListNamesMatching visits the names from startFrom matching the glob pattern, with the syntax of path.Match.
+// Only the literal prefix of the pattern, before its first special character, narrows the listing
+// via the skiplist, and the listing stops after the names with that prefix.
+// The rest of the pattern is matched in Go on each name of that range, so "*.jpg" scans everything,
+// as does any pattern with WithOrderKey.
+func (nl *ItemList) ListNamesMatching(ctx context.Context, startFrom string, pattern string, visitNamesFn func(name string) bool) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+	literalPrefix := pattern
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		literalPrefix = pattern[:i]
+	}
+	if nl.orderKey != nil || nl.nameCipher != nil {
+		// names with the literal prefix are not adjacent in the order of the order keys or ciphertexts
+		literalPrefix = ""
+	}
+	if startFrom < literalPrefix {
+		startFrom = literalPrefix
+	}
+	return nl.ListNamesWithError(ctx, startFrom, func(name string) (bool, error) {
+		if !strings.HasPrefix(name, literalPrefix) {
+			// sorted after all names with the literal prefix
+			return false, nil
+		}
+		if matched, _ := path.Match(pattern, name); !matched {
+			return true, nil
+		}
+		return visitNamesFn(name), nil
+	})
+}
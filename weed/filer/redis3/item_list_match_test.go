@@ -0,0 +1,38 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListNamesMatching(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	for _, name := range []string{"a.jpg", "a.png", "b.jpg", "photo1.jpg", "photo2.png", "photo3.jpg", "z.jpg"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	match := func(startFrom, pattern string) (names []string) {
+		assert.NoError(t, nl.ListNamesMatching(context.Background(), startFrom, pattern, func(name string) bool {
+			names = append(names, name)
+			return true
+		}))
+		return
+	}
+	// unanchored, scans all names
+	assert.Equal(t, []string{"a.jpg", "b.jpg", "photo1.jpg", "photo3.jpg", "z.jpg"}, match("", "*.jpg"))
+	// anchored by a literal prefix
+	assert.Equal(t, []string{"photo1.jpg", "photo3.jpg"}, match("", "photo?.jpg"))
+	assert.Equal(t, []string{"photo3.jpg"}, match("photo2", "photo*.jpg"))
+	assert.Equal(t, []string{"b.jpg"}, match("", "b.jpg"))
+	assert.Empty(t, match("", "q*"))
+
+	var scanned []string
+	assert.NoError(t, nl.ListNamesMatching(context.Background(), "", "photo*", func(name string) bool {
+		scanned = append(scanned, name)
+		return len(scanned) < 2
+	}))
+	assert.Equal(t, []string{"photo1.jpg", "photo2.png"}, scanned)
+
+	assert.Error(t, nl.ListNamesMatching(context.Background(), "", "[", func(name string) bool { return true }))
+}
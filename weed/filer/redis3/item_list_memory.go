@@ -0,0 +1,96 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+const memoryUsagePipelineSize = 1000
+
+// MemoryUsage estimates the Redis memory used by this list, summing MEMORY USAGE
+// of the list key, the node counts, the checksum, the name scores, each skiplist element key and each node member set, with its shards.
+// ErrUnsupported is returned if the server or proxy does not support MEMORY USAGE.
+func (nl *ItemList) MemoryUsage(ctx context.Context) (int64, error) {
+
+	keys := []string{nl.prefix, nl.nodeCountKey(), nl.checksumKey(), nl.nameScoresKey()}
+	var total int64
+
+	err := nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		keys = append(keys,
+			fmt.Sprintf("%s%d", nl.prefix, node.Id),
+			nl.nodeKey(node.Id),
+		)
+		if nl.nodeShardLength > 0 {
+			shards, err := nl.nodeShards(ctx, nl.nodeKey(node.Id))
+			if err != nil {
+				return false, wrapRedisError(err)
+			}
+			for _, shard := range shards {
+				keys = append(keys, nodeShardKey(nl.nodeKey(node.Id), shard))
+			}
+		}
+		if len(keys) >= memoryUsagePipelineSize {
+			usage, err := nl.memoryUsageOfKeys(ctx, keys)
+			if err != nil {
+				return false, err
+			}
+			total += usage
+			keys = keys[:0]
+		}
+		return true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	usage, err := nl.memoryUsageOfKeys(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+	return total + usage, nil
+}
+
+func (nl *ItemList) memoryUsageOfKeys(ctx context.Context, keys []string) (total int64, err error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	pipe := nl.client.Pipeline()
+	var cmds []*redis.Cmd
+	for _, key := range keys {
+		cmds = append(cmds, pipe.Do(ctx, "MEMORY", "USAGE", key))
+	}
+	_, err = pipe.Exec(ctx)
+	for _, cmd := range cmds {
+		usage, cmdErr := cmd.Int64()
+		if cmdErr == redis.Nil {
+			// the key does not exist
+			continue
+		}
+		if cmdErr != nil {
+			if isUnsupportedCommandError(cmdErr) {
+				return 0, fmt.Errorf("memory usage: %w", ErrUnsupported)
+			}
+			return 0, cmdErr
+		}
+		total += usage
+	}
+	if err != nil && err != redis.Nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func isUnsupportedCommandError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unknown command") ||
+		strings.Contains(msg, "unknown subcommand") ||
+		strings.Contains(msg, "not supported") ||
+		strings.Contains(msg, "unsupported")
+}
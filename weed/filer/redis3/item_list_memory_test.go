@@ -0,0 +1,23 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemListMemoryUsage(t *testing.T) {
+	nl, _ := newTestItemList(t, 2)
+
+	usage, err := nl.MemoryUsage(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, usage)
+
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	usage, err = nl.MemoryUsage(context.Background())
+	assert.NoError(t, err)
+	assert.Positive(t, usage)
+}
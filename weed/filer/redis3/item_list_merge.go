@@ -0,0 +1,62 @@
+package redis3
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+A merge moves all members of a source node into a target node and deletes the source.
+Done as three commands, a name added to the source between reading its members and deleting it is lost,
+so mergeNodesScript does all three at once. It does not make the merge safe for concurrent writers:
+a writer which routed a name to the source before the merge, and adds it after, recreates the source,
+which is no longer in the skiplist. Writers to one directory still hold the directory lock.
+
+In cluster mode the two node keys are in different hash slots, unless the prefix holds a hash tag, see HashTaggedPrefix,
+and with WithNodeShards a node is many keys, so there the three commands are sent one after the other.
+*/
+
+// mergeNodesScript moves the members of KEYS[1] into KEYS[2] and deletes KEYS[1],
+// adding at most ARGV[1] members per ZADD, and returns how many members were new to KEYS[2]
+var mergeNodesScript = redis.NewScript(`
+local names = redis.call('ZRANGE', KEYS[1], 0, -1)
+local chunk = tonumber(ARGV[1])
+local added = 0
+for i = 1, #names, chunk do
+	local args = {}
+	for j = i, math.min(i + chunk - 1, #names) do
+		args[#args + 1] = 0
+		args[#args + 1] = names[j]
+	end
+	added = added + redis.call('ZADD', KEYS[2], 'NX', unpack(args))
+end
+redis.call('DEL', KEYS[1])
+return added
+`)
+
+// mergeNodeMembers moves the names of source into target and deletes the member set of source
+func (nl *ItemList) mergeNodeMembers(ctx context.Context, target, source *skiplist.SkipListElementReference) error {
+	if nl.isCluster() && !nl.isColocated() || nl.nodeShardLength > 0 {
+		names, err := nl.nodeRangeBeforeExclusive(ctx, source, "")
+		if err != nil {
+			return err
+		}
+		if len(names) > 0 {
+			if err := nl.nodeAddMember(ctx, target, names...); err != nil {
+				return err
+			}
+		}
+		return nl.nodeDelete(ctx, source)
+	}
+	sourceKey, targetKey := nl.nodeKey(source.ElementPointer), nl.nodeKey(target.ElementPointer)
+	added, err := mergeNodesScript.Run(ctx, nl.client, []string{sourceKey, targetKey}, nl.addChunkSize()).Int64()
+	if err != nil {
+		return wrapNodeKeyError(sourceKey, err)
+	}
+	if err := nl.adjustNodeCount(ctx, target, added); err != nil {
+		return err
+	}
+	return nl.deleteNodeCount(ctx, source)
+}
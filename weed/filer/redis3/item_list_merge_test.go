@@ -0,0 +1,55 @@
+package redis3
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// beforeCommand calls fn once, before the first command with the given name
+type beforeCommand struct {
+	name string
+	once sync.Once
+	fn   func()
+}
+
+func (b *beforeCommand) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (b *beforeCommand) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if cmd.Name() == b.name {
+			b.once.Do(b.fn)
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (b *beforeCommand) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestDeleteNameMergeKeepsConcurrentAdd(t *testing.T) {
+	nl, server := newTestItemList(t, 4)
+	_, err := nl.itemAdd(context.Background(), []byte("a"), 0, "a", "b")
+	assert.NoError(t, err)
+	nextId, err := nl.itemAdd(context.Background(), []byte("m"), 0, "m")
+	assert.NoError(t, err)
+
+	// another writer adds to the next node as the merge starts
+	writer := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		writer.Close()
+	})
+	nl.client.AddHook(&beforeCommand{name: "evalsha", fn: func() {
+		assert.NoError(t, writer.ZAdd(context.Background(), nl.nodeKey(nextId), redis.Z{Member: "n"}).Err())
+	}})
+	assert.NoError(t, nl.DeleteName(context.Background(), "b"))
+	assert.Equal(t, 1, countNodes(t, nl))
+	assert.Equal(t, []string{"a", "m", "n"}, listAllNames(t, nl, ""))
+	assert.False(t, server.Exists(nl.nodeKey(nextId)))
+}
@@ -0,0 +1,107 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+)
+
+/*
+A mirror is a copy of the list in a second Redis, e.g. while migrating to it.
+Each write is applied to the primary as usual, then to the mirror, where the list header is read from
+and saved back to "<prefix>" for every write, on top of the commands of the write itself.
+A failing mirror write is logged and counted, and the primary write still succeeds;
+SyncMirror then reconciles the names the mirror missed.
+Reads are only served by the primary, until the operator cuts over to the mirror.
+*/
+
+// loadMirror loads the list from the mirror, with the options of the primary but no callbacks
+func (nl *ItemList) loadMirror(ctx context.Context) (*ItemList, error) {
+	data, err := nl.mirrorClient.Get(ctx, nl.prefix).Bytes()
+	if err != nil && err != redis.Nil {
+		return nil, wrapRedisError(err)
+	}
+	primary := nl.skipList.ListStore.(*SkipListElementStore)
+	store := newSkipListElementStore(primary.Prefix, nl.mirrorClient)
+	store.compact = primary.compact
+	opts := append(append([]ItemListOption(nil), nl.opts...), func(mirror *ItemList) {
+		mirror.mirrorClient = nil
+		// the primary client state and its counting hook are not the mirror's
+		mirror.clientState = nil
+		mirror.countCommands = false
+		mirror.maintenanceConfig = nil
+		mirror.onSplit = nil
+		mirror.onMerge = nil
+	})
+	return LoadItemList(data, nl.prefix, nl.mirrorClient, store, nl.batchSize, opts...)
+}
+
+// saveMirror saves the list header of mirror, if changed
+func (nl *ItemList) saveMirror(ctx context.Context, mirror *ItemList) error {
+	if !mirror.HasChanges() {
+		return nil
+	}
+	return wrapRedisError(nl.mirrorClient.Set(ctx, nl.prefix, mirror.ToBytes(), 0).Err())
+}
+
+// mirror applies fn to the mirror, logging and counting a failure instead of returning it
+func (nl *ItemList) mirror(ctx context.Context, op, name string, fn func(mirror *ItemList) error) {
+	if nl.mirrorClient == nil {
+		return
+	}
+	mirror, err := nl.loadMirror(ctx)
+	if err == nil {
+		err = fn(mirror)
+	}
+	if err == nil {
+		err = nl.saveMirror(ctx, mirror)
+	}
+	if err != nil {
+		stats.FilerStoreCounter.WithLabelValues("redis3", "mirror_error").Inc()
+		glog.Warningf("mirror %s %s %s: %v", op, nl.prefix, name, err)
+	}
+}
+
+// SyncMirror makes the names of the mirror of WithMirror those of the primary, adding the names it missed
+// and removing those it should not have, found by DiffLists. Names written concurrently may be missed again,
+// so run it while writes are paused, or until it returns 0, 0, before cutting over.
+func (nl *ItemList) SyncMirror(ctx context.Context) (added, removed int, err error) {
+	if nl.mirrorClient == nil {
+		return 0, 0, fmt.Errorf("sync mirror %s: %w: no WithMirror", nl.prefix, ErrInvalidOptions)
+	}
+	if nl.readOnly {
+		return 0, 0, ErrReadOnly
+	}
+	mirror, err := nl.loadMirror(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	missing, extra, err := DiffLists(ctx, nl, mirror, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, name := range missing {
+		if err = mirror.WriteName(ctx, name); err != nil {
+			break
+		}
+		added++
+	}
+	if err == nil {
+		for _, name := range extra {
+			if err = mirror.DeleteName(ctx, name); err != nil {
+				break
+			}
+			removed++
+		}
+	}
+	if saveErr := nl.saveMirror(ctx, mirror); err == nil {
+		err = saveErr
+	}
+	if added > 0 || removed > 0 {
+		glog.V(0).Infof("sync mirror %s: added %d, removed %d names", nl.prefix, added, removed)
+	}
+	return added, removed, err
+}
@@ -0,0 +1,56 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirror(t *testing.T) {
+	mirrorServer := miniredis.RunT(t)
+	mirrorClient := redis.NewClient(&redis.Options{Addr: mirrorServer.Addr(), MaxRetries: -1})
+	t.Cleanup(func() {
+		mirrorClient.Close()
+	})
+	failures := stats.FilerStoreCounter.WithLabelValues("redis3", "mirror_error")
+	failuresBefore := testutil.ToFloat64(failures)
+	nl, _ := newTestItemList(t, 3, WithMirror(mirrorClient))
+	mirrorNames := func() []string {
+		mirror, err := nl.loadMirror(context.Background())
+		assert.NoError(t, err)
+		return listAllNames(t, mirror, "")
+	}
+
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	assert.NoError(t, nl.DeleteName(context.Background(), "c"))
+	assert.Equal(t, []string{"a", "b", "d", "e"}, mirrorNames())
+
+	// the primary keeps working while the mirror is down
+	mirrorServer.Close()
+	assert.NoError(t, nl.WriteName(context.Background(), "f"))
+	assert.NoError(t, nl.DeleteName(context.Background(), "a"))
+	assert.Equal(t, failuresBefore+2, testutil.ToFloat64(failures))
+	assert.Equal(t, []string{"b", "d", "e", "f"}, listAllNames(t, nl, ""))
+
+	assert.NoError(t, mirrorServer.Restart())
+	assert.Equal(t, []string{"a", "b", "d", "e"}, mirrorNames())
+	added, removed, err := nl.SyncMirror(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, []string{"b", "d", "e", "f"}, mirrorNames())
+	added, removed, err = nl.SyncMirror(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, added+removed)
+
+	unmirrored, _ := newTestItemList(t, 3)
+	_, _, err = unmirrored.SyncMirror(context.Background())
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
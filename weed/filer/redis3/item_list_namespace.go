@@ -0,0 +1,115 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const namespaceCanarySuffix = "canary"
+
+// SelectOnConnect returns a hook for redis.Options.OnConnect that selects db on every new connection.
+func SelectOnConnect(db int) func(ctx context.Context, cn *redis.Conn) error {
+	return func(ctx context.Context, cn *redis.Conn) error {
+		return cn.Select(ctx, db).Err()
+	}
+}
+
+func (nl *ItemList) ensureNamespace(ctx context.Context) error {
+	if !nl.namespaceCheck || nl.namespaceChecked {
+		return nil
+	}
+	if err := nl.checkNamespace(ctx); err != nil {
+		return err
+	}
+	nl.namespaceChecked = true
+	return nil
+}
+
+func (nl *ItemList) checkNamespace(ctx context.Context) error {
+	if nl.prefix == "" {
+		return fmt.Errorf("%w: empty key prefix", ErrNamespaceConflict)
+	}
+
+	if nl.checkDatabase {
+		if nl.isCluster() && nl.database != 0 {
+			return fmt.Errorf("%w: a cluster only has database 0, not %d", ErrNamespaceConflict, nl.database)
+		}
+		db := 0
+		if c, ok := nl.client.(*redis.Client); ok {
+			db = c.Options().DB
+		}
+		if db != nl.database {
+			return fmt.Errorf("%w: client uses database %d instead of %d", ErrNamespaceConflict, db, nl.database)
+		}
+	}
+
+	if !nl.readOnly {
+		if err := nl.checkCanary(ctx); err != nil {
+			return err
+		}
+	}
+
+	if !nl.namespaceStrict {
+		return nil
+	}
+	return nl.scanKeys(ctx, escapeScanPattern(nl.prefix)+"*", func(key string) error {
+		if !isItemListKeySuffix(strings.TrimPrefix(key, nl.prefix)) {
+			return fmt.Errorf("%w: foreign key %q under prefix %q", ErrNamespaceConflict, key, nl.prefix)
+		}
+		return nil
+	})
+}
+
+func (nl *ItemList) checkCanary(ctx context.Context) error {
+	canaryKey := nl.prefix + namespaceCanarySuffix
+	token := strconv.FormatInt(time.Now().UnixNano(), 36)
+	if err := nl.client.Set(ctx, canaryKey, token, time.Minute).Err(); err != nil {
+		return fmt.Errorf("write canary %s: %v", canaryKey, err)
+	}
+	value, err := nl.client.Get(ctx, canaryKey).Result()
+	if err != nil {
+		return fmt.Errorf("read canary %s: %v", canaryKey, err)
+	}
+	if value != token {
+		return fmt.Errorf("%w: canary %s was overwritten", ErrNamespaceConflict, canaryKey)
+	}
+	if err := nl.client.Del(ctx, canaryKey).Err(); err != nil {
+		return fmt.Errorf("delete canary %s: %v", canaryKey, err)
+	}
+	return nil
+}
+
+// isItemListKeySuffix tells whether a key suffix after the prefix belongs to an item list:
+// the list itself, its lock, the canary, the node counts, the checksum, the rebalance checkpoint, the pre-split nodes,
+// the directory version, skiplist elements "<id>" and member sets "<id>m".
+func isItemListKeySuffix(suffix string) bool {
+	switch suffix {
+	case "", "lock", namespaceCanarySuffix, nodeCountSuffix, checksumSuffix, rebalanceSuffix, nameScoresSuffix, listingVersionSuffix, preSplitSuffix, directoryVersionSuffix, nodeKeysSuffix:
+		return true
+	}
+	if isNodeShardKeySuffix(suffix) {
+		return true
+	}
+	suffix = strings.TrimSuffix(suffix, "m")
+	if suffix == "" {
+		return false
+	}
+	return isNodeIdOfAnyEncoding(suffix)
+}
+
+func escapeScanPattern(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,39 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemListNamespaceCheck(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{
+		Addr: server.Addr(),
+	})
+	defer client.Close()
+
+	prefix := "/ns/dir\x00"
+	nl := mustNewItemList(t, client, prefix, newSkipListElementStore(prefix, client), 3, WithNamespaceCheck(true))
+	for _, name := range []string{"a", "b", "c", "d"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	assert.False(t, server.Exists(prefix+namespaceCanarySuffix))
+
+	// a fresh list over the same keys still passes the strict check
+	nl = mustNewItemList(t, client, prefix, newSkipListElementStore(prefix, client), 3, WithNamespaceCheck(true))
+	assert.NoError(t, nl.ensureNamespace(context.Background()))
+
+	assert.NoError(t, server.Set(prefix+"foreign", "x"))
+	nl = mustNewItemList(t, client, prefix, newSkipListElementStore(prefix, client), 3, WithNamespaceCheck(true))
+	assert.ErrorIs(t, nl.WriteName(context.Background(), "e"), ErrNamespaceConflict)
+
+	nl = mustNewItemList(t, client, prefix, newSkipListElementStore(prefix, client), 3, WithNamespaceCheck(false))
+	assert.NoError(t, nl.WriteName(context.Background(), "e"))
+
+	nl = mustNewItemList(t, client, prefix, newSkipListElementStore(prefix, client), 3, WithDatabase(2))
+	assert.ErrorIs(t, nl.WriteName(context.Background(), "f"), ErrNamespaceConflict)
+}
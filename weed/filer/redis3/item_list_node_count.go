@@ -0,0 +1,85 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+With WithMaintainedNodeCount, each node size is kept in the hash "<prefix>counts", field "<id>",
+so WriteName does not need ZLEXCOUNT to decide on splits.
+
+The count and the member set are updated by separate commands. A crash in between,
+or a member set modified out of band, makes them diverge, and nodes would then be split
+too early or too late. Verify reconciles the counts against ZLEXCOUNT.
+A missing count is lazily recomputed with ZLEXCOUNT.
+*/
+
+const nodeCountSuffix = "counts"
+
+// only adjust an existing count, a missing count is recomputed from the member set
+var nodeCountIncrScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 1 then
+	return redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2])
+end
+return false
+`)
+
+func (nl *ItemList) nodeCountKey() string {
+	return nl.prefix + nodeCountSuffix
+}
+
+func (nl *ItemList) adjustNodeCount(ctx context.Context, node *skiplist.SkipListElementReference, delta int64) error {
+	if !nl.maintainNodeCount || delta == 0 {
+		return nil
+	}
+	field := strconv.FormatInt(node.ElementPointer, 10)
+	err := nodeCountIncrScript.Run(ctx, nl.client, []string{nl.nodeCountKey()}, field, delta).Err()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("adjust node count %s: %w", field, wrapRedisError(err))
+	}
+	return nil
+}
+
+func (nl *ItemList) deleteNodeCount(ctx context.Context, node *skiplist.SkipListElementReference) error {
+	if !nl.maintainNodeCount {
+		return nil
+	}
+	return wrapRedisError(nl.client.HDel(ctx, nl.nodeCountKey(), strconv.FormatInt(node.ElementPointer, 10)).Err())
+}
+
+func (nl *ItemList) maintainedNodeSize(ctx context.Context, node *skiplist.SkipListElementReference) int {
+	field := strconv.FormatInt(node.ElementPointer, 10)
+	count, err := nl.client.HGet(ctx, nl.nodeCountKey(), field).Int()
+	if err == nil {
+		return count
+	}
+	return nl.recountNode(ctx, node)
+}
+
+func (nl *ItemList) recountNode(ctx context.Context, node *skiplist.SkipListElementReference) int {
+	key := nl.nodeKey(node.ElementPointer)
+	count := nl.zLexCountAll(ctx, nl.client, key).Val()
+	nl.client.HSet(ctx, nl.nodeCountKey(), strconv.FormatInt(node.ElementPointer, 10), count)
+	return int(count)
+}
+
+func (nl *ItemList) canAddMemberByNodeCount(ctx context.Context, node *skiplist.SkipListElementReference, name string) (alreadyContains bool, nodeSize int, err error) {
+	pipe := nl.client.Pipeline()
+	key := nl.nodeKey(node.ElementPointer)
+	countOperation := pipe.HGet(ctx, nl.nodeCountKey(), strconv.FormatInt(node.ElementPointer, 10))
+	scoreOperation := pipe.ZScore(ctx, nl.memberKey(key, name), name)
+	if _, err = pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return false, 0, wrapNodeKeyError(key, err)
+	}
+	err = nil
+	alreadyContains = scoreOperation.Err() == nil
+	if nodeSize, err = countOperation.Int(); err != nil {
+		return alreadyContains, nl.recountNode(ctx, node), nil
+	}
+	return
+}
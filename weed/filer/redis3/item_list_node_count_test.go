@@ -0,0 +1,46 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemListMaintainedNodeCount(t *testing.T) {
+	nl, server := newTestItemList(t, 3, WithMaintainedNodeCount())
+
+	var expected []string
+	for i := 0; i < 40; i++ {
+		name := fmt.Sprintf("%03d", (i*17)%40)
+		expected = append(expected, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	for i := 0; i < 40; i += 3 {
+		assert.NoError(t, nl.DeleteName(context.Background(), fmt.Sprintf("%03d", i)))
+	}
+	var remaining []string
+	for i := 0; i < 40; i++ {
+		if i%3 != 0 {
+			remaining = append(remaining, fmt.Sprintf("%03d", i))
+		}
+	}
+	assert.Equal(t, remaining, listAllNames(t, nl, ""))
+
+	report, err := nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, report.NodeCountFixed)
+	assert.True(t, report.IsConsistent())
+	assert.Equal(t, int64(len(remaining)), report.Names)
+	assertItemListConsistent(t, nl)
+
+	// a diverged count is reconciled by Verify
+	first := nl.skipList.StartLevels[0]
+	server.HSet(nl.nodeCountKey(), strconv.FormatInt(first.ElementPointer, 10), "100")
+	report, err = nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{first.ElementPointer}, report.NodeCountFixed)
+	assert.Equal(t, nl.NodeSize(first), len(mustNodeNames(t, nl, first)))
+}
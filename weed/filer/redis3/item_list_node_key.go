@@ -0,0 +1,241 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// NodeKeyEncoding is how the node id is written into the member set key "<prefix><id>m".
+type NodeKeyEncoding int
+
+const (
+	// NodeKeyDecimal is the variable width decimal id, the default and the original format
+	NodeKeyDecimal NodeKeyEncoding = iota
+	// NodeKeyHex is the id as 16 hex digits
+	NodeKeyHex
+	// NodeKeyBase62 is the id as 11 base62 digits
+	NodeKeyBase62
+	// NodeKeyHashed is 8 hex digits of a hash of the id followed by the id as 16 hex digits,
+	// so sequential ids do not cluster on adjacent keys
+	NodeKeyHashed
+)
+
+var nodeKeyEncodings = []NodeKeyEncoding{NodeKeyDecimal, NodeKeyHex, NodeKeyBase62, NodeKeyHashed}
+
+const (
+	hexNodeIdWidth    = 16
+	base62NodeIdWidth = 11
+	nodeIdHashWidth   = 8
+	base62Digits      = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// nodeKeysSuffix holds the NodeKeyEncoding MigrateNodeKeys last migrated the member sets to
+const nodeKeysSuffix = "keys"
+
+const (
+	nodeKeysUnread int8 = iota
+	nodeKeysMigrated
+	nodeKeysPending
+)
+
+func (nl *ItemList) nodeKeysKey() string {
+	return nl.prefix + nodeKeysSuffix
+}
+
+// nodeKey is the member set key of node id. With WithNodeKeyEncoding, until MigrateNodeKeys has migrated the list
+// to the encoding, the member set of the previous encoding is read and written while it exists,
+// so names written before the migration stay in one member set.
+func (nl *ItemList) nodeKey(id int64) string {
+	key := nl.encodedNodeKey(id, nl.nodeKeyEncoding)
+	if !nl.nodeKeyEncodingSet || !nl.nodeKeysPending() {
+		return key
+	}
+	if cached, found := nl.nodeKeys[id]; found {
+		return cached
+	}
+	previous := nl.encodedNodeKey(id, nl.previousNodeKeyEncoding)
+	exists, err := nl.client.Exists(context.Background(), previous).Result()
+	if err != nil {
+		return key
+	}
+	if exists > 0 {
+		key = previous
+	}
+	if nl.nodeKeys == nil {
+		nl.nodeKeys = make(map[int64]string)
+	}
+	nl.nodeKeys[id] = key
+	return key
+}
+
+func (nl *ItemList) encodedNodeKey(id int64, encoding NodeKeyEncoding) string {
+	return nl.prefix + encodeNodeId(id, encoding) + "m"
+}
+
+// nodeKeysPending reads once whether the member sets are still to be migrated to the configured encoding;
+// a list without a migration recorded has the original decimal keys
+func (nl *ItemList) nodeKeysPending() bool {
+	if nl.nodeKeysState == nodeKeysUnread {
+		value, err := nl.client.Get(context.Background(), nl.nodeKeysKey()).Int()
+		switch {
+		case err == redis.Nil:
+			nl.previousNodeKeyEncoding = NodeKeyDecimal
+		case err != nil:
+			// assume pending, the previous keys are only looked up
+			nl.previousNodeKeyEncoding = NodeKeyDecimal
+			return nl.nodeKeyEncoding != NodeKeyDecimal
+		default:
+			nl.previousNodeKeyEncoding = NodeKeyEncoding(value)
+		}
+		nl.nodeKeysState = nodeKeysMigrated
+		if nl.previousNodeKeyEncoding != nl.nodeKeyEncoding {
+			nl.nodeKeysState = nodeKeysPending
+		}
+	}
+	return nl.nodeKeysState == nodeKeysPending
+}
+
+func encodeNodeId(id int64, encoding NodeKeyEncoding) string {
+	switch encoding {
+	case NodeKeyHex:
+		return fmt.Sprintf("%016x", uint64(id))
+	case NodeKeyHashed:
+		return fmt.Sprintf("%08x%016x", nodeIdHash(id), uint64(id))
+	case NodeKeyBase62:
+		var b [base62NodeIdWidth]byte
+		n := uint64(id)
+		for i := len(b) - 1; i >= 0; i-- {
+			b[i] = base62Digits[n%62]
+			n /= 62
+		}
+		return string(b[:])
+	}
+	return strconv.FormatInt(id, 10)
+}
+
+func decodeNodeId(s string, encoding NodeKeyEncoding) (int64, bool) {
+	switch encoding {
+	case NodeKeyHex:
+		if len(s) != hexNodeIdWidth {
+			return 0, false
+		}
+		id, err := strconv.ParseUint(s, 16, 64)
+		return int64(id), err == nil
+	case NodeKeyHashed:
+		if len(s) != nodeIdHashWidth+hexNodeIdWidth {
+			return 0, false
+		}
+		id, ok := decodeNodeId(s[nodeIdHashWidth:], NodeKeyHex)
+		if !ok || s[:nodeIdHashWidth] != fmt.Sprintf("%08x", nodeIdHash(id)) {
+			return 0, false
+		}
+		return id, true
+	case NodeKeyBase62:
+		if len(s) != base62NodeIdWidth {
+			return 0, false
+		}
+		var id uint64
+		for i := 0; i < len(s); i++ {
+			digit := strings.IndexByte(base62Digits, s[i])
+			if digit < 0 {
+				return 0, false
+			}
+			id = id*62 + uint64(digit)
+		}
+		return int64(id), true
+	}
+	id, err := strconv.ParseInt(s, 10, 64)
+	return id, err == nil
+}
+
+// isNodeIdOfAnyEncoding tells whether s is a node id in one of the encodings
+func isNodeIdOfAnyEncoding(s string) bool {
+	for _, encoding := range nodeKeyEncodings {
+		if _, ok := decodeNodeId(s, encoding); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeIdHash(id int64) uint32 {
+	h := fnv.New32a()
+	var b [8]byte
+	for i := range b {
+		b[i] = byte(uint64(id) >> (8 * i))
+	}
+	h.Write(b[:])
+	return h.Sum32()
+}
+
+// MigrateNodeKeys renames the member sets still using another encoding to the configured NodeKeyEncoding,
+// e.g. after adding WithNodeKeyEncoding to an existing list, or back to decimal after removing it.
+// A member set of the configured encoding which already exists is merged with the old one.
+// Once done, the encoding is recorded, and until then lists with WithNodeKeyEncoding also read the previous keys,
+// so also run it once on a new list. Without WithNodeKeyEncoding the keys are read as decimal only,
+// so go back to decimal with WithNodeKeyEncoding(NodeKeyDecimal) until this has run.
+// Hold the directory lock while it runs. In a cluster, the old and new keys must hash to the same slot.
+func (nl *ItemList) MigrateNodeKeys(ctx context.Context) (migrated int, err error) {
+	if nl.readOnly {
+		return 0, ErrReadOnly
+	}
+	if nl.nodeShardLength > 0 {
+		if nl.nodeKeyEncoding == NodeKeyDecimal {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("migrate %s: sharded nodes are not supported", nl.prefix)
+	}
+	err = nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		newKey := nl.encodedNodeKey(node.Id, nl.nodeKeyEncoding)
+		for _, encoding := range nodeKeyEncodings {
+			if encoding == nl.nodeKeyEncoding {
+				continue
+			}
+			oldKey := nl.encodedNodeKey(node.Id, encoding)
+			exists, err := nl.client.Exists(ctx, oldKey).Result()
+			if err != nil {
+				return false, fmt.Errorf("read %s: %w", oldKey, wrapRedisError(err))
+			}
+			if exists == 0 {
+				continue
+			}
+			renamed, err := nl.client.RenameNX(ctx, oldKey, newKey).Result()
+			if err != nil {
+				return false, fmt.Errorf("rename %s: %w", oldKey, wrapRedisError(err))
+			}
+			if !renamed {
+				// names were written to the new key before the migration, keep both halves
+				_, err = nl.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+					pipe.ZUnionStore(ctx, newKey, &redis.ZStore{Keys: []string{newKey, oldKey}, Aggregate: "MIN"})
+					pipe.Del(ctx, oldKey)
+					// a missing count is recomputed from the merged member set
+					pipe.HDel(ctx, nl.nodeCountKey(), strconv.FormatInt(node.Id, 10))
+					return nil
+				})
+				if err != nil {
+					return false, fmt.Errorf("merge %s into %s: %w", oldKey, newKey, wrapRedisError(err))
+				}
+			}
+			migrated++
+		}
+		return true, nil
+	})
+	if err != nil {
+		return migrated, err
+	}
+	if err := nl.client.Set(ctx, nl.nodeKeysKey(), int(nl.nodeKeyEncoding), 0).Err(); err != nil {
+		return migrated, fmt.Errorf("record %s: %w", nl.nodeKeysKey(), wrapRedisError(err))
+	}
+	nl.nodeKeysState = nodeKeysMigrated
+	nl.previousNodeKeyEncoding = nl.nodeKeyEncoding
+	nl.nodeKeys = nil
+	glog.V(0).Infof("migrate %s: renamed %d node keys", nl.prefix, migrated)
+	return migrated, nil
+}
@@ -0,0 +1,134 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeKeyEncoding(t *testing.T) {
+	for _, id := range []int64{1, 62, 1<<62 + 12345, 1<<63 - 1} {
+		for _, encoding := range nodeKeyEncodings {
+			decoded, ok := decodeNodeId(encodeNodeId(id, encoding), encoding)
+			assert.True(t, ok)
+			assert.Equal(t, id, decoded)
+		}
+		assert.Len(t, encodeNodeId(id, NodeKeyHex), hexNodeIdWidth)
+		assert.Len(t, encodeNodeId(id, NodeKeyBase62), base62NodeIdWidth)
+		assert.Len(t, encodeNodeId(id, NodeKeyHashed), nodeIdHashWidth+hexNodeIdWidth)
+	}
+	_, ok := decodeNodeId("checksu", NodeKeyBase62)
+	assert.False(t, ok)
+	_, ok = decodeNodeId("00000000"+encodeNodeId(1, NodeKeyHex), NodeKeyHashed)
+	assert.False(t, ok)
+	assert.NotEqual(t, encodeNodeId(1, NodeKeyHashed)[:nodeIdHashWidth], encodeNodeId(2, NodeKeyHashed)[:nodeIdHashWidth])
+
+	// migrate an existing decimal list
+	nl, server := newTestItemList(t, 3)
+	var expected []string
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("name%02d", i)
+		expected = append(expected, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	base62 := mustNewItemList(t, nl.client, nl.prefix, nl.skipList.ListStore, 3, WithNodeKeyEncoding(NodeKeyBase62), WithNamespaceCheck(true))
+	base62.skipList = nl.skipList
+	migrated, err := base62.MigrateNodeKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, migrated)
+	for _, key := range server.Keys() {
+		if strings.HasSuffix(key, "m") {
+			assert.Len(t, key, len(nl.prefix)+base62NodeIdWidth+1, key)
+		}
+	}
+	assert.NoError(t, base62.WriteName(context.Background(), "name10"))
+	expected = append(expected, "name10")
+	assert.Equal(t, expected, listAllNames(t, base62, ""))
+	assertItemListConsistent(t, base62)
+
+	// migrating again is a no-op
+	migrated, err = base62.MigrateNodeKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, migrated)
+
+	// migrate from base62 to hashed keys
+	hashed := withOptions(base62, WithNodeKeyEncoding(NodeKeyHashed))
+	migrated, err = hashed.MigrateNodeKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, migrated)
+	for _, key := range server.Keys() {
+		if strings.HasSuffix(key, "m") {
+			assert.Len(t, key, len(nl.prefix)+nodeIdHashWidth+hexNodeIdWidth+1, key)
+		}
+	}
+	assert.Equal(t, expected, listAllNames(t, hashed, ""))
+	assertItemListConsistent(t, hashed)
+}
+
+func TestMigrateNodeKeysAfterWrites(t *testing.T) {
+	nl, server := newTestItemList(t, 3)
+	var expected []string
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("name%02d", i)
+		expected = append(expected, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+
+	// writes after adding the option, before migrating, go to the decimal keys
+	hex := mustNewItemList(t, nl.client, nl.prefix, nl.skipList.ListStore, 3, WithNodeKeyEncoding(NodeKeyHex))
+	hex.skipList = nl.skipList
+	assert.NoError(t, hex.WriteName(context.Background(), "name10"))
+	expected = append(expected, "name10")
+	assert.Equal(t, expected, listAllNames(t, hex, ""))
+	for _, key := range server.Keys() {
+		if strings.HasSuffix(key, "m") {
+			assert.NotContains(t, key, encodeNodeId(hex.skipList.StartLevels[0].ElementPointer, NodeKeyHex), key)
+		}
+	}
+
+	// a new key written next to the old one, e.g. by an older version, is merged
+	first := hex.skipList.StartLevels[0].ElementPointer
+	assert.NoError(t, nl.client.ZAdd(context.Background(), hex.encodedNodeKey(first, NodeKeyHex), redis.Z{Member: "name00a"}).Err())
+	expected = append(expected[:1], append([]string{"name00a"}, expected[1:]...)...)
+
+	migrated, err := hex.MigrateNodeKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, migrated)
+	assert.False(t, server.Exists(hex.encodedNodeKey(first, NodeKeyDecimal)))
+	assert.Equal(t, expected, listAllNames(t, hex, ""))
+	assertItemListConsistent(t, hex)
+
+	// the migration is recorded, a list loaded afterwards only reads the hex keys
+	reloaded := mustNewItemList(t, nl.client, nl.prefix, nl.skipList.ListStore, 3, WithNodeKeyEncoding(NodeKeyHex))
+	reloaded.skipList = nl.skipList
+	assert.NoError(t, reloaded.WriteName(context.Background(), "name11"))
+	expected = append(expected, "name11")
+	assert.Equal(t, expected, listAllNames(t, reloaded, ""))
+	assert.False(t, reloaded.nodeKeysPending())
+	assert.Empty(t, reloaded.nodeKeys)
+}
+
+func BenchmarkNodeKeyEncoding(b *testing.B) {
+	for _, encoding := range nodeKeyEncodings {
+		b.Run(fmt.Sprintf("encoding%d", encoding), func(b *testing.B) {
+			server := miniredis.RunT(b)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			store := newSkipListElementStore("/bench", client)
+			nl, err := newItemList(client, "/bench", store, 100, WithNodeKeyEncoding(encoding))
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := nl.WriteName(context.Background(), fmt.Sprintf("name%09d", i)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+The members of a node set are always sorted by their bytes, so names are only listed out of their intended order
+when they were stored under an order key which does not preserve it, e.g. WithOrderKey with a wrong function,
+or before WithOrderKey was set. RebuildNodeOrder stores each such name again under the current options.
+A stored name is decoded as its part after the order key separator, when it has one, since names never hold NUL,
+so names stored with any order key, or with none, are recovered.
+*/
+
+// storedRename is a name stored as from which the current options store as to
+type storedRename struct {
+	from, to string
+}
+
+// RebuildNodeOrder stores again each name whose stored member differs from the member the current
+// WithOrderKey and WithNamePrefix make of it, returning how many it rewrote. Every node is read once and checked
+// in memory. A rewritten name within the range of its node is replaced in place, rekeying the node if it was its key,
+// and any other is removed and written again, as DeleteName and WriteName do. Name scores and the checksum follow.
+// Running it again is a no-op. Persist the list header afterwards, as after WriteName.
+func (nl *ItemList) RebuildNodeOrder(ctx context.Context) (rewritten int, err error) {
+	if nl.readOnly {
+		return 0, ErrReadOnly
+	}
+	if nl.nameCipher != nil {
+		return 0, fmt.Errorf("rebuild order of %s: %w: names are encrypted", nl.prefix, ErrUnsupported)
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return 0, err
+	}
+
+	var moved []storedRename
+	first := true
+	var node *skiplist.SkipListElement
+	if ref := nl.skipList.StartLevels[0]; ref != nil {
+		if node, err = nl.loadElement(ctx, ref.ElementPointer); err != nil {
+			return 0, err
+		}
+	}
+	guard := nl.newChainGuard(ctx)
+	for node != nil {
+		if err := guard.step(); err != nil {
+			return rewritten, err
+		}
+		next, err := nl.loadNextNode(ctx, node)
+		if err != nil {
+			return rewritten, err
+		}
+		members, err := nl.nodeRangeInclusiveAfter(ctx, node.Reference(), "")
+		if err != nil {
+			return rewritten, err
+		}
+		var inPlace []storedRename
+		for _, member := range members {
+			to, ok := nl.reencodedName(member)
+			if !ok || to == member {
+				continue
+			}
+			// the first node also holds the names before its key
+			inRange := (first || to >= string(node.Key)) && (next == nil || to < string(next.Key))
+			if inRange {
+				inPlace = append(inPlace, storedRename{member, to})
+			} else {
+				moved = append(moved, storedRename{member, to})
+			}
+		}
+		if len(inPlace) > 0 {
+			if err := nl.renameInNode(ctx, node, inPlace); err != nil {
+				return rewritten, err
+			}
+			rewritten += len(inPlace)
+		}
+		first = false
+		node = next
+	}
+
+	for _, rename := range moved {
+		removed, err := nl.deleteName(ctx, rename.from)
+		if err != nil {
+			return rewritten, err
+		}
+		if !removed {
+			continue
+		}
+		if _, err := nl.writeName(ctx, rename.to); err != nil {
+			return rewritten, err
+		}
+		if err := nl.renameStoredMetadata(ctx, rename); err != nil {
+			return rewritten, err
+		}
+		rewritten++
+	}
+	if rewritten > 0 {
+		glog.V(0).Infof("rebuild order of %s: rewrote %d names, %d moved to other nodes", nl.prefix, rewritten, len(moved))
+	}
+	return rewritten, nil
+}
+
+// reencodedName is the member the current options store for the name stored as member,
+// false for a member of another name prefix
+func (nl *ItemList) reencodedName(member string) (string, bool) {
+	if !strings.HasPrefix(member, nl.namePrefix) {
+		return "", false
+	}
+	name := member[len(nl.namePrefix):]
+	if i := strings.Index(name, orderKeySeparator); i >= 0 {
+		name = name[i+len(orderKeySeparator):]
+	}
+	if name == "" || nl.checkOrderKey(name) != nil {
+		return "", false
+	}
+	return nl.storedName(name), true
+}
+
+// renameInNode replaces the members of node, then rekeys it to its smallest member
+func (nl *ItemList) renameInNode(ctx context.Context, node *skiplist.SkipListElement, renames []storedRename) error {
+	ref := node.Reference()
+	for _, rename := range renames {
+		if err := nl.nodeAddMember(ctx, ref, rename.to); err != nil {
+			return err
+		}
+		if err := nl.nodeDeleteMember(ctx, ref, rename.from); err != nil {
+			return err
+		}
+		if err := nl.renameStoredMetadata(ctx, rename); err != nil {
+			return err
+		}
+	}
+	minName, err := nl.nodeMin(ctx, ref)
+	if err != nil {
+		return err
+	}
+	if minName == "" || minName == string(node.Key) {
+		return nil
+	}
+	end, err := nl.beginStructuralChange(ctx)
+	if err != nil {
+		return err
+	}
+	defer end()
+	if err := nl.deleteNodeElement(ctx, ref); err != nil {
+		return err
+	}
+	_, err = nl.itemAdd(ctx, []byte(minName), node.Id)
+	return err
+}
+
+// renameStoredMetadata moves the name score and the checksum of a rewritten name
+func (nl *ItemList) renameStoredMetadata(ctx context.Context, rename storedRename) error {
+	if err := nl.adjustChecksum(ctx, rename.from, -1); err != nil {
+		return err
+	}
+	if err := nl.adjustChecksum(ctx, rename.to, 1); err != nil {
+		return err
+	}
+	if !nl.nameScores {
+		return nil
+	}
+	score, err := nl.client.HGet(ctx, nl.nameScoresKey(), rename.from).Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("move score of %s: %w", rename.from, wrapRedisError(err))
+	}
+	if err := nl.client.HSet(ctx, nl.nameScoresKey(), rename.to, score).Err(); err != nil {
+		return fmt.Errorf("move score of %s: %w", rename.from, wrapRedisError(err))
+	}
+	return nl.deleteNameScore(ctx, rename.from)
+}
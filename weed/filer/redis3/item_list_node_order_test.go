@@ -0,0 +1,41 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebuildNodeOrder(t *testing.T) {
+	// names stored under an order key which does not sort numbers, as if mistakenly configured
+	wrong := func(name string) string { return name }
+	nl, _ := newTestItemList(t, 3, WithOrderKey(wrong), WithNameScores(), WithChecksum())
+	names := []string{"1", "2", "3", "10", "20", "30", "100", "200", "b", "a"}
+	for i, name := range names {
+		assert.NoError(t, nl.UpsertName(context.Background(), name, float64(i)))
+	}
+	assert.Equal(t, []string{"1", "10", "100", "2", "20", "200", "3", "30", "a", "b"}, listAllNames(t, nl, ""))
+
+	fixed := mustNewItemList(t, nl.client, nl.prefix, nl.skipList.ListStore, 3, WithOrderKey(Int64OrderKey), WithNameScores(), WithChecksum())
+	fixed.skipList = nl.skipList
+	rewritten, err := fixed.RebuildNodeOrder(context.Background())
+	assert.NoError(t, err)
+	// the order key of every name changed
+	assert.Equal(t, len(names), rewritten)
+	assert.Equal(t, []string{"1", "2", "3", "10", "20", "30", "100", "200", "a", "b"}, listAllNames(t, fixed, ""))
+	assertItemListConsistent(t, fixed)
+	for i, name := range names {
+		score, found, err := fixed.NameScore(context.Background(), name)
+		assert.NoError(t, err)
+		assert.True(t, found, name)
+		assert.Equal(t, float64(i), score, name)
+	}
+	ok, err := fixed.VerifyChecksum(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	rewritten, err = fixed.RebuildNodeOrder(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, rewritten)
+}
@@ -0,0 +1,190 @@
+package redis3
+
+import (
+	"context"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/*
+With WithNodeShards(n), the members of a node are spread over sub-sets by their first n bytes,
+so a node of a huge directory with a common name prefix is not one huge sorted set:
+
+	"<prefix><id>m"           sorted set of the shard prefixes of the node, all at score 0
+	"<prefix><id>m:<shard>"   sorted set of the names starting with shard, or equal to it if shorter
+
+All names of a shard sort between the names of the shards before and after it,
+so a lex range over the node is the concatenation of the same range over each shard, in shard order.
+Each node operation reads the shard prefixes first, then runs one pipeline over the shards,
+so it costs one more round trip than a plain sorted set, and a range reads every shard of the node.
+Shard prefixes are only added, and removed with the node, so an emptied shard is still visited.
+The skiplist, with the leading key of each node, is unchanged.
+
+WithLexFallback, MigrateNodeKeys and RebuildSkiplistFromSets do not support sharded nodes.
+*/
+
+func (nl *ItemList) nameShard(name string) string {
+	if len(name) <= nl.nodeShardLength {
+		return name
+	}
+	return name[:nl.nodeShardLength]
+}
+
+func nodeShardKey(key, shard string) string {
+	return key + ":" + shard
+}
+
+// memberKey is the sorted set holding name within the node at key
+func (nl *ItemList) memberKey(key, name string) string {
+	if nl.nodeShardLength == 0 {
+		return key
+	}
+	return nodeShardKey(key, nl.nameShard(name))
+}
+
+func (nl *ItemList) nodeShards(ctx context.Context, key string) ([]string, error) {
+	return nl.client.ZRange(ctx, key, 0, -1).Result()
+}
+
+// shardedAddMembers adds names to their shards, registering new shards first,
+// so that every stored name is in a registered shard.
+func (nl *ItemList) shardedAddMembers(ctx context.Context, key string, names []string) (added int64, err error) {
+	byShard := make(map[string][]redis.Z)
+	var shards []redis.Z
+	for _, name := range names {
+		shard := nl.nameShard(name)
+		if _, ok := byShard[shard]; !ok {
+			shards = append(shards, redis.Z{Member: shard})
+		}
+		byShard[shard] = append(byShard[shard], redis.Z{Member: name})
+	}
+	if len(shards) == 0 {
+		return 0, nil
+	}
+	if err := nl.client.ZAddNX(ctx, key, shards...).Err(); err != nil {
+		return 0, err
+	}
+	pipe := nl.client.Pipeline()
+	var cmds []*redis.IntCmd
+	for shard, members := range byShard {
+		cmds = append(cmds, nl.zAddNXChunked(ctx, pipe, nodeShardKey(key, shard), members)...)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	for _, cmd := range cmds {
+		added += cmd.Val()
+	}
+	return added, nil
+}
+
+func (nl *ItemList) shardedDelete(ctx context.Context, key string) error {
+	shards, err := nl.nodeShards(ctx, key)
+	if err != nil {
+		return err
+	}
+	keys := []string{key}
+	for _, shard := range shards {
+		keys = append(keys, nodeShardKey(key, shard))
+	}
+	pipe := nl.client.Pipeline()
+	for _, k := range keys {
+		// one DEL per key, since the shards are in different cluster slots
+		pipe.Del(ctx, k)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// shardedRange runs one lex range command on each shard, in shard order
+func (nl *ItemList) shardedRange(ctx context.Context, key string, rangeFn func(pipe redis.Pipeliner, shardKey string) redis.Cmder) ([]redis.Cmder, error) {
+	shards, err := nl.nodeShards(ctx, key)
+	if err != nil || len(shards) == 0 {
+		return nil, err
+	}
+	pipe := nl.client.Pipeline()
+	cmds := make([]redis.Cmder, len(shards))
+	for i, shard := range shards {
+		cmds[i] = rangeFn(pipe, nodeShardKey(key, shard))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+	return cmds, nil
+}
+
+func (nl *ItemList) shardedRangeByLex(ctx context.Context, key string, opt *redis.ZRangeBy) *redis.StringSliceCmd {
+	cmd := redis.NewStringSliceCmd(ctx)
+	cmds, err := nl.shardedRange(ctx, key, func(pipe redis.Pipeliner, shardKey string) redis.Cmder {
+		return pipe.ZRangeByLex(ctx, shardKey, &redis.ZRangeBy{Min: opt.Min, Max: opt.Max})
+	})
+	if err != nil {
+		cmd.SetErr(err)
+		return cmd
+	}
+	var names []string
+	for _, c := range cmds {
+		names = append(names, c.(*redis.StringSliceCmd).Val()...)
+	}
+	if opt.Offset > 0 {
+		names = names[min(int(opt.Offset), len(names)):]
+	}
+	if opt.Count > 0 && int(opt.Count) < len(names) {
+		names = names[:opt.Count]
+	}
+	cmd.SetVal(names)
+	return cmd
+}
+
+func (nl *ItemList) shardedSum(ctx context.Context, key string, rangeFn func(pipe redis.Pipeliner, shardKey string) redis.Cmder) *redis.IntCmd {
+	cmd := redis.NewIntCmd(ctx)
+	cmds, err := nl.shardedRange(ctx, key, rangeFn)
+	if err != nil {
+		cmd.SetErr(err)
+		return cmd
+	}
+	var sum int64
+	for _, c := range cmds {
+		sum += c.(*redis.IntCmd).Val()
+	}
+	cmd.SetVal(sum)
+	return cmd
+}
+
+func (nl *ItemList) shardedLexCount(ctx context.Context, key, min, max string) *redis.IntCmd {
+	return nl.shardedSum(ctx, key, func(pipe redis.Pipeliner, shardKey string) redis.Cmder {
+		return pipe.ZLexCount(ctx, shardKey, min, max)
+	})
+}
+
+func (nl *ItemList) shardedRemRangeByLex(ctx context.Context, key, min, max string) *redis.IntCmd {
+	return nl.shardedSum(ctx, key, func(pipe redis.Pipeliner, shardKey string) redis.Cmder {
+		return pipe.ZRemRangeByLex(ctx, shardKey, min, max)
+	})
+}
+
+func (nl *ItemList) shardedLexMin(ctx context.Context, key string) *redis.StringSliceCmd {
+	cmd := redis.NewStringSliceCmd(ctx)
+	cmds, err := nl.shardedRange(ctx, key, func(pipe redis.Pipeliner, shardKey string) redis.Cmder {
+		return pipe.ZRange(ctx, shardKey, 0, 0)
+	})
+	if err != nil {
+		cmd.SetErr(err)
+		return cmd
+	}
+	for _, c := range cmds {
+		// skip emptied shards
+		if names := c.(*redis.StringSliceCmd).Val(); len(names) > 0 {
+			cmd.SetVal(names)
+			return cmd
+		}
+	}
+	return cmd
+}
+
+// isNodeShardKeySuffix tells whether suffix is "<id>m:<shard>"
+func isNodeShardKeySuffix(suffix string) bool {
+	i := strings.Index(suffix, "m:")
+	return i > 0 && isNodeIdOfAnyEncoding(suffix[:i])
+}
@@ -0,0 +1,91 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeShards(t *testing.T) {
+	_, err := newItemList(nil, "/test/dir", nil, 3, WithNodeShards(2), WithLexFallback())
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	for _, maintainNodeCount := range []bool{false, true} {
+		opts := []ItemListOption{WithNodeShards(2), WithNamespaceCheck(true)}
+		if maintainNodeCount {
+			opts = append(opts, WithMaintainedNodeCount())
+		}
+		nl, server := newTestItemList(t, 4, opts...)
+		r := rand.New(rand.NewSource(1))
+		model := make(map[string]bool)
+		letters := "abc"
+		for step := 0; step < 300; step++ {
+			// short names, shorter than the shard prefix, and names sharing prefixes
+			name := ""
+			for n := 1 + r.Intn(3); n > 0; n-- {
+				name += string(letters[r.Intn(len(letters))])
+			}
+			if r.Intn(3) == 0 {
+				assert.NoError(t, nl.DeleteName(context.Background(), name))
+				delete(model, name)
+			} else {
+				assert.NoError(t, nl.WriteName(context.Background(), name))
+				model[name] = true
+			}
+			assertItemListConsistent(t, nl)
+		}
+		var expected []string
+		for name := range model {
+			expected = append(expected, name)
+		}
+		sort.Strings(expected)
+		assert.Equal(t, expected, listAllNames(t, nl, ""))
+
+		found, err := nl.ExistsMany(context.Background(), []string{expected[0], "zzz"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{expected[0]: true, "zzz": false}, found)
+
+		// members are in shard sets, the node set holds the shard prefixes
+		node := nl.skipList.StartLevels[0]
+		shards, err := server.ZMembers(nl.nodeKey(node.ElementPointer))
+		assert.NoError(t, err)
+		for _, shard := range shards {
+			assert.LessOrEqual(t, len(shard), 2)
+		}
+		assert.True(t, server.Exists(nodeShardKey(nl.nodeKey(node.ElementPointer), nl.nameShard(string(node.Key)))))
+
+		_, err = nl.MigrateNodeKeys(context.Background())
+		assert.NoError(t, err)
+		assert.NoError(t, nl.RemoteAllListElement(context.Background()))
+		assert.NoError(t, nl.AssertEmpty(context.Background()))
+	}
+}
+
+func TestAddChunkSize(t *testing.T) {
+	nl, server := newTestItemList(t, 50, WithAddChunkSize(7), WithMaintainedNodeCount())
+	assert.NoError(t, nl.WriteName(context.Background(), "name00"))
+	node := nl.skipList.StartLevels[0]
+	var names []string
+	for i := 1; i < 49; i++ {
+		names = append(names, fmt.Sprintf("name%02d", i))
+	}
+	// one name is already a member
+	names = append(names, "name00")
+	before := server.CommandCount()
+	assert.NoError(t, nl.NodeAddMember(node, names...))
+	assert.GreaterOrEqual(t, server.CommandCount()-before, 7)
+	assert.Equal(t, 49, nl.NodeSize(node))
+	assert.Len(t, mustNodeNames(t, nl, node), 49)
+	assertItemListConsistent(t, nl)
+
+	// splits move names in chunks too
+	for i := 49; i < 120; i++ {
+		assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("name%03d", i)))
+	}
+	assert.Len(t, listAllNames(t, nl, ""), 120)
+	assertItemListConsistent(t, nl)
+}
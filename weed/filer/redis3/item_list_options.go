@@ -0,0 +1,334 @@
+package redis3
+
+import (
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ItemListOption func(*ItemList)
+
+// WithNamespaceCheck makes the list verify on first use that it can write and read back
+// a canary key under its prefix, skipped for read only lists. With strict enabled, any key under the prefix that is
+// not part of an item list layout fails the check with ErrNamespaceConflict.
+func WithNamespaceCheck(strict bool) ItemListOption {
+	return func(nl *ItemList) {
+		nl.namespaceCheck = true
+		nl.namespaceStrict = strict
+	}
+}
+
+// WithDatabase makes the namespace check also verify the client is configured for database db.
+func WithDatabase(db int) ItemListOption {
+	return func(nl *ItemList) {
+		nl.namespaceCheck = true
+		nl.checkDatabase = true
+		nl.database = db
+	}
+}
+
+// WithMaintainedNodeCount keeps a per node member count, so sizes are read without ZLEXCOUNT.
+// See item_list_node_count.go for the consistency trade-off.
+func WithMaintainedNodeCount() ItemListOption {
+	return func(nl *ItemList) {
+		nl.maintainNodeCount = true
+	}
+}
+
+// WithReadOnly makes all mutations fail with ErrReadOnly without touching Redis,
+// e.g. when listing from a snapshot or a replica.
+func WithReadOnly() ItemListOption {
+	return func(nl *ItemList) {
+		nl.readOnly = true
+	}
+}
+
+// WithMaxListingNodes caps the number of nodes one listing scans, 0 means unlimited.
+// With tiny batches, the node count of a listing can explode regardless of the name limit.
+func WithMaxListingNodes(maxNodes int) ItemListOption {
+	return func(nl *ItemList) {
+		nl.maxListingNodes = maxNodes
+	}
+}
+
+// WithWriteDeduplication makes re-adding a name written within window a no-op without Redis calls,
+// remembering at most maxSize names. DeleteName of a name forgets it, so a recreate is not masked.
+// Changes made by other ItemList instances are not seen, so keep the window short.
+func WithWriteDeduplication(window time.Duration, maxSize int) ItemListOption {
+	return func(nl *ItemList) {
+		nl.recentWrites = newRecentNames(window, maxSize)
+	}
+}
+
+// WithWriteBuffer makes WriteName buffer names, up to maxNames for at most window, and write the names
+// heading for the same node together, with at most one split per node, trading latency and durability for throughput.
+// Buffered names are not listed until flushed, by a full or expired buffer or by Flush, and are lost if not flushed.
+// A list loaded per write, as by the filer store, gains nothing from it.
+func WithWriteBuffer(window time.Duration, maxNames int) ItemListOption {
+	return func(nl *ItemList) {
+		nl.writeBuffer = newWriteBuffer(window, max(maxNames, 1))
+	}
+}
+
+// WithClock replaces the real time, e.g. with a fake clock for tests.
+func WithClock(clock Clock) ItemListOption {
+	return func(nl *ItemList) {
+		nl.clock = clock
+	}
+}
+
+// WithChecksum keeps a rolling checksum of all names, updated with one extra command per added or removed name,
+// so VerifyChecksum can detect member sets changed out of band. It detects tampering, it does not prevent it.
+func WithChecksum() ItemListOption {
+	return func(nl *ItemList) {
+		nl.checksum = true
+	}
+}
+
+// WithOnSplit calls fn synchronously after WriteName splits node oldNodeId at pivot,
+// moving part of its names to the new node newNodeId. fn must not call back into the ItemList.
+func WithOnSplit(fn func(oldNodeId, newNodeId int64, pivot string)) ItemListOption {
+	return func(nl *ItemList) {
+		nl.onSplit = fn
+	}
+}
+
+// WithOnMerge calls fn synchronously after DeleteName merges node removedNodeId into keptNodeId.
+// fn must not call back into the ItemList.
+func WithOnMerge(fn func(keptNodeId, removedNodeId int64)) ItemListOption {
+	return func(nl *ItemList) {
+		nl.onMerge = fn
+	}
+}
+
+// WithCommandStats counts and times the commands of the client, and its failed commands by type, reported by Stats
+// and in the filer store request metric. The counting hook is added to the client once per ClientState,
+// and counts for every user of the client, so share one with WithClientState.
+func WithCommandStats() ItemListOption {
+	return func(nl *ItemList) {
+		nl.countCommands = true
+	}
+}
+
+// WithClientState shares state with the other lists of the same client, see item_list_client_state.go,
+// instead of keeping it per list.
+func WithClientState(state *ClientState) ItemListOption {
+	return func(nl *ItemList) {
+		nl.clientState = state
+	}
+}
+
+// WithNamePrefix stores each name with namePrefix prepended, so several logical directories can share one list.
+// Listings start at the prefix, strip it, and end at the first stored name without it,
+// since all such names sort after the prefixed ones, and belong to the other logical directories.
+func WithNamePrefix(namePrefix string) ItemListOption {
+	return func(nl *ItemList) {
+		nl.namePrefix = namePrefix
+	}
+}
+
+// WithInsertDirection tunes how full nodes are split for the expected insertion order.
+func WithInsertDirection(direction InsertDirection) ItemListOption {
+	return func(nl *ItemList) {
+		nl.insertDirection = direction
+	}
+}
+
+// WithLexFallback probes once per ClientState whether ZLEXCOUNT is supported, and otherwise
+// falls back to ZCARD and ZRANGE with filtering in Go, for proxies without the lex commands.
+// The fallback reads whole nodes to list, split or merge them, so keep batches small.
+func WithLexFallback() ItemListOption {
+	return func(nl *ItemList) {
+		nl.probeLex = true
+	}
+}
+
+// WithNodeKeyEncoding writes the node ids of member set keys in a fixed width encoding,
+// or with NodeKeyHashed, behind a hash of the id to spread keys of sequential ids.
+// Existing lists keep their previous keys, which are still read and written, until MigrateNodeKeys renames them.
+func WithNodeKeyEncoding(encoding NodeKeyEncoding) ItemListOption {
+	return func(nl *ItemList) {
+		nl.nodeKeyEncoding = encoding
+		nl.nodeKeyEncodingSet = true
+		nl.nodeKeysState = nodeKeysUnread
+		nl.nodeKeys = nil
+	}
+}
+
+// WithOrderKey stores each name behind orderKey(name), so names are listed in the order of their order keys,
+// and by name among equal order keys. For example, orderKey can return the extension or a trailing timestamp,
+// zero padded to sort as a string. orderKey must be deterministic, must not contain a NUL byte,
+// and must be set whenever the list is opened, from its creation on, since existing names are not re-keyed.
+// ListNames and Iterator take a startFrom name, which starts at its position in this order.
+func WithOrderKey(orderKey func(name string) string) ItemListOption {
+	return func(nl *ItemList) {
+		nl.orderKey = orderKey
+	}
+}
+
+// WithCaseInsensitive lists names ignoring case, like WithOrderKey(strings.ToLower), and applies policy
+// when WriteName adds a name differing only in case from a stored one. Other operations take names as stored,
+// so DeleteName and ExistsMany need the stored case.
+func WithCaseInsensitive(policy NameCollisionPolicy) ItemListOption {
+	return func(nl *ItemList) {
+		nl.orderKey = strings.ToLower
+		nl.caseInsensitive = true
+		nl.collisionPolicy = policy
+	}
+}
+
+// WithNameCipher encrypts the names stored in Redis with cipher, decrypting them when listed.
+// Names are then listed out of order, see NewDeterministicNameCipher for what it leaks and supports.
+// It can not be combined with WithOrderKey or WithCaseInsensitive, whose order keys would be stored readable.
+func WithNameCipher(cipher NameCipher) ItemListOption {
+	return func(nl *ItemList) {
+		nl.nameCipher = cipher
+	}
+}
+
+// WithSafeMode makes WriteName fail with an OverCapacityError instead of splitting a node found over batchSize,
+// e.g. left by a failed split, until SplitOversizedNodes repairs it. Only the node a write would split is checked.
+// A skiplist with a first node but no last node then fails with ErrInconsistentSkiplist, instead of being walked.
+// Each refused write is counted in the filer store request metric, with type "safe_mode_blocked".
+func WithSafeMode() ItemListOption {
+	return func(nl *ItemList) {
+		nl.safeMode = true
+	}
+}
+
+// WithMirror makes WriteName and DeleteName also apply to the same list in a second Redis, the mirror,
+// e.g. to migrate to it without downtime. A failing mirror write is logged and counted in the filer store
+// request metric, with type "mirror_error", without failing the write. Reads still use the primary.
+// SyncMirror reconciles the names the mirror missed. The list must use the redis list store.
+func WithMirror(client redis.UniversalClient) ItemListOption {
+	return func(nl *ItemList) {
+		nl.mirrorClient = client
+	}
+}
+
+// WithMaintenance starts a goroutine verifying the list every config.Interval, and with config.Compact compacting it,
+// until Close. Each run loads the list from its header in Redis, and saves the header back after compacting.
+// A list header kept in memory does not see the compaction until loaded again. Only one run at a time per list runs in a process.
+// Runs are logged, and counted in the filer store request metric with types "maintenance_run", "maintenance_error"
+// and "maintenance_compact", by nodes removed. A zero interval disables it. Compact without config.Lock is ErrInvalidOptions.
+// The goroutine starts when the list is created or loaded, and runs until Close, so Close every list given this option;
+// a list loaded per operation, as the store does, would leave a goroutine behind each time.
+func WithMaintenance(config MaintenanceConfig) ItemListOption {
+	return func(nl *ItemList) {
+		if config.Interval > 0 {
+			nl.maintenanceConfig = &config
+		}
+	}
+}
+
+// WithTracer records a span for each WriteName, DeleteName and ListNames, with the name, the node written
+// and, with WithCommandStats, the round trips. Without it, no span is created.
+func WithTracer(tracer trace.Tracer) ItemListOption {
+	return func(nl *ItemList) {
+		nl.tracer = tracer
+	}
+}
+
+// WithSlowOperationLog logs each WriteName, DeleteName and ListNames taking threshold or more, with its details
+// as WithTracer records them, and counts it in the filer store request metric. It counts round trips as WithCommandStats.
+func WithSlowOperationLog(threshold time.Duration) ItemListOption {
+	return func(nl *ItemList) {
+		nl.slowOperationThreshold = threshold
+		nl.countCommands = true
+	}
+}
+
+// WithListingPrefetch makes ListNames read up to depth nodes ahead concurrently while the visitor
+// consumes the current node, keeping the order. Up to depth node reads can be wasted when the visitor stops early.
+func WithListingPrefetch(depth int) ItemListOption {
+	return func(nl *ItemList) {
+		nl.prefetchDepth = depth
+	}
+}
+
+// WithNameScores keeps a score per name for UpsertName, stored outside the member sets.
+// DeleteName of an existing name then costs one more command.
+func WithNameScores() ItemListOption {
+	return func(nl *ItemList) {
+		nl.nameScores = true
+	}
+}
+
+// WithNewMinimumInNewNode makes a name sorting before all names always start a new node.
+// By default, it is merged into the first node if that has room, which re-keys the first node,
+// costing a skiplist delete and insert, but keeps descending inserts from creating a node per name.
+// New nodes are cheaper to write, at the cost of more nodes, until later writes fill them.
+func WithNewMinimumInNewNode() ItemListOption {
+	return func(nl *ItemList) {
+		nl.newMinimumInNewNode = true
+	}
+}
+
+// WithSaneBatchSize sets the batch sizes accepted without a warning, 2 to 1000000 by default.
+// Tiny batches make a node per name, and huge batches make node reads expensive and splits rare.
+func WithSaneBatchSize(min, max int) ItemListOption {
+	return func(nl *ItemList) {
+		nl.minSaneBatchSize = min
+		nl.maxSaneBatchSize = max
+	}
+}
+
+// WithMaxChainNodes bounds the nodes of one walk over the list, failing with ErrPossibleCycle beyond it,
+// so a corrupted store linking the nodes into a cycle does not hang the walk. See item_list_chain.go for the default.
+func WithMaxChainNodes(maxNodes int) ItemListOption {
+	return func(nl *ItemList) {
+		nl.maxChainNodes = maxNodes
+	}
+}
+
+// WithNodeShards spreads the members of each node over sorted sets by their first length bytes,
+// keeping each Redis key small for huge nodes. Node operations cost one more round trip.
+// See item_list_node_shards.go for the layout and the unsupported features.
+func WithNodeShards(length int) ItemListOption {
+	return func(nl *ItemList) {
+		nl.nodeShardLength = length
+	}
+}
+
+// WithListingVersionCheck makes listings fail with ErrConcurrentModification when nodes were split,
+// merged or removed meanwhile, so the caller can retry, at the cost of two more commands per listing
+// and two per structural change. See item_list_version.go.
+func WithListingVersionCheck() ItemListOption {
+	return func(nl *ItemList) {
+		nl.listingVersionCheck = true
+	}
+}
+
+// WithAddChunkSize bounds the members of one ZADD when many names are added to a node at once,
+// e.g. by splits, merges and bulk loads. They are sent in chunks within one pipeline, 500 by default.
+func WithAddChunkSize(members int) ItemListOption {
+	return func(nl *ItemList) {
+		nl.maxAddChunk = members
+	}
+}
+
+// WithNodeReuseCheck checks a node id reused to re-key a node, e.g. passed to ItemAdd, failing with ErrNodeIdInUse
+// when the id still holds another node. It costs one GET and one ZRANGEBYLEX per re-key.
+func WithNodeReuseCheck() ItemListOption {
+	return func(nl *ItemList) {
+		nl.nodeReuseCheck = true
+	}
+}
+
+// WithStrictNames makes the writes check names with ValidateNameStrict instead of ValidateName.
+func WithStrictNames() ItemListOption {
+	return func(nl *ItemList) {
+		nl.strictNames = true
+	}
+}
+
+// WithDirectoryVersion keeps a directory version, read by Version, bumped by every change to the names,
+// so cached listings are checked without listing, at the cost of one more command per change.
+// See item_list_directory_version.go.
+func WithDirectoryVersion() ItemListOption {
+	return func(nl *ItemList) {
+		nl.directoryVersion = true
+	}
+}
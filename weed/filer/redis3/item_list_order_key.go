@@ -0,0 +1,152 @@
+package redis3
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+// orderKeySeparator ends the order key of a stored name, and sorts before any other byte,
+// so a shorter order key sorts before the longer ones it prefixes.
+const orderKeySeparator = "\x00"
+
+// storedName is the member stored for name, with WithNamePrefix, WithOrderKey and WithNameCipher applied
+func (nl *ItemList) storedName(name string) string {
+	if nl.nameCipher != nil {
+		return nl.namePrefix + nl.nameCipher.EncryptName(name)
+	}
+	if nl.orderKey == nil {
+		return nl.namePrefix + name
+	}
+	return nl.namePrefix + nl.orderKey(name) + orderKeySeparator + name
+}
+
+// storedStart is the stored startFrom of a listing, the empty startFrom listing from the first name
+func (nl *ItemList) storedStart(startFrom string) string {
+	if startFrom == "" {
+		return nl.namePrefix
+	}
+	return nl.storedName(startFrom)
+}
+
+// checkOrderKey rejects names whose order key can not be told apart from the name in the stored member
+func (nl *ItemList) checkOrderKey(name string) error {
+	if nl.orderKey == nil {
+		return nil
+	}
+	if orderKey := nl.orderKey(name); strings.Contains(orderKey, orderKeySeparator) {
+		return fmt.Errorf("order key %q of %q: %w", orderKey, name, ErrInvalidOrderKey)
+	}
+	return nil
+}
+
+// realName is the name stored as storedName, false if storedName is not one of this list's names,
+// which sort after all of them when WithNamePrefix is set
+func (nl *ItemList) realName(storedName string) (string, bool) {
+	if !strings.HasPrefix(storedName, nl.namePrefix) {
+		return "", false
+	}
+	name := storedName[len(nl.namePrefix):]
+	if nl.nameCipher != nil {
+		decrypted, err := nl.nameCipher.DecryptName(name)
+		if err != nil {
+			// listed as stored rather than ending the listing
+			glog.Errorf("decrypt name %q of %s: %v", name, nl.prefix, err)
+			return name, true
+		}
+		return decrypted, true
+	}
+	if nl.orderKey == nil {
+		return name, true
+	}
+	i := strings.Index(name, orderKeySeparator)
+	if i < 0 {
+		return "", false
+	}
+	return name[i+len(orderKeySeparator):], true
+}
+
+// listedName is realName for listings, ok being false once past the names with the name prefix,
+// which belong to other lists sharing the nodes. A member with the prefix which realName rejects
+// was not stored by this list, and fails the listing with ErrCorruptMember instead of ending it.
+func (nl *ItemList) listedName(storedName string) (name string, ok bool, err error) {
+	name, ok = nl.realName(storedName)
+	if !ok && strings.HasPrefix(storedName, nl.namePrefix) {
+		return "", false, fmt.Errorf("list %s: %w: %q", nl.prefix, ErrCorruptMember, storedName)
+	}
+	return name, ok, nil
+}
+
+// sortKey is storedName without the name prefix, which orders the names of lists sharing the order key function
+func (nl *ItemList) sortKey(storedName string) string {
+	return strings.TrimPrefix(storedName, nl.namePrefix)
+}
+
+// realNameOf is the name stored as storedName, which is known to be one of this list's names
+func (nl *ItemList) realNameOf(storedName string) string {
+	name, _ := nl.realName(storedName)
+	return name
+}
+
+// Int64OrderKey is an order key for WithOrderKey sorting decimal integer names by value, "2" < "10" < "100",
+// then all other names by name.
+func Int64OrderKey(name string) string {
+	n, err := strconv.ParseInt(name, 10, 64)
+	if err != nil {
+		return "1"
+	}
+	return fmt.Sprintf("0%016x", uint64(n)^(1<<63))
+}
+
+// Float64OrderKey is an order key for WithOrderKey sorting names parsed by strconv.ParseFloat by value,
+// then all other names, and NaN, by name.
+func Float64OrderKey(name string) string {
+	f, err := strconv.ParseFloat(name, 64)
+	if err != nil || math.IsNaN(f) {
+		return "1"
+	}
+	bits := math.Float64bits(f)
+	if bits&(1<<63) != 0 {
+		bits = ^bits
+	} else {
+		bits |= 1 << 63
+	}
+	return fmt.Sprintf("0%016x", bits)
+}
+
+// NaturalOrderKey is an order key for WithOrderKey comparing the runs of decimal digits of names by value,
+// so "file2" < "file10" < "file100". A number sorts before any other character at the same position,
+// and names differing only in leading zeros are ordered by name.
+func NaturalOrderKey(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); {
+		if !isDigit(name[i]) {
+			b.WriteByte(name[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(name) && isDigit(name[j]) {
+			j++
+		}
+		digits := strings.TrimLeft(name[i:j], "0")
+		if digits == "" {
+			digits = "0"
+		}
+		// the digit count, itself prefixed by its length, makes longer numbers sort later
+		count := strconv.Itoa(len(digits))
+		b.WriteByte('\x01')
+		b.WriteString(strconv.Itoa(len(count)))
+		b.WriteString(count)
+		b.WriteString(digits)
+		i = j
+	}
+	return b.String()
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
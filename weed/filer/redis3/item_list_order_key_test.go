@@ -0,0 +1,133 @@
+package redis3
+
+import (
+	"context"
+	"math/rand"
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamePrefix(t *testing.T) {
+	raw, _ := newTestItemList(t, 3)
+	dirA := mustNewItemList(t, raw.client, raw.prefix, raw.skipList.ListStore, 3, WithNamePrefix("a/"))
+	dirB := mustNewItemList(t, raw.client, raw.prefix, raw.skipList.ListStore, 3, WithNamePrefix("b/"))
+	// the lists share one skiplist header
+	dirA.skipList, dirB.skipList = raw.skipList, raw.skipList
+
+	assert.NoError(t, raw.WriteName(context.Background(), "0"))
+	for _, name := range []string{"x", "y", "z", "w"} {
+		assert.NoError(t, dirA.WriteName(context.Background(), name))
+		assert.NoError(t, dirB.WriteName(context.Background(), name+name))
+	}
+	assert.NoError(t, raw.WriteName(context.Background(), "c"))
+	assert.NoError(t, dirA.DeleteName(context.Background(), "y"))
+	assert.ErrorIs(t, dirA.WriteName(context.Background(), ""), ErrEmptyName)
+
+	assert.Equal(t, []string{"0", "a/w", "a/x", "a/z", "b/ww", "b/xx", "b/yy", "b/zz", "c"}, listAllNames(t, raw, ""))
+	assert.Equal(t, []string{"w", "x", "z"}, listAllNames(t, dirA, ""))
+	assert.Equal(t, []string{"x", "z"}, listAllNames(t, dirA, "x"))
+	assert.Equal(t, []string{"yy", "zz"}, listAllNames(t, dirB, "y"))
+
+	var names []string
+	for it := dirB.Iterator(context.Background(), ""); it.Next(); {
+		names = append(names, it.Value())
+	}
+	assert.Equal(t, []string{"ww", "xx", "yy", "zz"}, names)
+
+	found, err := dirA.ExistsMany(context.Background(), []string{"x", "y", "ww"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"x": true, "y": false, "ww": false}, found)
+}
+
+func TestOrderKey(t *testing.T) {
+	byExtension := WithOrderKey(path.Ext)
+	nl, _ := newTestItemList(t, 3, byExtension, WithNamePrefix("p/"))
+	names := []string{"b.txt", "a.jpg", "c", "a.txt", "z.go", "d.jpg", "e"}
+	for _, name := range names {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	expected := []string{"c", "e", "z.go", "a.jpg", "d.jpg", "a.txt", "b.txt"}
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assert.Equal(t, expected[3:], listAllNames(t, nl, "a.jpg"))
+	assertItemListConsistent(t, nl)
+
+	var iterated []string
+	it := nl.Iterator(context.Background(), "d.jpg")
+	for it.Next() {
+		iterated = append(iterated, it.Value())
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, expected[4:], iterated)
+
+	page, marker, hasMore, err := nl.Page(context.Background(), "z.go", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.jpg", "d.jpg"}, page)
+	assert.Equal(t, "d.jpg", marker)
+	assert.True(t, hasMore)
+
+	var matched []string
+	assert.NoError(t, nl.ListNamesMatching(context.Background(), "", "a*", func(name string) bool {
+		matched = append(matched, name)
+		return true
+	}))
+	assert.Equal(t, []string{"a.jpg", "a.txt"}, matched)
+
+	found, err := nl.ExistsMany(context.Background(), []string{"a.txt", "a.go"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"a.txt": true, "a.go": false}, found)
+
+	assert.NoError(t, nl.DeleteName(context.Background(), "a.jpg"))
+	assert.Equal(t, []string{"c", "e", "z.go", "d.jpg", "a.txt", "b.txt"}, listAllNames(t, nl, ""))
+
+	other := mustNewItemList(t, nl.client, "/test/other", newSkipListElementStore("/test/other", nl.client), 3, byExtension)
+	for _, name := range []string{"c", "e", "z.go", "a.txt", "x.txt"} {
+		assert.NoError(t, other.WriteName(context.Background(), name))
+	}
+	onlyInA, onlyInB, err := DiffLists(context.Background(), nl, other, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d.jpg", "b.txt"}, onlyInA)
+	assert.Equal(t, []string{"x.txt"}, onlyInB)
+
+	invalid := withOptions(nl, WithOrderKey(func(name string) string { return "a\x00" }))
+	assert.ErrorIs(t, invalid.WriteName(context.Background(), "f"), ErrInvalidOrderKey)
+
+	// a member with the name prefix but without an order key fails the listing, one past the prefix ends it
+	last := nl.skipList.GetLargestNodeReference()
+	assert.NoError(t, nl.NodeAddMember(last, "q/other"))
+	assert.Equal(t, []string{"c", "e", "z.go", "d.jpg", "a.txt", "b.txt"}, listAllNames(t, nl, ""))
+	assert.NoError(t, nl.NodeAddMember(last, "p/misstored"))
+	err = nl.ListNames(context.Background(), "", func(name string) bool { return true })
+	assert.ErrorIs(t, err, ErrCorruptMember)
+	it = nl.Iterator(context.Background(), "")
+	for it.Next() {
+	}
+	assert.ErrorIs(t, it.Err(), ErrCorruptMember)
+}
+
+func TestBuiltinOrderKeys(t *testing.T) {
+	for _, tc := range []struct {
+		orderKey func(name string) string
+		names    []string
+	}{
+		{Int64OrderKey, []string{"-100", "-2", "0", "2", "10", "100", "9223372036854775807", "1.5", "abc"}},
+		{Float64OrderKey, []string{"-Inf", "-100", "-2.5", "0", "1.5", "2", "10", "100", "1e300", "NaN", "abc"}},
+		{NaturalOrderKey, []string{"", "2", "10", "100", "file", "file01", "file1", "file2", "file2a", "file10", "file100", "file100.txt", "x007y", "x7z"}},
+	} {
+		sorted := append([]string(nil), tc.names...)
+		sort.Slice(sorted, func(i, j int) bool {
+			return tc.orderKey(sorted[i])+orderKeySeparator+sorted[i] < tc.orderKey(sorted[j])+orderKeySeparator+sorted[j]
+		})
+		assert.Equal(t, tc.names, sorted)
+	}
+
+	nl, _ := newTestItemList(t, 3, WithOrderKey(NaturalOrderKey))
+	names := []string{"2", "10", "100", "img1.jpg", "img2.jpg", "img12.jpg"}
+	for _, i := range rand.Perm(len(names)) {
+		assert.NoError(t, nl.WriteName(context.Background(), names[i]))
+	}
+	assert.Equal(t, names, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+}
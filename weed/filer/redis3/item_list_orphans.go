@@ -0,0 +1,100 @@
+package redis3
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+An orphan set is a member set "<prefix><id>m", or one of its shards with WithNodeShards, whose node is not
+in the skiplist, e.g. left by a failed delete which removed the node from the skiplist but not its set.
+Listings never read it, so it only takes Redis memory, growing with each failure however stable the directory.
+A set whose skiplist element still exists is not an orphan, even if it is not reachable from this copy of the
+list header: it may be a node another writer just added, or a node of a rebalance or ReplaceAll in progress.
+*/
+
+// FindOrphanSets scans the prefix for the member sets of nodes which are not in the skiplist, and returns their keys,
+// sorted. Only keys in the node key encoding of this list are considered, but like AssertEmpty, a list whose prefix
+// extends this one with digits has keys that look like node keys, so review the keys before CleanOrphanSets.
+func (nl *ItemList) FindOrphanSets(ctx context.Context) ([]string, error) {
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return nil, err
+	}
+	referenced := make(map[int64]bool)
+	err := nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		referenced[node.Id] = true
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[int64][]string)
+	err = nl.scanKeys(ctx, escapeScanPattern(nl.prefix)+"*", func(key string) error {
+		setKey := key
+		if i := strings.Index(key[len(nl.prefix):], "m:"); i >= 0 {
+			setKey = key[:len(nl.prefix)+i+1]
+		}
+		id, ok := nl.parseNodeKey(setKey)
+		if !ok || nl.nodeKey(id) != setKey || referenced[id] {
+			return nil
+		}
+		candidates[id] = append(candidates[id], key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []string
+	for id, keys := range candidates {
+		element, err := nl.loadElement(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if element != nil {
+			continue
+		}
+		orphans = append(orphans, keys...)
+	}
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// CleanOrphanSets deletes the keys of confirmed, as returned by FindOrphanSets and reviewed, which are still orphans,
+// and returns how many it deleted. Keys not found orphaned again, e.g. of a node added meanwhile, are kept,
+// so nothing is deleted without being both confirmed and rechecked. Hold the directory lock while it runs.
+func (nl *ItemList) CleanOrphanSets(ctx context.Context, confirmed []string) (deleted int, err error) {
+	if nl.readOnly {
+		return 0, ErrReadOnly
+	}
+	if len(confirmed) == 0 {
+		return 0, nil
+	}
+	orphans, err := nl.FindOrphanSets(ctx)
+	if err != nil {
+		return 0, err
+	}
+	isOrphan := make(map[string]bool, len(orphans))
+	for _, key := range orphans {
+		isOrphan[key] = true
+	}
+	for _, key := range confirmed {
+		if !isOrphan[key] {
+			glog.V(1).Infof("clean orphans %s: keep %s, no longer an orphan", nl.prefix, key)
+			continue
+		}
+		if err := nl.client.Del(ctx, key).Err(); err != nil {
+			return deleted, wrapNodeKeyError(key, err)
+		}
+		deleted++
+	}
+	if deleted > 0 {
+		glog.V(0).Infof("clean orphans %s: deleted %d orphan sets", nl.prefix, deleted)
+	}
+	return deleted, nil
+}
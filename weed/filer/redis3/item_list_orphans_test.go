@@ -0,0 +1,49 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrphanSets(t *testing.T) {
+	nl, server := newTestItemList(t, 3)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	ctx := context.Background()
+	orphanKey, otherKey := nl.nodeKey(12345), nl.nodeKey(23456)
+	assert.NoError(t, nl.client.ZAdd(ctx, orphanKey, redis.Z{Member: "x"}).Err())
+	assert.NoError(t, nl.client.ZAdd(ctx, otherKey, redis.Z{Member: "y"}).Err())
+	// a node whose element exists, e.g. added by another writer after this header was loaded
+	added, err := skiplist.New(nl.skipList.ListStore).InsertByKey([]byte("z"), 0, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, nl.client.ZAdd(ctx, nl.nodeKey(added), redis.Z{Member: "z"}).Err())
+	// not node keys of this list
+	assert.NoError(t, nl.client.Set(ctx, nl.prefix+"0012345m", "v", 0).Err())
+	assert.NoError(t, nl.client.Set(ctx, nl.prefix+"extra", "v", 0).Err())
+
+	orphans, err := nl.FindOrphanSets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{orphanKey, otherKey}, orphans)
+
+	deleted, err := nl.CleanOrphanSets(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+	// otherKey is taken by a new node before the cleanup
+	_, err = nl.itemAdd(context.Background(), []byte("y"), 23456)
+	assert.NoError(t, err)
+	deleted, err = nl.CleanOrphanSets(context.Background(), orphans)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.False(t, server.Exists(orphanKey))
+	assert.True(t, server.Exists(otherKey))
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g", "y"}, listAllNames(t, nl, ""))
+
+	orphans, err = nl.FindOrphanSets(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, orphans)
+}
@@ -0,0 +1,52 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+)
+
+// ListNamesAfterExclusive is ListNames starting after startAfter, so the last name of a listing resumes it.
+// An empty startAfter lists from the first name.
+func (nl *ItemList) ListNamesAfterExclusive(ctx context.Context, startAfter string, visitNamesFn func(name string) bool) error {
+	return nl.ListNamesWithError(ctx, startAfter, func(name string) (bool, error) {
+		if name == startAfter {
+			return true, nil
+		}
+		return visitNamesFn(name), nil
+	})
+}
+
+// ListNamesExcluding is ListNames skipping the names in exclude, e.g. those already known to a sync.
+// exclude is only read, in memory, so the caller bounds its size.
+func (nl *ItemList) ListNamesExcluding(ctx context.Context, startFrom string, exclude map[string]struct{}, visitNamesFn func(name string) bool) error {
+	return nl.ListNamesWithError(ctx, startFrom, func(name string) (bool, error) {
+		if _, excluded := exclude[name]; excluded {
+			return true, nil
+		}
+		return visitNamesFn(name), nil
+	})
+}
+
+// Page returns up to pageSize names after the exclusive marker, with the marker of the next page,
+// which is the last returned name. hasMore tells whether names are left after this page.
+func (nl *ItemList) Page(ctx context.Context, marker string, pageSize int) (names []string, nextMarker string, hasMore bool, err error) {
+	if pageSize <= 0 {
+		return nil, "", false, fmt.Errorf("invalid page size %d", pageSize)
+	}
+	err = nl.ListNamesAfterExclusive(ctx, marker, func(name string) bool {
+		if len(names) == pageSize {
+			// read one more name to know there is a next page
+			hasMore = true
+			return false
+		}
+		names = append(names, name)
+		return true
+	})
+	if err != nil {
+		return nil, "", false, err
+	}
+	if len(names) > 0 {
+		nextMarker = names[len(names)-1]
+	}
+	return names, nextMarker, hasMore, nil
+}
@@ -0,0 +1,78 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPage(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	var expected []string
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("name%02d", i)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+		expected = append(expected, name)
+	}
+
+	var paged []string
+	marker, hasMore := "", true
+	for pages := 0; hasMore; pages++ {
+		if !assert.Less(t, pages, 4) {
+			return
+		}
+		var names []string
+		var err error
+		names, marker, hasMore, err = nl.Page(context.Background(), marker, 4)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(names), 4)
+		paged = append(paged, names...)
+	}
+	assert.Equal(t, expected, paged)
+	assert.Equal(t, "name09", marker)
+
+	// an exact last page has no more names
+	names, marker, hasMore, err := nl.Page(context.Background(), "name05", 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name06", "name07", "name08", "name09"}, names)
+	assert.Equal(t, "name09", marker)
+	assert.False(t, hasMore)
+
+	// a marker which is not a name
+	names, _, hasMore, err = nl.Page(context.Background(), "name055", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name06", "name07"}, names)
+	assert.True(t, hasMore)
+
+	names, marker, hasMore, err = nl.Page(context.Background(), "name09", 4)
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+	assert.Empty(t, marker)
+	assert.False(t, hasMore)
+
+	_, _, _, err = nl.Page(context.Background(), "", 0)
+	assert.Error(t, err)
+}
+
+func TestListNamesExcluding(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	exclude := map[string]struct{}{"a": {}, "c": {}, "d": {}, "x": {}}
+	var names []string
+	assert.NoError(t, nl.ListNamesExcluding(context.Background(), "b", exclude, func(name string) bool {
+		names = append(names, name)
+		return len(names) < 3
+	}))
+	assert.Equal(t, []string{"b", "e", "f"}, names)
+
+	names = nil
+	assert.NoError(t, nl.ListNamesExcluding(context.Background(), "", nil, func(name string) bool {
+		names = append(names, name)
+		return true
+	}))
+	assert.Equal(t, listAllNames(t, nl, ""), names)
+}
@@ -0,0 +1,94 @@
+package redis3
+
+import (
+	"context"
+	"sync"
+
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// prefetchedNode is the names of one node read ahead of the listing
+type prefetchedNode struct {
+	names []string
+	err   error
+}
+
+// listNodesPrefetched visits the names of node and all its successors, like the sequential walk in listNames,
+// while up to prefetchDepth following nodes are read concurrently. Results are still visited in node order.
+// Unless it is nil, scanned is set to nodesScanned plus the nodes visited, not counting those read ahead.
+// It returns once the reads in flight are done, as they use the context of the listing.
+func (nl *ItemList) listNodesPrefetched(ctx context.Context, node *skiplist.SkipListElement, startFrom string, nodesScanned int, scanned *int, visitNamesFn func(name string) (bool, error)) error {
+	queue := make(chan chan prefetchedNode, nl.prefetchDepth)
+	done := make(chan struct{})
+	var reads sync.WaitGroup
+	defer reads.Wait()
+	defer close(done)
+
+	// the reader goroutine counts nodesScanned on, for the cap
+	visited := nodesScanned
+	if scanned != nil {
+		defer func() {
+			*scanned = visited
+		}()
+	}
+	guard := nl.newChainGuard(ctx)
+	reads.Add(1)
+	go func() {
+		defer reads.Done()
+		defer close(queue)
+		for node != nil {
+			// buffered, so an abandoned read does not block
+			result := make(chan prefetchedNode, 1)
+			select {
+			case queue <- result:
+			case <-done:
+				return
+			}
+			if nl.maxListingNodes > 0 && nodesScanned >= nl.maxListingNodes {
+				result <- prefetchedNode{err: &ListingTooLargeError{NodesScanned: nodesScanned, ResumeFrom: string(node.Key)}}
+				return
+			}
+			if err := guard.step(); err != nil {
+				result <- prefetchedNode{err: err}
+				return
+			}
+			nodesScanned++
+			reads.Add(1)
+			go func(ref *skiplist.SkipListElementReference) {
+				defer reads.Done()
+				names, err := nl.nodeRangeInclusiveAfter(ctx, ref, startFrom)
+				result <- prefetchedNode{names: names, err: err}
+			}(node.Reference())
+
+			next, err := nl.skipList.LoadElementContext(ctx, node.Next[0])
+			if err != nil {
+				result := make(chan prefetchedNode, 1)
+				result <- prefetchedNode{err: err}
+				select {
+				case queue <- result:
+				case <-done:
+				}
+				return
+			}
+			node = next
+		}
+	}()
+
+	for result := range queue {
+		prefetched := <-result
+		if prefetched.err != nil {
+			return prefetched.err
+		}
+		// nodes read ahead are not visited once the listing is done
+		if err := guard.ctx.Err(); err != nil {
+			return err
+		}
+		visited++
+		for _, name := range prefetched.names {
+			if shouldContinue, err := visitNamesFn(name); !shouldContinue || err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package redis3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListingPrefetch(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("name%03d", i)))
+	}
+	for _, depth := range []int{1, 4} {
+		prefetched := withOptions(nl, WithListingPrefetch(depth))
+		for _, startFrom := range []string{"", "name031", "name0315", "zzz"} {
+			assert.Equal(t, listAllNames(t, nl, startFrom), listAllNames(t, prefetched, startFrom), "depth %d from %q", depth, startFrom)
+		}
+
+		var visited []string
+		assert.NoError(t, prefetched.ListNames(context.Background(), "name050", func(name string) bool {
+			visited = append(visited, name)
+			return len(visited) < 5
+		}))
+		assert.Equal(t, []string{"name050", "name051", "name052", "name053", "name054"}, visited)
+
+		failure := errors.New("visitor failed")
+		assert.ErrorIs(t, prefetched.ListNamesWithError(context.Background(), "", func(name string) (bool, error) {
+			return true, failure
+		}), failure)
+	}
+
+	capped := withOptions(nl, WithMaxListingNodes(4))
+	cappedPrefetched := withOptions(capped, WithListingPrefetch(2))
+	var names, prefetchedNames []string
+	err := capped.ListNames(context.Background(), "name010", func(name string) bool {
+		names = append(names, name)
+		return true
+	})
+	prefetchedErr := cappedPrefetched.ListNames(context.Background(), "name010", func(name string) bool {
+		prefetchedNames = append(prefetchedNames, name)
+		return true
+	})
+	assert.ErrorIs(t, err, ErrListingTooLarge)
+	assert.Equal(t, err, prefetchedErr)
+	assert.Equal(t, names, prefetchedNames)
+}
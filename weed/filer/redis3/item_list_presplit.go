@@ -0,0 +1,104 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+Like pre-splitting a database table into regions before a bulk load, PreSplit creates empty nodes at known
+boundaries, so a write storm of names spread over the boundaries, e.g. hashed object names, fills the nodes
+side by side instead of repeatedly splitting one node. The ids of nodes not written yet are kept in "<prefix>presplit",
+so they are not taken for empty nodes left by a failed split, which writes remove, and Verify and Reconcile skip them.
+The first name written into a pre-split node becomes its key, as the key of a node is its smallest name.
+*/
+
+const preSplitSuffix = "presplit"
+
+func (nl *ItemList) preSplitKey() string {
+	return nl.prefix + preSplitSuffix
+}
+
+// PreSplit creates an empty node for each of boundaries, which must be sorted, in an empty list.
+// Persist the list header afterwards, as after WriteName.
+func (nl *ItemList) PreSplit(ctx context.Context, boundaries []string) error {
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+	if nl.skipList.StartLevels[0] != nil {
+		return fmt.Errorf("pre-split %s: %w", nl.prefix, ErrSkiplistNotEmpty)
+	}
+	keys := make([]string, len(boundaries))
+	for i, boundary := range boundaries {
+		if err := nl.validateName(boundary); err != nil {
+			return fmt.Errorf("pre-split %s boundary %q: %w", nl.prefix, boundary, err)
+		}
+		if err := nl.checkOrderKey(boundary); err != nil {
+			return err
+		}
+		keys[i] = nl.storedName(boundary)
+		if i > 0 && keys[i] <= keys[i-1] {
+			return fmt.Errorf("pre-split %s: boundary %q is not after %q", nl.prefix, boundary, boundaries[i-1])
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ids := make([]interface{}, len(keys))
+	for i, key := range keys {
+		id, err := nl.skipList.InsertByKeyContext(ctx, []byte(key), 0, nil)
+		if err != nil {
+			return err
+		}
+		ids[i] = strconv.FormatInt(id, 10)
+	}
+	return wrapRedisError(nl.client.SAdd(ctx, nl.preSplitKey(), ids...).Err())
+}
+
+// isPreSplit tells whether an empty node was created by PreSplit and not written yet
+func (nl *ItemList) isPreSplit(ctx context.Context, node *skiplist.SkipListElementReference) (bool, error) {
+	found, err := nl.client.SIsMember(ctx, nl.preSplitKey(), strconv.FormatInt(node.ElementPointer, 10)).Result()
+	return found, wrapRedisError(err)
+}
+
+// preSplitNodes returns the ids of the nodes created by PreSplit and not written yet
+func (nl *ItemList) preSplitNodes(ctx context.Context) (map[int64]bool, error) {
+	members, err := nl.client.SMembers(ctx, nl.preSplitKey()).Result()
+	if err != nil {
+		return nil, wrapRedisError(err)
+	}
+	ids := make(map[int64]bool, len(members))
+	for _, member := range members {
+		if id, err := strconv.ParseInt(member, 10, 64); err == nil {
+			ids[id] = true
+		}
+	}
+	return ids, nil
+}
+
+// fillPreSplitNode writes name as the first name of the pre-split node, which takes name as its key
+func (nl *ItemList) fillPreSplitNode(ctx context.Context, node *skiplist.SkipListElementReference, name string) error {
+	end, err := nl.beginStructuralChange(ctx)
+	if err != nil {
+		return err
+	}
+	defer end()
+	if err := nl.deleteNodeElement(ctx, node); err != nil {
+		return err
+	}
+	if _, err := nl.itemAdd(ctx, []byte(name), node.ElementPointer, name); err != nil {
+		return err
+	}
+	return wrapRedisError(nl.client.SRem(ctx, nl.preSplitKey(), strconv.FormatInt(node.ElementPointer, 10)).Err())
+}
+
+func (nl *ItemList) deletePreSplit(ctx context.Context) error {
+	return wrapRedisError(nl.client.Del(ctx, nl.preSplitKey()).Err())
+}
@@ -0,0 +1,49 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreSplit(t *testing.T) {
+	nl, server := newTestItemList(t, 16, WithNamespaceCheck(true))
+	assert.Error(t, nl.PreSplit(context.Background(), []string{"8", "4"}))
+	assert.Error(t, nl.PreSplit(context.Background(), []string{"4", "4"}))
+	assert.ErrorIs(t, nl.PreSplit(context.Background(), []string{""}), ErrEmptyName)
+	assert.NoError(t, nl.PreSplit(context.Background(), []string{"0", "4", "8", "c"}))
+	assert.Equal(t, 4, countNodes(t, nl))
+
+	report, err := nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, report.IsConsistent())
+	assert.Len(t, report.PreSplitNodes, 4)
+	cleaned, err := nl.Reconcile(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, cleaned)
+
+	// uniformly spread names, as hashed names, fill the nodes without splitting
+	var names []string
+	for i := 0; i < 64; i++ {
+		name := fmt.Sprintf("%x%03d", i%16, i)
+		names = append(names, name)
+		split, err := nl.WriteNameWithSplit(context.Background(), name)
+		assert.NoError(t, err)
+		assert.False(t, split, name)
+	}
+	sort.Strings(names)
+	assert.Equal(t, names, listAllNames(t, nl, ""))
+	assert.Equal(t, 4, countNodes(t, nl))
+	report, err = nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, report.IsConsistent())
+	assert.Empty(t, report.PreSplitNodes)
+	assertItemListConsistent(t, nl)
+
+	assert.ErrorIs(t, nl.PreSplit(context.Background(), []string{"x"}), ErrSkiplistNotEmpty)
+	assert.NoError(t, nl.RemoteAllListElement(context.Background()))
+	assert.False(t, server.Exists(nl.preSplitKey()))
+}
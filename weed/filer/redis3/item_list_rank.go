@@ -0,0 +1,107 @@
+package redis3
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// Rank returns the 0 based index of name among the names, or for an absent name, the index it would be inserted at.
+// The nodes before the owning node are walked in the skiplist, O(node count before name) round trips,
+// and their sizes are then read in one pipeline, from the counts of WithMaintainedNodeCount when set.
+func (nl *ItemList) Rank(ctx context.Context, name string) (int64, error) {
+	if name == "" {
+		return 0, ErrEmptyName
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return 0, err
+	}
+	rank, err := nl.storedRank(ctx, nl.storedName(name))
+	if err != nil || nl.namePrefix == "" {
+		return rank, err
+	}
+	// names of other prefixes sorting before this one do not count
+	before, err := nl.storedRank(ctx, nl.namePrefix)
+	return rank - before, err
+}
+
+func (nl *ItemList) storedRank(ctx context.Context, name string) (int64, error) {
+	owner, err := nl.nodeOf(ctx, name)
+	if err != nil || owner == nil {
+		return 0, err
+	}
+
+	var before []int64
+	err = nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		if node.Id == owner.ElementPointer {
+			return false, nil
+		}
+		before = append(before, node.Id)
+		return true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sizes, err := nl.nodeSizes(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	var rank int64
+	for _, size := range sizes {
+		rank += size
+	}
+	key := nl.nodeKey(owner.ElementPointer)
+	position, err := nl.zLexCount(ctx, key, "-", "("+name).Result()
+	if err != nil {
+		return 0, wrapNodeKeyError(key, err)
+	}
+	return rank + position, nil
+}
+
+// nodeSizes reads the sizes of the nodes in one pipeline, recounting missing maintained counts
+func (nl *ItemList) nodeSizes(ctx context.Context, nodeIds []int64) ([]int64, error) {
+	if len(nodeIds) == 0 {
+		return nil, nil
+	}
+	sizes := make([]int64, len(nodeIds))
+	if nl.maintainNodeCount {
+		fields := make([]string, len(nodeIds))
+		for i, id := range nodeIds {
+			fields[i] = strconv.FormatInt(id, 10)
+		}
+		counts, err := nl.client.HMGet(ctx, nl.nodeCountKey(), fields...).Result()
+		if err != nil {
+			return nil, wrapRedisError(err)
+		}
+		for i, count := range counts {
+			if s, ok := count.(string); ok {
+				if size, err := strconv.ParseInt(s, 10, 64); err == nil {
+					sizes[i] = size
+					continue
+				}
+			}
+			sizes[i] = int64(nl.recountNode(ctx, &skiplist.SkipListElementReference{ElementPointer: nodeIds[i]}))
+		}
+		return sizes, nil
+	}
+	pipe := nl.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(nodeIds))
+	for i, id := range nodeIds {
+		cmds[i] = nl.zLexCountAll(ctx, pipe, nl.nodeKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		for i, cmd := range cmds {
+			if cmd.Err() != nil {
+				return nil, wrapNodeKeyError(nl.nodeKey(nodeIds[i]), cmd.Err())
+			}
+		}
+		return nil, wrapRedisError(err)
+	}
+	for i, cmd := range cmds {
+		sizes[i] = cmd.Val()
+	}
+	return sizes, nil
+}
@@ -0,0 +1,47 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRank(t *testing.T) {
+	for _, opts := range [][]ItemListOption{
+		nil,
+		{WithMaintainedNodeCount()},
+		{WithNamePrefix("p/")},
+	} {
+		nl, _ := newTestItemList(t, 3, opts...)
+		var names []string
+		for i := 0; i < 20; i += 2 {
+			names = append(names, fmt.Sprintf("name%02d", i))
+		}
+		for _, i := range rand.Perm(len(names)) {
+			assert.NoError(t, nl.WriteName(context.Background(), names[i]))
+		}
+		if nl.namePrefix != "" {
+			// names of another prefix before this one
+			assert.NoError(t, withOptions(nl, WithNamePrefix("a/")).WriteName(context.Background(), "x"))
+		}
+		for i, name := range names {
+			rank, err := nl.Rank(context.Background(), name)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(i), rank, name)
+		}
+		// absent names rank where they would be inserted
+		for _, tc := range []struct {
+			name string
+			rank int64
+		}{{"a", 0}, {"name03", 2}, {"name19", 10}, {"z", 10}} {
+			rank, err := nl.Rank(context.Background(), tc.name)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.rank, rank, tc.name)
+		}
+		_, err := nl.Rank(context.Background(), "")
+		assert.ErrorIs(t, err, ErrEmptyName)
+	}
+}
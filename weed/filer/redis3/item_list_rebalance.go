@@ -0,0 +1,225 @@
+package redis3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+RebalanceToBatchSize copies all names, in order, into fresh nodes of the new size,
+then points the list header "<prefix>" to the fresh nodes and deletes the old ones.
+Until the header is swapped, the old nodes are untouched and stay readable.
+
+Progress is checkpointed in the hash "<prefix>rebalance" after each fresh node:
+  batchSize: the target batch size
+  source:    the list header being copied
+  header:    the list header of the fresh nodes written so far
+  last:      the last name copied
+  pending:   the id of the next fresh node, reused on resume so a partially written node is overwritten
+The swap happened once the list header equals the checkpointed header, and only the cleanup is left.
+
+Names added or removed inside existing nodes do not change the list header, so
+hold the directory lock across an interrupted run and its resume.
+A checkpoint for another batch size or another list header is discarded.
+*/
+
+const rebalanceSuffix = "rebalance"
+
+type rebalanceCheckpoint struct {
+	batchSize int
+	source    []byte
+	header    []byte
+	last      string
+	pending   int64
+}
+
+func (nl *ItemList) rebalanceKey() string {
+	return nl.prefix + rebalanceSuffix
+}
+
+// RebalanceToBatchSize rewrites the list into nodes of newSize names and persists the new list header.
+// It resumes from the checkpoint left by an interrupted run.
+func (nl *ItemList) RebalanceToBatchSize(ctx context.Context, newSize int) error {
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	if err := nl.checkBatchSize(newSize); err != nil {
+		return err
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+
+	source := nl.ToBytes()
+	checkpoint, err := nl.loadRebalanceCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+	if checkpoint != nil && bytes.Equal(checkpoint.header, source) {
+		// interrupted after the swap
+		if err := nl.finishRebalance(ctx, checkpoint); err != nil {
+			return err
+		}
+		if checkpoint.batchSize == newSize {
+			nl.batchSize = newSize
+			return nil
+		}
+		checkpoint = nil
+		source = nl.ToBytes()
+	}
+	if checkpoint != nil && (checkpoint.batchSize != newSize || !bytes.Equal(checkpoint.source, source)) {
+		glog.V(0).Infof("rebalance %s: discard checkpoint for batch size %d", nl.prefix, checkpoint.batchSize)
+		if err := nl.deleteNodesOf(ctx, checkpoint.header); err != nil {
+			return err
+		}
+		if err := nl.deleteNode(ctx, checkpoint.pending); err != nil {
+			return err
+		}
+		checkpoint = nil
+	}
+
+	fresh := skiplist.New(nl.skipList.ListStore)
+	if checkpoint == nil {
+		checkpoint = &rebalanceCheckpoint{
+			batchSize: newSize,
+			source:    source,
+			header:    encodeSkipListHeader(fresh),
+			pending:   rand.Int63(),
+		}
+		if err := nl.saveRebalanceCheckpoint(ctx, checkpoint); err != nil {
+			return err
+		}
+	} else {
+		glog.V(0).Infof("rebalance %s: resume after %s", nl.prefix, checkpoint.last)
+		if err := decodeSkipListHeader(fresh, checkpoint.header); err != nil {
+			return fmt.Errorf("rebalance %s: %v", nl.prefix, err)
+		}
+		if err := nl.deleteNode(ctx, checkpoint.pending); err != nil {
+			return err
+		}
+	}
+
+	var names []string
+	flush := func() error {
+		id, err := fresh.InsertByKey([]byte(names[0]), checkpoint.pending, nil)
+		if err != nil {
+			return err
+		}
+		if err := nl.nodeAddMember(ctx, &skiplist.SkipListElementReference{ElementPointer: id, Key: []byte(names[0])}, names...); err != nil {
+			return err
+		}
+		checkpoint.header = encodeSkipListHeader(fresh)
+		checkpoint.last = names[len(names)-1]
+		checkpoint.pending = rand.Int63()
+		names = names[:0]
+		return nl.saveRebalanceCheckpoint(ctx, checkpoint)
+	}
+
+	err = nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		min := "-"
+		if checkpoint.last != "" {
+			min = "(" + checkpoint.last
+		}
+		key := nl.nodeKey(node.Id)
+		members, err := nl.zRangeByLex(ctx, key, &redis.ZRangeBy{Min: min, Max: "+"}).Result()
+		if err != nil {
+			return false, wrapRedisError(err)
+		}
+		for _, name := range members {
+			names = append(names, name)
+			if len(names) >= newSize {
+				if err := flush(); err != nil {
+					return false, err
+				}
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+
+	// swap
+	if err := nl.client.Set(ctx, nl.prefix, checkpoint.header, 0).Err(); err != nil {
+		return wrapRedisError(err)
+	}
+	nl.skipList = fresh
+	nl.batchSize = newSize
+	return nl.finishRebalance(ctx, checkpoint)
+}
+
+// finishRebalance deletes the old nodes and the checkpoint
+func (nl *ItemList) finishRebalance(ctx context.Context, checkpoint *rebalanceCheckpoint) error {
+	if err := nl.deleteNodesOf(ctx, checkpoint.source); err != nil {
+		return err
+	}
+	if err := nl.deleteNode(ctx, checkpoint.pending); err != nil {
+		return err
+	}
+	return wrapRedisError(nl.client.Del(ctx, nl.rebalanceKey()).Err())
+}
+
+// deleteNodesOf deletes the nodes of the skiplist with the given header
+func (nl *ItemList) deleteNodesOf(ctx context.Context, header []byte) error {
+	t := skiplist.New(nl.skipList.ListStore)
+	if err := decodeSkipListHeader(t, header); err != nil {
+		return fmt.Errorf("rebalance %s: %v", nl.prefix, err)
+	}
+	return nl.walkNodesOf(ctx, t, func(node *skiplist.SkipListElement) (bool, error) {
+		if err := nl.deleteNode(ctx, node.Id); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+func (nl *ItemList) deleteNode(ctx context.Context, id int64) error {
+	if err := nl.skipList.ListStore.DeleteElement(id); err != nil {
+		return err
+	}
+	return nl.nodeDelete(ctx, &skiplist.SkipListElementReference{ElementPointer: id})
+}
+
+func (nl *ItemList) loadRebalanceCheckpoint(ctx context.Context) (*rebalanceCheckpoint, error) {
+	fields, err := nl.client.HGetAll(ctx, nl.rebalanceKey()).Result()
+	if err != nil {
+		return nil, wrapRedisError(err)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	checkpoint := &rebalanceCheckpoint{
+		source: []byte(fields["source"]),
+		header: []byte(fields["header"]),
+		last:   fields["last"],
+	}
+	if checkpoint.batchSize, err = strconv.Atoi(fields["batchSize"]); err != nil {
+		return nil, fmt.Errorf("rebalance %s: batch size: %v", nl.prefix, err)
+	}
+	if checkpoint.pending, err = strconv.ParseInt(fields["pending"], 10, 64); err != nil {
+		return nil, fmt.Errorf("rebalance %s: pending node: %v", nl.prefix, err)
+	}
+	return checkpoint, nil
+}
+
+func (nl *ItemList) saveRebalanceCheckpoint(ctx context.Context, checkpoint *rebalanceCheckpoint) error {
+	return wrapRedisError(nl.client.HSet(ctx, nl.rebalanceKey(),
+		"batchSize", checkpoint.batchSize,
+		"source", checkpoint.source,
+		"header", checkpoint.header,
+		"last", checkpoint.last,
+		"pending", checkpoint.pending,
+	).Err())
+}
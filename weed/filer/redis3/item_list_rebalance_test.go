@@ -0,0 +1,77 @@
+package redis3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingListStore struct {
+	skiplist.ListStore
+	savesLeft int
+}
+
+func (s *failingListStore) SaveElement(id int64, element *skiplist.SkipListElement) error {
+	if s.savesLeft == 0 {
+		return errors.New("injected failure")
+	}
+	s.savesLeft--
+	return s.ListStore.SaveElement(id, element)
+}
+
+func TestRebalanceToBatchSize(t *testing.T) {
+	nl, server := newTestItemList(t, 3)
+	var expected []string
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("name%02d", i)
+		expected = append(expected, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	memberSets := func() int {
+		n := 0
+		for _, key := range server.Keys() {
+			if _, ok := nl.parseNodeKey(key); ok {
+				n++
+			}
+		}
+		return n
+	}
+
+	// interrupted runs keep the old nodes readable
+	store := nl.skipList.ListStore
+	failing := &failingListStore{ListStore: store, savesLeft: 4}
+	nl.skipList.ListStore = failing
+	assert.Error(t, nl.RebalanceToBatchSize(context.Background(), 10))
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assert.True(t, server.Exists(nl.rebalanceKey()))
+
+	// resume
+	nl.skipList.ListStore = store
+	assert.NoError(t, nl.RebalanceToBatchSize(context.Background(), 10))
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assert.Equal(t, 5, countNodes(t, nl))
+	assert.Equal(t, 5, memberSets())
+	assert.False(t, server.Exists(nl.rebalanceKey()))
+	assertItemListConsistent(t, nl)
+
+	// the swapped list header is persisted
+	header, err := server.Get(nl.prefix)
+	assert.NoError(t, err)
+	reloaded, err := LoadItemList([]byte(header), nl.prefix, nl.client, store, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, listAllNames(t, reloaded, ""))
+
+	// a checkpoint for another batch size is discarded
+	failing.savesLeft = 2
+	nl.skipList.ListStore = failing
+	assert.Error(t, nl.RebalanceToBatchSize(context.Background(), 4))
+	nl.skipList.ListStore = store
+	assert.NoError(t, nl.RebalanceToBatchSize(context.Background(), 25))
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assert.Equal(t, 2, countNodes(t, nl))
+	assert.Equal(t, 2, memberSets())
+}
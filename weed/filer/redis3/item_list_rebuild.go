@@ -0,0 +1,107 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// RebuildSkiplistFromSets is a disaster recovery tool for a lost or corrupted skiplist.
+// It scans the node member sets matching nodeKeyPattern, default "<prefix>*m",
+// and inserts one skiplist node per non-empty set, keyed by the set's smallest member.
+// It refuses to run on a non-empty skiplist unless force is set, in which case the
+// current skiplist is discarded. The caller must persist the list with ToBytes() afterwards.
+func (nl *ItemList) RebuildSkiplistFromSets(ctx context.Context, nodeKeyPattern string, force bool) error {
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	if nl.nodeShardLength > 0 {
+		return fmt.Errorf("rebuild %s: sharded nodes are not supported", nl.prefix)
+	}
+	if !nl.skipList.IsEmpty() {
+		if !force {
+			return ErrSkiplistNotEmpty
+		}
+		glog.Warningf("rebuild %s: discarding the existing skiplist", nl.prefix)
+	}
+	if nodeKeyPattern == "" {
+		nodeKeyPattern = escapeScanPattern(nl.prefix) + "*m"
+	}
+
+	nl.skipList = skiplist.New(nl.skipList.ListStore)
+	nl.skipList.HasChanges = true
+	if nl.maintainNodeCount {
+		if err := nl.client.Del(ctx, nl.nodeCountKey()).Err(); err != nil {
+			return err
+		}
+	}
+
+	rebuilt := 0
+	err := nl.scanKeys(ctx, nodeKeyPattern, func(key string) error {
+		id, ok := nl.parseNodeKey(key)
+		if !ok {
+			glog.V(1).Infof("rebuild %s: skip key %s", nl.prefix, key)
+			return nil
+		}
+		minNames, err := nl.zLexMin(ctx, nl.client, key).Result()
+		if err != nil {
+			return fmt.Errorf("read %s: %v", key, err)
+		}
+		if len(minNames) == 0 {
+			return nil
+		}
+		if _, err := nl.skipList.InsertByKeyContext(ctx, []byte(minNames[0]), id, nil); err != nil {
+			return fmt.Errorf("insert %s: %v", key, err)
+		}
+		rebuilt++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	glog.V(0).Infof("rebuild %s: rebuilt skiplist with %d nodes", nl.prefix, rebuilt)
+	return nil
+}
+
+// RebuildSetsFromSkiplist is the inverse recovery tool, for lost member sets.
+// The skiplist only knows the leading key of each node, so only these names can be restored,
+// into the member sets that are missing. It returns the number of restored names.
+func (nl *ItemList) RebuildSetsFromSkiplist(ctx context.Context) (restored int, err error) {
+	if nl.readOnly {
+		return 0, ErrReadOnly
+	}
+	err = nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		key := nl.nodeKey(node.Id)
+		exists, err := nl.client.Exists(ctx, key).Result()
+		if err != nil {
+			return false, err
+		}
+		if exists == 0 {
+			if err := nl.deleteNodeCount(ctx, node.Reference()); err != nil {
+				return false, err
+			}
+			if err := nl.nodeAddMember(ctx, node.Reference(), string(node.Key)); err != nil {
+				return false, err
+			}
+			restored++
+		}
+		return true, nil
+	})
+	if err != nil {
+		return restored, err
+	}
+	glog.V(0).Infof("rebuild %s: restored %d leading names", nl.prefix, restored)
+	return restored, nil
+}
+
+// parseNodeKey extracts the node id from a member set key "<prefix><id>m"
+func (nl *ItemList) parseNodeKey(key string) (id int64, ok bool) {
+	if !strings.HasPrefix(key, nl.prefix) || !strings.HasSuffix(key, "m") {
+		return 0, false
+	}
+	id, ok = decodeNodeId(key[len(nl.prefix):len(key)-1], nl.nodeKeyEncoding)
+	return id, ok && id != 0
+}
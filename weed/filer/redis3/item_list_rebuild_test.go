@@ -0,0 +1,56 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemListRebuildSkiplistFromSets(t *testing.T) {
+	nl, server := newTestItemList(t, 3)
+	var expected []string
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("%02d", (i*7)%20)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	for i := 0; i < 20; i++ {
+		expected = append(expected, fmt.Sprintf("%02d", i))
+	}
+
+	assert.ErrorIs(t, nl.RebuildSkiplistFromSets(context.Background(), "", false), ErrSkiplistNotEmpty)
+
+	// lose the skiplist
+	for _, key := range server.Keys() {
+		if _, isNode := nl.parseNodeKey(key); !isNode {
+			server.Del(key)
+		}
+	}
+	lost := mustNewItemList(t, nl.client, nl.prefix, nl.skipList.ListStore, 3)
+	assert.Empty(t, listAllNames(t, lost, ""))
+
+	assert.NoError(t, lost.RebuildSkiplistFromSets(context.Background(), "", false))
+	assert.True(t, lost.HasChanges())
+	assertItemListConsistent(t, lost)
+	assert.Equal(t, expected, listAllNames(t, lost, ""))
+	assert.NoError(t, lost.WriteName(context.Background(), "205"))
+	assert.NoError(t, lost.DeleteName(context.Background(), "00"))
+	assert.Equal(t, append(expected[1:], "205"), listAllNames(t, lost, ""))
+}
+
+func TestItemListRebuildSetsFromSkiplist(t *testing.T) {
+	nl, server := newTestItemList(t, 2)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	for _, key := range server.Keys() {
+		if _, isNode := nl.parseNodeKey(key); isNode {
+			server.Del(key)
+		}
+	}
+	restored, err := nl.RebuildSetsFromSkiplist(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, restored)
+	assert.Equal(t, []string{"a", "c", "e"}, listAllNames(t, nl, ""))
+}
@@ -0,0 +1,66 @@
+package redis3
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+const reconcilePipelineSize = 1000
+
+// Reconcile removes the skiplist nodes whose member set no longer exists,
+// e.g. left over when deleting a node removed its member set but not its skiplist key.
+// Unlike RebuildSetsFromSkiplist, a missing member set is taken as deleted.
+// It returns the number of removed nodes. The caller must persist the list with ToBytes() afterwards.
+func (nl *ItemList) Reconcile(ctx context.Context) (cleaned int, err error) {
+	if nl.readOnly {
+		return 0, ErrReadOnly
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return 0, err
+	}
+	preSplit, err := nl.preSplitNodes(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var nodes []*skiplist.SkipListElementReference
+	err = nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		nodes = append(nodes, node.Reference())
+		return true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for start := 0; start < len(nodes); start += reconcilePipelineSize {
+		batch := nodes[start:min(start+reconcilePipelineSize, len(nodes))]
+		pipe := nl.client.Pipeline()
+		cmds := make([]*redis.IntCmd, len(batch))
+		for i, node := range batch {
+			cmds[i] = pipe.Exists(ctx, nl.nodeKey(node.ElementPointer))
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return cleaned, wrapRedisError(err)
+		}
+		for i, node := range batch {
+			if cmds[i].Val() != 0 || preSplit[node.ElementPointer] {
+				continue
+			}
+			glog.V(1).Infof("reconcile %s: remove node %d with key %s", nl.prefix, node.ElementPointer, string(node.Key))
+			if _, err := nl.skipList.DeleteByKeyContext(ctx, node.Key); err != nil {
+				return cleaned, err
+			}
+			if err := nl.deleteNodeCount(ctx, node); err != nil {
+				return cleaned, err
+			}
+			cleaned++
+		}
+	}
+	if cleaned > 0 {
+		glog.V(0).Infof("reconcile %s: removed %d nodes without member set", nl.prefix, cleaned)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,41 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcile(t *testing.T) {
+	nl, server := newTestItemList(t, 2)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("name%02d", i)))
+	}
+	cleaned, err := nl.Reconcile(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, cleaned)
+
+	// lose the member sets of the first and a middle node
+	first := nl.skipList.StartLevels[0]
+	middle, err := nl.skipList.LoadElement(first)
+	assert.NoError(t, err)
+	middle, err = nl.skipList.LoadElement(middle.Next[0])
+	assert.NoError(t, err)
+	middle, err = nl.skipList.LoadElement(middle.Next[0])
+	assert.NoError(t, err)
+	lost := append(mustNodeNames(t, nl, first), mustNodeNames(t, nl, middle.Reference())...)
+	server.Del(fmt.Sprintf("%s%dm", nl.prefix, first.ElementPointer))
+	server.Del(fmt.Sprintf("%s%dm", nl.prefix, middle.Id))
+
+	cleaned, err = nl.Reconcile(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cleaned)
+	assertItemListConsistent(t, nl)
+	names := listAllNames(t, nl, "")
+	assert.Len(t, names, 10-len(lost))
+	for _, name := range lost {
+		assert.NotContains(t, names, name)
+	}
+}
@@ -0,0 +1,95 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// ReplaceAll replaces all names of the list with names, e.g. for a directory regenerated as a whole.
+// The names are written into fresh nodes of batchSize names under a shadow skiplist, which replaces the old one
+// by setting the list header "<prefix>", a single SET: readers loading the header before it see only the old names,
+// and after it only the new ones. The old nodes are then deleted, so until then both copies take Redis memory.
+// A reader still walking the old nodes during the deletion sees a partial listing, which WithListingVersionCheck reports.
+// Name scores are dropped. The list is replaced as a whole, so WithNamePrefix is not supported.
+func (nl *ItemList) ReplaceAll(ctx context.Context, names []string) error {
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	if nl.namePrefix != "" {
+		return fmt.Errorf("replace %s: %w: WithNamePrefix shares the list", nl.prefix, ErrInvalidOptions)
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+
+	stored := make([]string, 0, len(names))
+	var checksum int64
+	for _, name := range names {
+		if err := nl.validateName(name); err != nil {
+			return err
+		}
+		if err := nl.checkOrderKey(name); err != nil {
+			return err
+		}
+		stored = append(stored, nl.storedName(name))
+	}
+	sort.Strings(stored)
+	unique := stored[:0]
+	for i, name := range stored {
+		if i == 0 || name != stored[i-1] {
+			unique = append(unique, name)
+			checksum += nameChecksum(name)
+		}
+	}
+	stored = unique
+
+	fresh := skiplist.New(nl.skipList.ListStore)
+	for start := 0; start < len(stored); start += nl.batchSize {
+		batch := stored[start:min(start+nl.batchSize, len(stored))]
+		id, err := fresh.InsertByKey([]byte(batch[0]), rand.Int63(), nil)
+		if err == nil {
+			err = nl.nodeAddMember(ctx, &skiplist.SkipListElementReference{ElementPointer: id, Key: []byte(batch[0])}, batch...)
+		}
+		if err != nil {
+			// the old list is untouched
+			if cleanupErr := nl.deleteNodesOf(ctx, encodeSkipListHeader(fresh)); cleanupErr != nil {
+				return fmt.Errorf("replace %s: %v, and delete the new nodes: %v", nl.prefix, err, cleanupErr)
+			}
+			return err
+		}
+	}
+
+	end, err := nl.beginStructuralChange(ctx)
+	if err != nil {
+		return err
+	}
+	defer end()
+	old := nl.ToBytes()
+	// swap
+	if err := nl.client.Set(ctx, nl.prefix, encodeSkipListHeader(fresh), 0).Err(); err != nil {
+		return wrapRedisError(err)
+	}
+	nl.skipList = fresh
+	if nl.recentWrites != nil {
+		nl.recentWrites.clear()
+	}
+	if nl.checksum {
+		if err := nl.client.Set(ctx, nl.checksumKey(), checksum, 0).Err(); err != nil {
+			return fmt.Errorf("update checksum: %w", wrapRedisError(err))
+		}
+	}
+	if err := nl.deleteNameScores(ctx); err != nil {
+		return err
+	}
+	if err := nl.deletePreSplit(ctx); err != nil {
+		return err
+	}
+	if err := nl.bumpDirectoryVersion(ctx); err != nil {
+		return err
+	}
+	return nl.deleteNodesOf(ctx, old)
+}
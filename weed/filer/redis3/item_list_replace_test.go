@@ -0,0 +1,51 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceAll(t *testing.T) {
+	nl, server := newTestItemList(t, 3, WithMaintainedNodeCount(), WithChecksum())
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("old%02d", i)))
+	}
+	oldKeys := server.Keys()
+
+	names := []string{"new05", "new01", "new03", "new02", "new04", "new01", "new00"}
+	assert.NoError(t, nl.ReplaceAll(context.Background(), names))
+	expected := []string{"new00", "new01", "new02", "new03", "new04", "new05"}
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+	matched, err := nl.VerifyChecksum(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, matched)
+
+	// the header is swapped, and no old node is left
+	header, err := server.Get(nl.prefix)
+	assert.NoError(t, err)
+	loaded, err := LoadItemList([]byte(header), nl.prefix, nl.client, nl.skipList.ListStore, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, listAllNames(t, loaded, ""))
+	keys := map[string]bool{}
+	for _, key := range server.Keys() {
+		keys[key] = true
+	}
+	for _, key := range oldKeys {
+		if key != nl.prefix && key != nl.checksumKey() && key != nl.nodeCountKey() {
+			assert.False(t, keys[key], key)
+		}
+	}
+
+	assert.ErrorIs(t, nl.ReplaceAll(context.Background(), []string{"a", ""}), ErrEmptyName)
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assert.ErrorIs(t, withOptions(nl, WithNamePrefix("p/")).ReplaceAll(context.Background(), names), ErrInvalidOptions)
+
+	assert.NoError(t, nl.ReplaceAll(context.Background(), nil))
+	assert.Empty(t, listAllNames(t, nl, ""))
+	assert.NoError(t, nl.RemoteAllListElement(context.Background()))
+	assert.NoError(t, nl.AssertEmpty(context.Background()))
+}
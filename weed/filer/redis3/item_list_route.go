@@ -0,0 +1,60 @@
+package redis3
+
+import "context"
+
+// RouteNode returns the node whose range holds name, from the skiplist alone, without reading any member set.
+// A name sorting before all names routes to the first node, which WriteName would merge it into if it has room.
+// isNewNode is set when WriteName would start a new node: for an empty list, or with WithNewMinimumInNewNode.
+// Only the sizes tell whether WriteName splits the node, so a full node may still move name to a new node.
+func (nl *ItemList) RouteNode(ctx context.Context, name string) (nodeId int64, isNewNode bool, err error) {
+	if name == "" {
+		return 0, false, ErrEmptyName
+	}
+	name = nl.storedName(name)
+	prev, next, found, err := nl.resolveNeighbors(ctx, name)
+	if err != nil {
+		return 0, false, err
+	}
+	switch {
+	case found && string(next.Key) == name:
+		return next.Id, false, nil
+	case prev != nil:
+		return prev.ElementPointer, false, nil
+	case next == nil || nl.newMinimumInNewNode:
+		return 0, true, nil
+	}
+	return next.Id, false, nil
+}
+
+// ScanStartNode returns the node ListNames(startFrom) reads first, and the number of its names before startFrom,
+// where the listing starts. It reads the skiplist and one ZLEXCOUNT, no member.
+// When all names of that node are before startFrom, the listing goes on with the next node.
+// An empty list returns node 0.
+func (nl *ItemList) ScanStartNode(ctx context.Context, startFrom string) (nodeId int64, innerPos int, err error) {
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return 0, 0, err
+	}
+	startFrom = nl.storedStart(startFrom)
+	prev, next, found, err := nl.resolveNeighbors(ctx, startFrom)
+	if err != nil {
+		return 0, 0, err
+	}
+	if found && string(next.Key) == startFrom {
+		prev = nil
+	}
+	if prev == nil {
+		if next == nil {
+			return 0, 0, nil
+		}
+		return next.Id, 0, nil
+	}
+	if startFrom == "" {
+		return prev.ElementPointer, 0, nil
+	}
+	key := nl.nodeKey(prev.ElementPointer)
+	position, err := nl.zLexCount(ctx, key, "-", "("+startFrom).Result()
+	if err != nil {
+		return 0, 0, wrapNodeKeyError(key, err)
+	}
+	return prev.ElementPointer, int(position), nil
+}
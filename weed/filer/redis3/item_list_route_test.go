@@ -0,0 +1,77 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteNode(t *testing.T) {
+	nl, server := newTestItemList(t, 3)
+	_, isNewNode, err := nl.RouteNode(context.Background(), "a")
+	assert.NoError(t, err)
+	assert.True(t, isNewNode)
+
+	for i := 1; i <= 9; i++ {
+		assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("name%d", i)))
+	}
+	// no member set is read, any sorted set command would fail
+	for _, key := range server.Keys() {
+		if strings.HasSuffix(key, "m") {
+			server.Del(key)
+			assert.NoError(t, server.Set(key, "not a sorted set"))
+		}
+	}
+	for _, name := range []string{"name1", "name2", "name35", "name9", "zzz"} {
+		nodeId, isNewNode, err := nl.RouteNode(context.Background(), name)
+		assert.NoError(t, err)
+		assert.False(t, isNewNode)
+		node, err := nl.nodeOf(context.Background(), name)
+		assert.NoError(t, err)
+		assert.Equal(t, node.ElementPointer, nodeId, name)
+	}
+	// a new minimum goes to the first node
+	nodeId, isNewNode, err := nl.RouteNode(context.Background(), "a")
+	assert.NoError(t, err)
+	assert.False(t, isNewNode)
+	assert.Equal(t, nl.skipList.StartLevels[0].ElementPointer, nodeId)
+	_, isNewNode, err = withOptions(nl, WithNewMinimumInNewNode()).RouteNode(context.Background(), "a")
+	assert.NoError(t, err)
+	assert.True(t, isNewNode)
+
+	_, _, err = nl.RouteNode(context.Background(), "")
+	assert.ErrorIs(t, err, ErrEmptyName)
+}
+
+func TestScanStartNode(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	nodeId, innerPos, err := nl.ScanStartNode(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Zero(t, nodeId)
+	assert.Zero(t, innerPos)
+
+	for i := 0; i < 10; i += 2 {
+		assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("name%02d", i)))
+	}
+	first := nl.skipList.StartLevels[0]
+	for _, startFrom := range []string{"", "a", "name00", "name01", "name03", "name04", "name05", "name08", "zzz"} {
+		nodeId, innerPos, err := nl.ScanStartNode(context.Background(), startFrom)
+		assert.NoError(t, err)
+		// the listing is the names of the node from innerPos on, and the following nodes
+		names := mustNodeNames(t, nl, &skiplist.SkipListElementReference{ElementPointer: nodeId})
+		expected := []string{}
+		for _, name := range listAllNames(t, nl, "") {
+			if name >= names[0] {
+				expected = append(expected, name)
+			}
+		}
+		assert.Equal(t, append([]string{}, listAllNames(t, nl, startFrom)...), expected[innerPos:], startFrom)
+		if startFrom < "name00" {
+			assert.Equal(t, first.ElementPointer, nodeId, startFrom)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package redis3
+
+import (
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+)
+
+// refuseOverCapacity reports a write refused by WithSafeMode, so operators see safe mode blocking writes
+func (nl *ItemList) refuseOverCapacity(nodeId int64, nodeSize int) error {
+	stats.FilerStoreCounter.WithLabelValues("redis3", "safe_mode_blocked").Inc()
+	glog.Warningf("safe mode %s: refuse to split node %d with %d names over batch size %d, run SplitOversizedNodes",
+		nl.prefix, nodeId, nodeSize, nl.batchSize)
+	return &OverCapacityError{NodeId: nodeId, NodeSize: nodeSize, BatchSize: nl.batchSize}
+}
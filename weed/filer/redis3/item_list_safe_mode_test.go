@@ -0,0 +1,40 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeMode(t *testing.T) {
+	blocked := stats.FilerStoreCounter.WithLabelValues("redis3", "safe_mode_blocked")
+	blockedBefore := testutil.ToFloat64(blocked)
+	nl, _ := newTestItemList(t, 3, WithSafeMode())
+	for _, name := range []string{"a", "b", "c"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	first := nl.skipList.StartLevels[0]
+	assert.NoError(t, nl.NodeAddMember(first, "b1"))
+
+	err := nl.WriteName(context.Background(), "b2")
+	assert.ErrorIs(t, err, ErrOverCapacity)
+	assert.Equal(t, blockedBefore+1, testutil.ToFloat64(blocked))
+	var overCapacity *OverCapacityError
+	if assert.ErrorAs(t, err, &overCapacity) {
+		assert.Equal(t, first.ElementPointer, overCapacity.NodeId)
+		assert.Equal(t, 4, overCapacity.NodeSize)
+	}
+	assert.Equal(t, []string{"a", "b", "b1", "c"}, listAllNames(t, nl, ""))
+
+	// names already stored and writes not splitting still succeed
+	assert.NoError(t, nl.WriteName(context.Background(), "b"))
+
+	_, err = nl.SplitOversizedNodes(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, nl.WriteName(context.Background(), "b2"))
+	assert.Equal(t, []string{"a", "b", "b1", "b2", "c"}, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+}
@@ -0,0 +1,79 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultScanCount is the COUNT of ZSCAN when not given
+const defaultScanCount = 10
+
+// ScanCursor scans the names in order like ZSCAN over one sorted set: start with cursor 0 and pass the returned
+// cursor back until it is 0. As with ZSCAN, count is a hint, whole nodes are returned until at least count names.
+// The cursor is the id of the last node returned, and the scan resumes after the largest name that node holds then,
+// so names moved out of it by a split are not missed, but may be returned twice. A cursor of a node removed
+// since, by a merge or its last name deleted, fails with ErrInvalidCursor.
+func (nl *ItemList) ScanCursor(ctx context.Context, cursor uint64, count int) (names []string, nextCursor uint64, err error) {
+	if count <= 0 {
+		count = defaultScanCount
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return nil, 0, err
+	}
+	startFrom, skipStart := "", false
+	if cursor != 0 {
+		last, ok, err := nl.scanPosition(ctx, int64(cursor))
+		if err != nil {
+			return nil, 0, err
+		}
+		name, inPrefix := nl.realName(last)
+		switch {
+		case !ok:
+			return nil, 0, fmt.Errorf("scan %s cursor %d: %w", nl.prefix, cursor, ErrInvalidCursor)
+		case inPrefix:
+			startFrom, skipStart = name, true
+		case last > nl.namePrefix:
+			// after all names of the prefix
+			return nil, 0, nil
+		}
+	}
+
+	var lastNodeId int64
+	stopped := false
+	// the position is a stored name, even when the stored names are not in name order
+	err = nl.listByNode(ctx, startFrom, func(nodeId int64, nodeNames []string) bool {
+		for _, name := range nodeNames {
+			if skipStart && name == startFrom {
+				continue
+			}
+			names = append(names, name)
+		}
+		skipStart = false
+		lastNodeId = nodeId
+		stopped = len(names) >= count
+		return !stopped
+	})
+	if err != nil || !stopped {
+		return names, 0, err
+	}
+	return names, uint64(lastNodeId), nil
+}
+
+// scanPosition returns the largest stored name of a node, or its key if empty, and whether the node still exists
+func (nl *ItemList) scanPosition(ctx context.Context, nodeId int64) (string, bool, error) {
+	node, err := nl.loadElement(ctx, nodeId)
+	if err != nil || node == nil {
+		return "", false, err
+	}
+	key := nl.nodeKey(nodeId)
+	largest, err := nl.zRevRangeByLex(ctx, key, &redis.ZRangeBy{Min: "-", Max: "+", Count: 1}).Result()
+	if err != nil {
+		return "", false, wrapNodeKeyError(key, err)
+	}
+	if len(largest) == 0 {
+		return string(node.Key), true, nil
+	}
+	return largest[0], true, nil
+}
@@ -0,0 +1,67 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanCursor(t *testing.T) {
+	nl, _ := newTestItemList(t, 5)
+	var all []string
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("name%02d", i*2)
+		all = append(all, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	scan := func(nl *ItemList, count int, between func(cursor uint64)) (names []string) {
+		var cursor uint64
+		for calls := 0; calls < 100; calls++ {
+			page, next, err := nl.ScanCursor(context.Background(), cursor, count)
+			assert.NoError(t, err)
+			names = append(names, page...)
+			if next == 0 {
+				return names
+			}
+			if between != nil {
+				between(next)
+			}
+			cursor = next
+		}
+		t.Fatal("scan did not end")
+		return nil
+	}
+	assert.Equal(t, all, scan(nl, 7, nil))
+	assert.Equal(t, all, scan(nl, 1000, nil))
+	assert.Equal(t, all, scan(nl, 0, nil))
+
+	// splits of the last returned node between calls may repeat names, but do not miss any
+	var written []string
+	seen := scan(nl, 7, func(cursor uint64) {
+		for _, name := range mustNodeNames(t, nl, &skiplist.SkipListElementReference{ElementPointer: int64(cursor)}) {
+			written = append(written, name+"x")
+			assert.NoError(t, nl.WriteName(context.Background(), name+"x"))
+		}
+	})
+	for _, name := range all {
+		assert.Contains(t, seen, name)
+	}
+	assert.Subset(t, listAllNames(t, nl, ""), seen)
+	assert.NotEmpty(t, written)
+
+	// the cursor of a removed node
+	_, cursor, err := nl.ScanCursor(context.Background(), 0, 1)
+	assert.NoError(t, err)
+	for _, name := range mustNodeNames(t, nl, &skiplist.SkipListElementReference{ElementPointer: int64(cursor)}) {
+		assert.NoError(t, nl.DeleteName(context.Background(), name))
+	}
+	_, _, err = nl.ScanCursor(context.Background(), cursor, 1)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	prefixed := withOptions(nl, WithNamePrefix("name4"))
+	assert.Subset(t, listAllNames(t, prefixed, ""), []string{"0", "2", "4", "6", "8"})
+	assert.Equal(t, listAllNames(t, prefixed, ""), scan(prefixed, 3, nil))
+}
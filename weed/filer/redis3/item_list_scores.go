@@ -0,0 +1,233 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+/*
+With WithNameScores, "<prefix>scores" is a hash from each stored name to its score.
+The scores can not be the member scores of the node sets, since the lex commands need all members at score 0.
+UpsertName writes the name before its score, so a score is only seen for a listed name.
+If writing the score fails, the name is kept without a score, and the upsert can be retried.
+*/
+
+const nameScoresSuffix = "scores"
+
+func (nl *ItemList) nameScoresKey() string {
+	return nl.prefix + nameScoresSuffix
+}
+
+// UpsertName adds name if missing, and sets its score, overwriting any previous one.
+// Adding a new smallest name moves the leading key of the first node, the same as WriteName.
+// With WithWriteBuffer, the name is flushed, with the names buffered before it, ahead of its score.
+func (nl *ItemList) UpsertName(ctx context.Context, name string, score float64) error {
+	if !nl.nameScores {
+		return ErrNoNameScores
+	}
+	outcome, err := nl.addName(ctx, name)
+	if err != nil {
+		return err
+	}
+	// with WithWriteBuffer, the name is written before its score, so no name is scored but not listed
+	if nl.writeBuffer.holds(nl.storedName(name)) {
+		if err := nl.flush(ctx); err != nil {
+			return err
+		}
+	}
+	if err := nl.client.HSet(ctx, nl.nameScoresKey(), nl.storedName(name), score).Err(); err != nil {
+		return fmt.Errorf("set score of %s: %w", name, wrapRedisError(err))
+	}
+	if outcome.added {
+		// bumped by the write
+		return nil
+	}
+	return nl.bumpDirectoryVersion(ctx)
+}
+
+// NameScore returns the score set by UpsertName, found is false for a name without a score.
+func (nl *ItemList) NameScore(ctx context.Context, name string) (score float64, found bool, err error) {
+	if !nl.nameScores {
+		return 0, false, ErrNoNameScores
+	}
+	value, err := nl.client.HGet(ctx, nl.nameScoresKey(), nl.storedName(name)).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("get score of %s: %w", name, wrapRedisError(err))
+	}
+	score, err = strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("parse score of %s: %v", name, err)
+	}
+	return score, true, nil
+}
+
+// compareAndSetScoreScript sets the score of ARGV[1] in the hash KEYS[#KEYS] to ARGV[3] if it is ARGV[2],
+// returning 1 if it did and 0 if not, or -1 if ARGV[1] is not a member of the node set KEYS[1], when given
+var compareAndSetScoreScript = redis.NewScript(`
+if #KEYS > 1 and not redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	return -1
+end
+local score = redis.call('HGET', KEYS[#KEYS], ARGV[1])
+if not score or tonumber(score) ~= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call('HSET', KEYS[#KEYS], ARGV[1], ARGV[3])
+return 1
+`)
+
+// CompareAndSetScore sets the score of name to newScore only if it is expected, reporting whether it did,
+// e.g. for optimistic concurrency on a version kept as the score. A name without a score matches no expected score,
+// and a name not in the list fails with ErrNameNotFound. The check of the name and the set are one script,
+// except in cluster mode without HashTaggedPrefix, where the node set is read first, so a concurrent delete
+// can leave the score of a deleted name, which the next UpsertName of the name overwrites.
+func (nl *ItemList) CompareAndSetScore(ctx context.Context, name string, expected, newScore float64) (bool, error) {
+	if !nl.nameScores {
+		return false, ErrNoNameScores
+	}
+	if nl.readOnly {
+		return false, ErrReadOnly
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return false, err
+	}
+	stored := nl.storedName(name)
+	node, err := nl.nodeOf(ctx, stored)
+	if err != nil {
+		return false, err
+	}
+	if node == nil {
+		return false, fmt.Errorf("set score of %s: %w", name, ErrNameNotFound)
+	}
+	memberKey := nl.memberKey(nl.nodeKey(node.ElementPointer), stored)
+	keys := []string{memberKey, nl.nameScoresKey()}
+	if nl.isCluster() && !nl.isColocated() {
+		if err := nl.client.ZScore(ctx, memberKey, stored).Err(); err == redis.Nil {
+			return false, fmt.Errorf("set score of %s: %w", name, ErrNameNotFound)
+		} else if err != nil {
+			return false, fmt.Errorf("set score of %s: %w", name, wrapNodeKeyError(memberKey, err))
+		}
+		keys = keys[1:]
+	}
+	set, err := compareAndSetScoreScript.Run(ctx, nl.client, keys, stored, expected, newScore).Int64()
+	if err != nil {
+		return false, fmt.Errorf("set score of %s: %w", name, wrapRedisError(err))
+	}
+	switch set {
+	case -1:
+		return false, fmt.Errorf("set score of %s: %w", name, ErrNameNotFound)
+	case 0:
+		return false, nil
+	}
+	return true, nl.bumpDirectoryVersion(ctx)
+}
+
+// ListNamesModifiedSince visits, in name order, the names whose score set by UpsertName is at least sinceScore,
+// e.g. names with their mtime as score for an incremental sync. Names without a score are skipped.
+// The nodes order the names, not the scores, so every node is scanned and the score of each of its names read,
+// which costs O(node count) round trips however few names match.
+func (nl *ItemList) ListNamesModifiedSince(ctx context.Context, sinceScore float64, visitFn func(name string, score float64) bool) error {
+	if !nl.nameScores {
+		return ErrNoNameScores
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+	return nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		names, err := nl.nodeRangeInclusiveAfter(ctx, node.Reference(), "")
+		if err != nil {
+			return false, err
+		}
+		if len(names) > 0 {
+			values, err := nl.client.HMGet(ctx, nl.nameScoresKey(), names...).Result()
+			if err != nil {
+				return false, fmt.Errorf("get scores of node %d: %w", node.Id, wrapRedisError(err))
+			}
+			for i, value := range values {
+				s, ok := value.(string)
+				if !ok {
+					continue
+				}
+				score, err := strconv.ParseFloat(s, 64)
+				if err != nil {
+					return false, fmt.Errorf("parse score of %s: %v", names[i], err)
+				}
+				name, ok, err := nl.listedName(names[i])
+				if err != nil {
+					return false, err
+				}
+				if !ok || score < sinceScore {
+					continue
+				}
+				if !visitFn(name, score) {
+					return false, nil
+				}
+			}
+		}
+		return true, nil
+	})
+}
+
+// ScoredName is a name with the score set by UpsertName, HasScore is false for a name without a score.
+type ScoredName struct {
+	Name     string
+	Score    float64
+	HasScore bool
+}
+
+// ScoredPage is Page returning each name with its score, e.g. a name and its mtime for a directory listing.
+// The member scores of the nodes are all 0, so the scores of the whole page, across any node boundaries,
+// are read in one HMGET after the names, one round trip instead of one NameScore per name.
+func (nl *ItemList) ScoredPage(ctx context.Context, marker string, pageSize int) (page []ScoredName, nextMarker string, hasMore bool, err error) {
+	if !nl.nameScores {
+		return nil, "", false, ErrNoNameScores
+	}
+	names, nextMarker, hasMore, err := nl.Page(ctx, marker, pageSize)
+	if err != nil || len(names) == 0 {
+		return nil, nextMarker, hasMore, err
+	}
+	fields := make([]string, len(names))
+	for i, name := range names {
+		fields[i] = nl.storedName(name)
+	}
+	values, err := nl.client.HMGet(ctx, nl.nameScoresKey(), fields...).Result()
+	if err != nil {
+		return nil, "", false, fmt.Errorf("get scores of page after %s: %w", marker, wrapRedisError(err))
+	}
+	page = make([]ScoredName, len(names))
+	for i, value := range values {
+		page[i].Name = names[i]
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if page[i].Score, err = strconv.ParseFloat(s, 64); err != nil {
+			return nil, "", false, fmt.Errorf("parse score of %s: %v", names[i], err)
+		}
+		page[i].HasScore = true
+	}
+	return page, nextMarker, hasMore, nil
+}
+
+func (nl *ItemList) deleteNameScore(ctx context.Context, name string) error {
+	if !nl.nameScores {
+		return nil
+	}
+	if err := nl.client.HDel(ctx, nl.nameScoresKey(), name).Err(); err != nil {
+		return fmt.Errorf("delete score of %s: %w", name, wrapRedisError(err))
+	}
+	return nil
+}
+
+func (nl *ItemList) deleteNameScores(ctx context.Context) error {
+	if !nl.nameScores {
+		return nil
+	}
+	return wrapRedisError(nl.client.Del(ctx, nl.nameScoresKey()).Err())
+}
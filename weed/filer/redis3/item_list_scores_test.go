@@ -0,0 +1,147 @@
+package redis3
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareAndSetScore(t *testing.T) {
+	plain, _ := newTestItemList(t, 3)
+	_, err := plain.CompareAndSetScore(context.Background(), "a", 0, 1)
+	assert.ErrorIs(t, err, ErrNoNameScores)
+
+	for _, opts := range [][]ItemListOption{{WithNameScores()}, {WithNameScores(), WithNodeShards(1)}} {
+		nl, _ := newTestItemList(t, 2, opts...)
+		for i, name := range []string{"b", "d", "f", "h"} {
+			assert.NoError(t, nl.UpsertName(context.Background(), name, float64(i)))
+		}
+		assert.NoError(t, nl.WriteName(context.Background(), "x"))
+
+		set, err := nl.CompareAndSetScore(context.Background(), "d", 1, 1.5)
+		assert.NoError(t, err)
+		assert.True(t, set)
+		score, _, err := nl.NameScore(context.Background(), "d")
+		assert.NoError(t, err)
+		assert.Equal(t, 1.5, score)
+
+		// a stale expected score, or none stored, does not match
+		set, err = nl.CompareAndSetScore(context.Background(), "d", 1, 2)
+		assert.NoError(t, err)
+		assert.False(t, set)
+		set, err = nl.CompareAndSetScore(context.Background(), "x", 0, 2)
+		assert.NoError(t, err)
+		assert.False(t, set)
+		score, _, err = nl.NameScore(context.Background(), "d")
+		assert.NoError(t, err)
+		assert.Equal(t, 1.5, score)
+
+		for _, absent := range []string{"a", "e", "z"} {
+			_, err = nl.CompareAndSetScore(context.Background(), absent, 0, 1)
+			assert.ErrorIs(t, err, ErrNameNotFound, absent)
+		}
+		_, err = withOptions(nl, WithReadOnly()).CompareAndSetScore(context.Background(), "d", 1.5, 2)
+		assert.ErrorIs(t, err, ErrReadOnly)
+		assertItemListConsistent(t, nl)
+	}
+}
+
+func TestUpsertName(t *testing.T) {
+	plain, _ := newTestItemList(t, 3)
+	assert.ErrorIs(t, plain.UpsertName(context.Background(), "a", 1), ErrNoNameScores)
+
+	nl, server := newTestItemList(t, 2, WithNameScores())
+	for i, name := range []string{"d", "e", "f"} {
+		assert.NoError(t, nl.UpsertName(context.Background(), name, float64(i)))
+	}
+	// a new smallest name becomes the leading key of the first node
+	assert.NoError(t, nl.UpsertName(context.Background(), "a", 7))
+	assert.NoError(t, nl.UpsertName(context.Background(), "e", 2.5))
+	assert.Equal(t, []string{"a", "d", "e", "f"}, listAllNames(t, nl, ""))
+	assert.Equal(t, "a", string(nl.skipList.StartLevels[0].Key))
+	assertItemListConsistent(t, nl)
+
+	score, found, err := nl.NameScore(context.Background(), "e")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 2.5, score)
+	score, found, err = nl.NameScore(context.Background(), "a")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, float64(7), score)
+
+	// member scores stay 0
+	for _, key := range server.Keys() {
+		if strings.HasSuffix(key, "m") {
+			members, err := server.ZMembers(key)
+			assert.NoError(t, err)
+			for _, member := range members {
+				memberScore, err := server.ZScore(key, member)
+				assert.NoError(t, err)
+				assert.Zero(t, memberScore)
+			}
+		}
+	}
+
+	assert.NoError(t, nl.DeleteName(context.Background(), "e"))
+	_, found, err = nl.NameScore(context.Background(), "e")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// names with a score from 2, in name order, and without a score are skipped
+	assert.NoError(t, nl.WriteName(context.Background(), "b"))
+	assert.ErrorIs(t, plain.ListNamesModifiedSince(context.Background(), 0, nil), ErrNoNameScores)
+	modified := map[string]float64{}
+	var order []string
+	assert.NoError(t, nl.ListNamesModifiedSince(context.Background(), 2, func(name string, score float64) bool {
+		modified[name] = score
+		order = append(order, name)
+		return true
+	}))
+	assert.Equal(t, map[string]float64{"a": 7, "f": 2}, modified)
+	assert.Equal(t, []string{"a", "f"}, order)
+	order = nil
+	assert.NoError(t, nl.ListNamesModifiedSince(context.Background(), 0, func(name string, score float64) bool {
+		order = append(order, name)
+		return false
+	}))
+	assert.Equal(t, []string{"a"}, order)
+
+	assert.NoError(t, nl.RemoteAllListElement(context.Background()))
+	assert.False(t, server.Exists(nl.nameScoresKey()))
+}
+
+func TestScoredPage(t *testing.T) {
+	nl, _ := newTestItemList(t, 3, WithNameScores())
+	for i, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		assert.NoError(t, nl.UpsertName(context.Background(), name, float64(i)))
+	}
+	assert.NoError(t, nl.WriteName(context.Background(), "cc"))
+	assert.Greater(t, countNodes(t, nl), 2)
+
+	reads := &countCommands{name: "hmget"}
+	nl.client.AddHook(reads)
+	page, nextMarker, hasMore, err := nl.ScoredPage(context.Background(), "b", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []ScoredName{
+		{Name: "c", Score: 2, HasScore: true},
+		{Name: "cc"},
+		{Name: "d", Score: 3, HasScore: true},
+		{Name: "e", Score: 4, HasScore: true},
+		{Name: "f", Score: 5, HasScore: true},
+	}, page)
+	assert.Equal(t, "f", nextMarker)
+	assert.True(t, hasMore)
+	assert.Equal(t, int64(1), reads.count.Load())
+
+	page, _, hasMore, err = nl.ScoredPage(context.Background(), nextMarker, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []ScoredName{{Name: "g", Score: 6, HasScore: true}}, page)
+	assert.False(t, hasMore)
+
+	plain, _ := newTestItemList(t, 3)
+	_, _, _, err = plain.ScoredPage(context.Background(), "", 5)
+	assert.ErrorIs(t, err, ErrNoNameScores)
+}
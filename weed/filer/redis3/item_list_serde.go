@@ -7,38 +7,41 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
-func LoadItemList(data []byte, prefix string, client redis.UniversalClient, store skiplist.ListStore, batchSize int) *ItemList {
+func LoadItemList(data []byte, prefix string, client redis.UniversalClient, store skiplist.ListStore, batchSize int, opts ...ItemListOption) (*ItemList, error) {
 
-	nl := &ItemList{
-		skipList:  skiplist.New(store),
-		batchSize: batchSize,
-		client:    client,
-		prefix:    prefix,
+	nl, err := newItemList(client, prefix, store, batchSize, opts...)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(data) == 0 {
-		return nl
+		return nl, nil
 	}
 
-	message := &skiplist.SkipListProto{}
-	if err := proto.Unmarshal(data, message); err != nil {
+	if err := decodeSkipListHeader(nl.skipList, data); err != nil {
 		glog.Errorf("loading skiplist: %v", err)
 	}
-	nl.skipList.MaxNewLevel = int(message.MaxNewLevel)
-	nl.skipList.MaxLevel = int(message.MaxLevel)
+	return nl, nil
+}
+
+func decodeSkipListHeader(t *skiplist.SkipList, data []byte) error {
+	message := &skiplist.SkipListProto{}
+	err := proto.Unmarshal(data, message)
+	t.MaxNewLevel = int(message.MaxNewLevel)
+	t.MaxLevel = int(message.MaxLevel)
 	for i, ref := range message.StartLevels {
-		nl.skipList.StartLevels[i] = &skiplist.SkipListElementReference{
+		t.StartLevels[i] = &skiplist.SkipListElementReference{
 			ElementPointer: ref.ElementPointer,
 			Key:            ref.Key,
 		}
 	}
 	for i, ref := range message.EndLevels {
-		nl.skipList.EndLevels[i] = &skiplist.SkipListElementReference{
+		t.EndLevels[i] = &skiplist.SkipListElementReference{
 			ElementPointer: ref.ElementPointer,
 			Key:            ref.Key,
 		}
 	}
-	return nl
+	return err
 }
 
 func (nl *ItemList) HasChanges() bool {
@@ -46,10 +49,14 @@ func (nl *ItemList) HasChanges() bool {
 }
 
 func (nl *ItemList) ToBytes() []byte {
+	return encodeSkipListHeader(nl.skipList)
+}
+
+func encodeSkipListHeader(t *skiplist.SkipList) []byte {
 	message := &skiplist.SkipListProto{}
-	message.MaxNewLevel = int32(nl.skipList.MaxNewLevel)
-	message.MaxLevel = int32(nl.skipList.MaxLevel)
-	for _, ref := range nl.skipList.StartLevels {
+	message.MaxNewLevel = int32(t.MaxNewLevel)
+	message.MaxLevel = int32(t.MaxLevel)
+	for _, ref := range t.StartLevels {
 		if ref == nil {
 			break
 		}
@@ -58,7 +65,7 @@ func (nl *ItemList) ToBytes() []byte {
 			Key:            ref.Key,
 		})
 	}
-	for _, ref := range nl.skipList.EndLevels {
+	for _, ref := range t.EndLevels {
 		if ref == nil {
 			break
 		}
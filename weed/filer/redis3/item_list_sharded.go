@@ -0,0 +1,219 @@
+package redis3
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+/*
+ShardedItemList spreads the names of one directory over K ItemLists, each with its own prefix
+"<prefix>shard<i>." and list header, picked by a hash of the name. Writes to different shards
+run concurrently, so a bulk import is not serialized by one skiplist.
+
+The price is on reads: every listing merges K sorted streams, loading at least one node per shard,
+and Exists style lookups must not assume an order across shards.
+When the import is done, Collapse writes all names into one ItemList and deletes the shards.
+
+The sharded list only serializes writes of this process. Across processes, hold the directory lock.
+*/
+type ShardedItemList struct {
+	client redis.UniversalClient
+	prefix string
+	shards []*ItemList
+	locks  []sync.Mutex
+}
+
+func shardPrefix(prefix string, shard int) string {
+	return fmt.Sprintf("%sshard%d.", prefix, shard)
+}
+
+// LoadShardedItemList loads the list headers of shardCount shards under prefix.
+func LoadShardedItemList(ctx context.Context, client redis.UniversalClient, prefix string, shardCount int, batchSize int, opts ...ItemListOption) (*ShardedItemList, error) {
+	if shardCount <= 0 {
+		return nil, fmt.Errorf("invalid shard count %d", shardCount)
+	}
+	sl := &ShardedItemList{
+		client: client,
+		prefix: prefix,
+		shards: make([]*ItemList, shardCount),
+		locks:  make([]sync.Mutex, shardCount),
+	}
+	// the shards share a client, and so its state, unless given one
+	opts = append([]ItemListOption{WithClientState(NewClientState(client))}, opts...)
+	for i := range sl.shards {
+		key := shardPrefix(prefix, i)
+		data, err := client.Get(ctx, key).Result()
+		if err != nil && err != redis.Nil {
+			return nil, fmt.Errorf("read %s: %w", key, wrapRedisError(err))
+		}
+		if sl.shards[i], err = LoadItemList([]byte(data), key, client, newSkipListElementStore(key, client), batchSize, opts...); err != nil {
+			return nil, err
+		}
+	}
+	return sl, nil
+}
+
+func (sl *ShardedItemList) shardOf(name string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return int(h.Sum32() % uint32(len(sl.shards)))
+}
+
+func (sl *ShardedItemList) WriteName(ctx context.Context, name string) error {
+	i := sl.shardOf(name)
+	sl.locks[i].Lock()
+	defer sl.locks[i].Unlock()
+	return sl.shards[i].WriteName(ctx, name)
+}
+
+func (sl *ShardedItemList) DeleteName(ctx context.Context, name string) error {
+	i := sl.shardOf(name)
+	sl.locks[i].Lock()
+	defer sl.locks[i].Unlock()
+	return sl.shards[i].DeleteName(ctx, name)
+}
+
+// WriteNames adds names, writing to all shards concurrently. It returns the first error.
+func (sl *ShardedItemList) WriteNames(ctx context.Context, names []string) error {
+	shardNames := make([][]string, len(sl.shards))
+	for _, name := range names {
+		i := sl.shardOf(name)
+		shardNames[i] = append(shardNames[i], name)
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(sl.shards))
+	for i := range sl.shards {
+		if len(shardNames[i]) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sl.locks[i].Lock()
+			defer sl.locks[i].Unlock()
+			errs[i] = sl.shards[i].WriteNames(ctx, shardNames[i])
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save persists the list headers of the changed shards.
+func (sl *ShardedItemList) Save(ctx context.Context) error {
+	for i, shard := range sl.shards {
+		sl.locks[i].Lock()
+		var err error
+		if shard.HasChanges() {
+			err = sl.client.Set(ctx, shard.prefix, shard.ToBytes(), 0).Err()
+		}
+		sl.locks[i].Unlock()
+		if err != nil {
+			return fmt.Errorf("save %s: %w", shard.prefix, wrapRedisError(err))
+		}
+	}
+	return nil
+}
+
+// ListNames visits the names of all shards in order, starting from startFrom inclusively.
+// Writes wait for the listing, so visitNamesFn must not write to the sharded list.
+// Once ctx is done, the listing stops with its error before the next name.
+func (sl *ShardedItemList) ListNames(ctx context.Context, startFrom string, visitNamesFn func(name string) bool) error {
+	for i := range sl.locks {
+		sl.locks[i].Lock()
+		defer sl.locks[i].Unlock()
+	}
+	merged := &nameIteratorHeap{}
+	for _, shard := range sl.shards {
+		it := shard.Iterator(ctx, startFrom)
+		if it.Next() {
+			merged.iterators = append(merged.iterators, it)
+		} else if err := it.Err(); err != nil {
+			return err
+		}
+	}
+	heap.Init(merged)
+	for merged.Len() > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		it := merged.iterators[0]
+		if !visitNamesFn(it.Value()) {
+			return nil
+		}
+		if it.Next() {
+			heap.Fix(merged, 0)
+			continue
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+		heap.Pop(merged)
+	}
+	return nil
+}
+
+// Collapse writes all names, in order, into target and then deletes the shards.
+// The caller persists target with ToBytes(). An interrupted Collapse can be run again.
+func (sl *ShardedItemList) Collapse(ctx context.Context, target *ItemList) error {
+	var writeErr error
+	if err := sl.ListNames(ctx, "", func(name string) bool {
+		writeErr = target.WriteName(ctx, name)
+		return writeErr == nil
+	}); err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	for i, shard := range sl.shards {
+		sl.locks[i].Lock()
+		err := shard.RemoteAllListElement(ctx)
+		if err == nil {
+			err = wrapRedisError(sl.client.Del(ctx, shard.prefix).Err())
+		}
+		sl.locks[i].Unlock()
+		if err != nil {
+			return fmt.Errorf("delete %s: %w", shard.prefix, err)
+		}
+	}
+	glog.V(0).Infof("collapsed %d shards of %s", len(sl.shards), sl.prefix)
+	return nil
+}
+
+// nameIteratorHeap orders iterators by their current name
+type nameIteratorHeap struct {
+	iterators []*NameIterator
+}
+
+func (h *nameIteratorHeap) Len() int {
+	return len(h.iterators)
+}
+
+func (h *nameIteratorHeap) Less(i, j int) bool {
+	return h.iterators[i].Value() < h.iterators[j].Value()
+}
+
+func (h *nameIteratorHeap) Swap(i, j int) {
+	h.iterators[i], h.iterators[j] = h.iterators[j], h.iterators[i]
+}
+
+func (h *nameIteratorHeap) Push(x any) {
+	h.iterators = append(h.iterators, x.(*NameIterator))
+}
+
+func (h *nameIteratorHeap) Pop() any {
+	last := h.iterators[len(h.iterators)-1]
+	h.iterators = h.iterators[:len(h.iterators)-1]
+	return last
+}
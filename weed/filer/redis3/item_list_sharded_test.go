@@ -0,0 +1,53 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedItemList(t *testing.T) {
+	ctx := context.Background()
+	nl, server := newTestItemList(t, 3)
+	sharded, err := LoadShardedItemList(ctx, nl.client, nl.prefix, 4, 3)
+	assert.NoError(t, err)
+
+	var expected []string
+	for i := 0; i < 100; i++ {
+		expected = append(expected, fmt.Sprintf("name%03d", i))
+	}
+	shuffled := append([]string(nil), expected...)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	assert.NoError(t, sharded.WriteNames(context.Background(), shuffled))
+	assert.NoError(t, sharded.DeleteName(context.Background(), "name050"))
+	expected = append(expected[:50], expected[51:]...)
+	for _, shard := range sharded.shards {
+		assert.NotEmpty(t, listAllNames(t, shard, ""))
+	}
+	assert.NoError(t, sharded.Save(ctx))
+
+	// reload from the saved headers
+	sharded, err = LoadShardedItemList(ctx, nl.client, nl.prefix, 4, 3)
+	assert.NoError(t, err)
+	listSharded := func(startFrom string) (names []string) {
+		assert.NoError(t, sharded.ListNames(context.Background(), startFrom, func(name string) bool {
+			names = append(names, name)
+			return true
+		}))
+		return names
+	}
+	assert.Equal(t, expected, listSharded(""))
+	assert.Equal(t, expected[20:], listSharded("name020"))
+
+	assert.NoError(t, sharded.Collapse(ctx, nl))
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+	for _, key := range server.Keys() {
+		assert.NotContains(t, key, "shard")
+	}
+}
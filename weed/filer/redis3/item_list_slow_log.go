@@ -0,0 +1,30 @@
+package redis3
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// logSlowOperation logs an operation which took at least the WithSlowOperationLog threshold, with the details
+// its span records, e.g. the write case and node of a WriteName, or the nodes scanned by a ListNames
+func (nl *ItemList) logSlowOperation(op string, elapsed time.Duration, err error, attrs []attribute.KeyValue) {
+	stats.FilerStoreCounter.WithLabelValues("redis3", "slow_operation").Inc()
+	var details strings.Builder
+	for _, attr := range attrs {
+		value := attr.Value.Emit()
+		if attr.Value.Type() == attribute.STRING {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(&details, " %s=%s", strings.TrimPrefix(string(attr.Key), "redis3."), value)
+	}
+	if err != nil {
+		fmt.Fprintf(&details, " error=%q", err.Error())
+	}
+	glog.Warningf("slow %s %s: %v%s", op, nl.prefix, elapsed, details.String())
+}
@@ -0,0 +1,32 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowOperationLog(t *testing.T) {
+	slow := stats.FilerStoreCounter.WithLabelValues("redis3", "slow_operation")
+	nl, _ := newTestItemList(t, 2, WithSlowOperationLog(time.Hour))
+	before := testutil.ToFloat64(slow)
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	assert.Equal(t, []string{"a"}, listAllNames(t, nl, ""))
+	assert.Equal(t, before, testutil.ToFloat64(slow))
+
+	nl, _ = newTestItemList(t, 2, WithSlowOperationLog(time.Nanosecond), WithListingPrefetch(2))
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	assert.NoError(t, nl.DeleteName(context.Background(), "e"))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, listAllNames(t, nl, ""))
+	assert.Equal(t, before+7, testutil.ToFloat64(slow))
+	assert.NotNil(t, nl.commandStats)
+
+	_, err := NewItemList(Config{Client: nl.client, Prefix: "/test/config", SlowOperationThreshold: -time.Second})
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+}
@@ -0,0 +1,77 @@
+package redis3
+
+import (
+	"context"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// SplitOversizedNodes splits each node holding more than batchSize names, e.g. left over by a failed split
+// and reported in VerifyReport.OverCapacityNodes, into nodes of batchSize names, returning how many nodes it split.
+// The tail of a node is moved to a new node first and then removed from the node, as WriteName splits,
+// so an interrupted run leaves names in two nodes, which the next run resolves. Running it again is a no-op.
+func (nl *ItemList) SplitOversizedNodes(ctx context.Context) (split int, err error) {
+	if nl.readOnly {
+		return 0, ErrReadOnly
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return 0, err
+	}
+	err = nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		key := nl.nodeKey(node.Id)
+		count, err := nl.zLexCountAll(ctx, nl.client, key).Result()
+		if err != nil {
+			return false, wrapNodeKeyError(key, err)
+		}
+		if count > int64(nl.batchSize) {
+			if err := nl.splitOversizedNode(ctx, node.Reference()); err != nil {
+				return false, err
+			}
+			split++
+		}
+		// the nodes split off are after node and before its next node
+		return true, nil
+	})
+	if err != nil {
+		return split, err
+	}
+	if split > 0 {
+		glog.V(0).Infof("split %s: split %d oversized nodes", nl.prefix, split)
+	}
+	return split, nil
+}
+
+// splitOversizedNode moves the names of node past batchSize to new nodes, the last piece first
+func (nl *ItemList) splitOversizedNode(ctx context.Context, node *skiplist.SkipListElementReference) error {
+	end, err := nl.beginStructuralChange(ctx)
+	if err != nil {
+		return err
+	}
+	defer end()
+	names, err := nl.nodeRangeInclusiveAfter(ctx, node, "")
+	if err != nil {
+		return err
+	}
+	for len(names) > nl.batchSize {
+		start := (len(names) - 1) / nl.batchSize * nl.batchSize
+		piece := names[start:]
+		_, next, found, err := nl.resolveNeighbors(ctx, piece[0])
+		if err != nil {
+			return err
+		}
+		if found && string(next.Key) == piece[0] {
+			// added by an interrupted run
+			if err := nl.nodeAddMember(ctx, next.Reference(), piece...); err != nil {
+				return err
+			}
+		} else if _, err := nl.itemAdd(ctx, []byte(piece[0]), 0, piece...); err != nil {
+			return err
+		}
+		if err := nl.nodeDeleteAfterExclusive(ctx, node, names[start-1]); err != nil {
+			return err
+		}
+		names = names[:start]
+	}
+	return nil
+}
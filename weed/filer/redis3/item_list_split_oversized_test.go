@@ -0,0 +1,51 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitOversizedNodes(t *testing.T) {
+	nl, _ := newTestItemList(t, 3, WithMaintainedNodeCount())
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	assert.NoError(t, nl.WriteName(context.Background(), "z"))
+	first := nl.skipList.StartLevels[0]
+	var expected []string
+	for i := 0; i < 8; i++ {
+		expected = append(expected, fmt.Sprintf("b%d", i))
+	}
+	// an oversized first node, as left by a failed split
+	assert.NoError(t, nl.NodeAddMember(first, expected...))
+	expected = append(append([]string{"a"}, expected...), "z")
+
+	report, err := nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, report.OverCapacityNodes, 1)
+
+	split, err := nl.SplitOversizedNodes(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, split)
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+	assert.Equal(t, []string{"a", "b0", "b1"}, mustNodeNames(t, nl, first))
+
+	split, err = nl.SplitOversizedNodes(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, split)
+
+	// resume after the tail was copied to its new node but not removed from the node
+	last := nl.skipList.GetLargestNodeReference()
+	assert.NoError(t, nl.NodeAddMember(first, mustNodeNames(t, nl, last)...))
+	split, err = nl.SplitOversizedNodes(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, split)
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+	report, err = nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, report.IsConsistent())
+	assert.Empty(t, report.NodeCountFixed)
+}
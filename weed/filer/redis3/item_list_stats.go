@@ -0,0 +1,32 @@
+package redis3
+
+import "time"
+
+// ItemListStats is a snapshot of an ItemList, taken without any Redis call.
+type ItemListStats struct {
+	// Cluster tells whether the client is a Redis Cluster client, with slot aware code paths
+	Cluster   bool
+	BatchSize int
+	// Commands, RoundTrips and RedisTime count what the client sent, for all ItemLists sharing it.
+	// They stay zero without WithCommandStats. A pipeline is one round trip.
+	Commands   int64
+	RoundTrips int64
+	RedisTime  time.Duration
+	// RedisErrors counts the failed commands by type: timeout, connection_refused, moved (MOVED or ASK),
+	// wrong_type, noscript and other, leaving out the types without failures. It is nil without WithCommandStats
+	RedisErrors map[string]int64
+}
+
+func (nl *ItemList) Stats() ItemListStats {
+	stats := ItemListStats{
+		Cluster:   nl.isCluster(),
+		BatchSize: nl.batchSize,
+	}
+	if nl.commandStats != nil {
+		stats.Commands = nl.commandStats.commands.Load()
+		stats.RoundTrips = nl.commandStats.roundTrips.Load()
+		stats.RedisTime = time.Duration(nl.commandStats.nanos.Load())
+		stats.RedisErrors = nl.commandStats.redisErrors()
+	}
+	return stats
+}
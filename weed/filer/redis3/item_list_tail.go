@@ -0,0 +1,81 @@
+package redis3
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// TailNames visits the last limit names in descending order, e.g. the newest entries of a log style directory.
+// It starts from the last node and walks back with ZREVRANGEBYLEX, reading about limit/batchSize nodes
+// and never the front of the list. With WithNamePrefix, it starts from the last node holding the prefix.
+func (nl *ItemList) TailNames(ctx context.Context, limit int, visitNamesFn func(name string) bool) error {
+	if limit <= 0 {
+		return nil
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+	lexMin, max := "-", "+"
+	var nodeRef *skiplist.SkipListElementReference
+	var err error
+	if nl.namePrefix != "" {
+		lexMin = "[" + nl.namePrefix
+		if end, ok := prefixEnd(nl.namePrefix); ok {
+			max = "(" + end
+			nodeRef, err = nl.nodeOf(ctx, end)
+		} else {
+			nodeRef, err = nl.largestNodeReference(ctx)
+		}
+	} else {
+		nodeRef, err = nl.largestNodeReference(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	t := nl.skipList
+	guard := nl.newChainGuard(ctx)
+	for nodeRef != nil && limit > 0 {
+		if err := guard.step(); err != nil {
+			return err
+		}
+		key := nl.nodeKey(nodeRef.ElementPointer)
+		// in chunks, as nodeScanInclusiveAfter
+		nodeMax := max
+		for limit > 0 {
+			chunk := min(int64(limit), nl.nodeScanChunk())
+			names, err := nl.zRevRangeByLex(ctx, key, &redis.ZRangeBy{Min: lexMin, Max: nodeMax, Count: chunk}).Result()
+			if err != nil {
+				return wrapNodeKeyError(key, err)
+			}
+			for _, name := range names {
+				if !visitNamesFn(nl.realNameOf(name)) {
+					return nil
+				}
+				limit--
+			}
+			if int64(len(names)) < chunk {
+				break
+			}
+			if chunk == nl.nodeScanChunk() {
+				nl.warnOversizedNode(key)
+			}
+			nodeMax = "(" + names[len(names)-1]
+		}
+		if nl.namePrefix != "" && string(nodeRef.Key) <= nl.namePrefix {
+			// the earlier nodes only hold names before the prefix
+			return nil
+		}
+		node, err := t.LoadElementContext(ctx, nodeRef)
+		if err != nil {
+			return err
+		}
+		if node == nil {
+			return nil
+		}
+		nodeRef = node.Prev
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTailNames(t *testing.T) {
+	tail := func(nl *ItemList, limit int) (names []string) {
+		assert.NoError(t, nl.TailNames(context.Background(), limit, func(name string) bool {
+			names = append(names, name)
+			return true
+		}))
+		return names
+	}
+	nl, _ := newTestItemList(t, 3)
+	assert.Empty(t, tail(nl, 5))
+	var all []string
+	for i := 0; i < 100; i++ {
+		name := fmt.Sprintf("name%03d", i)
+		all = append(all, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	reversed := slices.Clone(all)
+	slices.Reverse(reversed)
+
+	nodeReads := &countCommands{name: "zrevrangebylex"}
+	nl.client.AddHook(nodeReads)
+	assert.Equal(t, reversed[:5], tail(nl, 5))
+	assert.LessOrEqual(t, nodeReads.count.Load(), int64(4))
+	assert.Equal(t, reversed, tail(nl, 1000))
+	assert.Empty(t, tail(nl, 0))
+
+	var visited []string
+	assert.NoError(t, nl.TailNames(context.Background(), 10, func(name string) bool {
+		visited = append(visited, name)
+		return len(visited) < 2
+	}))
+	assert.Equal(t, reversed[:2], visited)
+
+	// names of other prefixes, before and after, are skipped
+	prefixed := withOptions(nl, WithNamePrefix("name05"))
+	assert.Equal(t, []string{"9", "8", "7"}, tail(prefixed, 3))
+	assert.Len(t, tail(prefixed, 1000), 10)
+
+	sharded, _ := newTestItemList(t, 3, WithNodeShards(1))
+	for _, name := range all[:10] {
+		assert.NoError(t, sharded.WriteName(context.Background(), name))
+	}
+	assert.Equal(t, reversed[90:94], tail(sharded, 4))
+}
+
+func TestOversizedNodeScanInChunks(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	for _, name := range []string{"a", "b", "c", "z"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	// an oversized node, as left by a failed split
+	first := nl.skipList.StartLevels[0]
+	var expected []string
+	for i := 0; i < 30; i++ {
+		expected = append(expected, fmt.Sprintf("b%02d", i))
+	}
+	assert.NoError(t, nl.NodeAddMember(first, expected...))
+	expected = append(append([]string{"a", "b"}, expected...), "c", "z")
+
+	reads := &countCommands{name: "zrangebylex"}
+	nl.client.AddHook(reads)
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	// the 33 names of the first node in chunks of 4
+	assert.GreaterOrEqual(t, reads.count.Load(), int64(9))
+	_, warned := warnedOversizedNodes.Load(nl.nodeKey(first.ElementPointer))
+	assert.True(t, warned)
+	assert.Equal(t, expected[5:], listAllNames(t, nl, expected[5]))
+
+	var tail []string
+	assert.NoError(t, nl.TailNames(context.Background(), 100, func(name string) bool {
+		tail = append(tail, name)
+		return true
+	}))
+	reversed := slices.Clone(expected)
+	slices.Reverse(reversed)
+	assert.Equal(t, reversed, tail)
+}
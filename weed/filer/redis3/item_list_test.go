@@ -0,0 +1,608 @@
+package redis3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemListEmptyName(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+
+	assert.ErrorIs(t, nl.WriteName(context.Background(), ""), ErrEmptyName)
+	assert.Empty(t, listAllNames(t, nl, ""))
+
+	for _, name := range []string{"b", "a", "c"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	assert.ErrorIs(t, nl.WriteName(context.Background(), ""), ErrEmptyName)
+
+	// an empty startFrom lists from the beginning
+	assert.Equal(t, []string{"a", "b", "c"}, listAllNames(t, nl, ""))
+	assert.NoError(t, nl.DeleteName(context.Background(), ""))
+	assert.Equal(t, []string{"a", "b", "c"}, listAllNames(t, nl, ""))
+}
+
+func TestItemListListNamesWithError(t *testing.T) {
+	nl, _ := newTestItemList(t, 2)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+
+	errWrite := errors.New("write failed")
+	var visited []string
+	err := nl.ListNamesWithError(context.Background(), "", func(name string) (bool, error) {
+		visited = append(visited, name)
+		if name == "c" {
+			return true, errWrite
+		}
+		return true, nil
+	})
+	assert.ErrorIs(t, err, errWrite)
+	assert.Equal(t, []string{"a", "b", "c"}, visited)
+
+	visited = nil
+	err = nl.ListNamesWithError(context.Background(), "c", func(name string) (bool, error) {
+		visited = append(visited, name)
+		return name != "d", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, visited)
+}
+
+func TestItemListWriteNameRemovesEmptyNode(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	for _, name := range []string{"a", "b", "c"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+
+	// simulate a failed split: the skiplist key is inserted but the members are not
+	assert.NoError(t, nl.ItemAdd([]byte("m"), 0))
+
+	assert.NoError(t, nl.WriteName(context.Background(), "n"))
+	assertItemListConsistent(t, nl)
+	assert.Equal(t, []string{"a", "b", "c", "n"}, listAllNames(t, nl, ""))
+}
+
+func TestItemListReadOnly(t *testing.T) {
+	nl, server := newTestItemList(t, 3)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	keys := server.Keys()
+
+	WithReadOnly()(nl)
+	assert.ErrorIs(t, nl.WriteName(context.Background(), "e"), ErrReadOnly)
+	assert.ErrorIs(t, nl.DeleteName(context.Background(), "a"), ErrReadOnly)
+	assert.ErrorIs(t, nl.RemoteAllListElement(context.Background()), ErrReadOnly)
+	first := nl.skipList.StartLevels[0]
+	members := mustNodeNames(t, nl, first)
+	assert.ErrorIs(t, nl.NodeAddMember(first, "a0"), ErrReadOnly)
+	assert.ErrorIs(t, nl.NodeDeleteMember(first, "a"), ErrReadOnly)
+	assert.ErrorIs(t, nl.NodeDelete(first), ErrReadOnly)
+	assert.ErrorIs(t, nl.NodeDeleteBeforeExclusive(first, "b"), ErrReadOnly)
+	assert.ErrorIs(t, nl.NodeDeleteAfterExclusive(first, "a"), ErrReadOnly)
+	assert.ErrorIs(t, nl.ItemAdd([]byte("a0"), 0, "a0"), ErrReadOnly)
+	assert.Equal(t, members, mustNodeNames(t, nl, first))
+	assert.Equal(t, keys, server.Keys())
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, listAllNames(t, nl, ""))
+	report, err := nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, report.IsConsistent())
+}
+
+func TestItemListMaxListingNodes(t *testing.T) {
+	nl, _ := newTestItemList(t, 2, WithMaxListingNodes(2))
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+
+	var names []string
+	startFrom := ""
+	for {
+		err := nl.ListNames(context.Background(), startFrom, func(name string) bool {
+			names = append(names, name)
+			return true
+		})
+		if err == nil {
+			break
+		}
+		var tooLarge *ListingTooLargeError
+		if !assert.ErrorAs(t, err, &tooLarge) {
+			return
+		}
+		assert.ErrorIs(t, err, ErrListingTooLarge)
+		assert.Equal(t, 2, tooLarge.NodesScanned)
+		startFrom = tooLarge.ResumeFrom
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e", "f", "g"}, names)
+}
+
+func TestSplitAndMergeCallbacks(t *testing.T) {
+	type split struct {
+		oldNodeId, newNodeId int64
+		pivot                string
+	}
+	var splits []split
+	var merges [][2]int64
+	nl, _ := newTestItemList(t, 3,
+		WithOnSplit(func(oldNodeId, newNodeId int64, pivot string) {
+			splits = append(splits, split{oldNodeId, newNodeId, pivot})
+		}),
+		WithOnMerge(func(keptNodeId, removedNodeId int64) {
+			merges = append(merges, [2]int64{keptNodeId, removedNodeId})
+		}),
+	)
+
+	for _, name := range []string{"a", "c", "e", "b"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	if assert.Len(t, splits, 1) {
+		assert.Equal(t, "b", splits[0].pivot)
+		assert.NotEqual(t, splits[0].oldNodeId, splits[0].newNodeId)
+	}
+	assertItemListConsistent(t, nl)
+
+	for _, name := range []string{"e", "b"} {
+		assert.NoError(t, nl.DeleteName(context.Background(), name))
+	}
+	assert.Len(t, merges, 1)
+	assert.Equal(t, []string{"a", "c"}, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+
+	// unset callbacks are skipped
+	plain, _ := newTestItemList(t, 2)
+	for _, name := range []string{"a", "c", "b", "d"} {
+		assert.NoError(t, plain.WriteName(context.Background(), name))
+	}
+}
+
+func TestRandomizedOperations(t *testing.T) {
+	for batchSize := 2; batchSize <= 4; batchSize++ {
+		for seed := int64(0); seed < 5; seed++ {
+			nl, _ := newTestItemList(t, batchSize, WithInsertDirection(InsertDirection(seed%4)), WithListingPrefetch(int(seed%3)))
+			r := rand.New(rand.NewSource(seed))
+			model := make(map[string]bool)
+			for step := 0; step < 200; step++ {
+				name := fmt.Sprintf("%03d", r.Intn(60))
+				if r.Intn(3) == 0 {
+					assert.NoError(t, nl.DeleteName(context.Background(), name))
+					delete(model, name)
+				} else {
+					assert.NoError(t, nl.WriteName(context.Background(), name))
+					model[name] = true
+				}
+				assertItemListConsistent(t, nl)
+
+				// list from a random name, which is often inside a node
+				startFrom := fmt.Sprintf("%03d", r.Intn(60))
+				var expected []string
+				for name := range model {
+					if name >= startFrom {
+						expected = append(expected, name)
+					}
+				}
+				sort.Strings(expected)
+				if !assert.Equal(t, expected, listAllNames(t, nl, startFrom), "batch size %d seed %d step %d", batchSize, seed, step) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func TestListNodeBoundaries(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	boundaries, err := nl.ListNodeBoundaries(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, boundaries)
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("name%02d", i)))
+	}
+	boundaries, err = nl.ListNodeBoundaries(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name00", "name03", "name06", "name09"}, boundaries)
+	for _, boundary := range boundaries {
+		assert.Equal(t, boundary, listAllNames(t, nl, boundary)[0])
+	}
+}
+
+func TestWriteNameWithSplit(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	for _, name := range []string{"a", "c", "e"} {
+		split, err := nl.WriteNameWithSplit(context.Background(), name)
+		assert.NoError(t, err)
+		assert.False(t, split, name)
+	}
+	// appending after a full node creates a new node without splitting
+	split, err := nl.WriteNameWithSplit(context.Background(), "g")
+	assert.NoError(t, err)
+	assert.False(t, split)
+
+	split, err = nl.WriteNameWithSplit(context.Background(), "b")
+	assert.NoError(t, err)
+	assert.True(t, split)
+
+	split, err = nl.WriteNameWithSplit(context.Background(), "b")
+	assert.NoError(t, err)
+	assert.False(t, split)
+	assert.Equal(t, []string{"a", "b", "c", "e", "g"}, listAllNames(t, nl, ""))
+}
+
+func TestNewMinimum(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	assert.NoError(t, nl.WriteName(context.Background(), "m"))
+	// merged into the first node, which is re-keyed
+	assert.NoError(t, nl.WriteName(context.Background(), "c"))
+	assert.Equal(t, 1, countNodes(t, nl))
+	assert.Equal(t, "c", string(nl.skipList.StartLevels[0].Key))
+	assert.NoError(t, nl.WriteName(context.Background(), "b"))
+	// the first node is full
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	assert.Equal(t, 2, countNodes(t, nl))
+	assert.Equal(t, []string{"a", "b", "c", "m"}, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+
+	separate, _ := newTestItemList(t, 3, WithNewMinimumInNewNode())
+	assert.NoError(t, separate.WriteName(context.Background(), "m"))
+	assert.NoError(t, separate.WriteName(context.Background(), "c"))
+	assert.Equal(t, 2, countNodes(t, separate))
+	assert.Equal(t, "c", string(separate.skipList.StartLevels[0].Key))
+	// names after the new minimum still fill its node
+	assert.NoError(t, separate.WriteName(context.Background(), "d"))
+	assert.Equal(t, 2, countNodes(t, separate))
+	assert.Equal(t, []string{"c", "d", "m"}, listAllNames(t, separate, ""))
+	assertItemListConsistent(t, separate)
+
+	// descending inserts are all new minimums
+	for _, opts := range [][]ItemListOption{nil, {WithNewMinimumInNewNode()}} {
+		descending, _ := newTestItemList(t, 3, opts...)
+		for i := 8; i >= 0; i-- {
+			assert.NoError(t, descending.WriteName(context.Background(), strconv.Itoa(i)))
+		}
+		if descending.newMinimumInNewNode {
+			assert.Equal(t, 9, countNodes(t, descending))
+		} else {
+			assert.Equal(t, 3, countNodes(t, descending))
+		}
+		assertItemListConsistent(t, descending)
+	}
+}
+
+func TestAscendingAppendsFillNodes(t *testing.T) {
+	const batchSize, n = 10, 1000
+	nl, _ := newTestItemList(t, batchSize)
+	for i := 0; i < n; i++ {
+		result, err := nl.WriteNameDetailed(context.Background(), fmt.Sprintf("%010d.log", i))
+		assert.NoError(t, err)
+		assert.False(t, result.Split, i)
+		if i%batchSize == 0 {
+			assert.Contains(t, []WriteCase{WriteCaseNewNode, WriteCaseSplit}, result.Case, i)
+		} else {
+			assert.Equal(t, WriteCaseAddToNode, result.Case, i)
+		}
+	}
+	assert.Equal(t, n/batchSize, countNodes(t, nl))
+	assertItemListConsistent(t, nl)
+}
+
+func BenchmarkAscendingAppends(b *testing.B) {
+	const batchSize = 1000
+	server := miniredis.RunT(b)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	store := newSkipListElementStore("/bench", client)
+	nl, err := newItemList(client, "/bench", store, batchSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := nl.WriteName(context.Background(), fmt.Sprintf("%012d", i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	nodes := 0
+	for ref := nl.skipList.StartLevels[0]; ref != nil; nodes++ {
+		node, err := nl.skipList.LoadElement(ref)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ref = node.Next[0]
+	}
+	if expected := (b.N + batchSize - 1) / batchSize; nodes != expected {
+		b.Fatalf("%d ascending names in %d nodes, expected %d", b.N, nodes, expected)
+	}
+}
+
+// TestAscendingAppends checks the node count of a fixed run, which the benchmark only does for b.N names
+func TestAscendingAppends(t *testing.T) {
+	if testing.Short() {
+		t.Skip("appends a million names")
+	}
+	const n, batchSize = 1000000, 1000
+	nl, _ := newTestItemList(t, batchSize)
+	for i := 0; i < n; i++ {
+		if err := nl.WriteName(context.Background(), fmt.Sprintf("%012d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.InDelta(t, n/batchSize, countNodes(t, nl), 1)
+}
+
+func TestMissingLargestNode(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	for _, name := range []string{"a", "b", "c", "d"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	last := nl.skipList.GetLargestNodeReference()
+	nodes := countNodes(t, nl)
+
+	// the last node is found by walking, so no node is created
+	nl.skipList.EndLevels[0] = nil
+	assert.NoError(t, nl.WriteName(context.Background(), "e"))
+	assert.Equal(t, nodes, countNodes(t, nl))
+	assert.Contains(t, mustNodeNames(t, nl, last), "e")
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, listAllNames(t, nl, ""))
+
+	nl.skipList.EndLevels[0] = nil
+	assert.ErrorIs(t, withOptions(nl, WithSafeMode()).WriteName(context.Background(), "f"), ErrInconsistentSkiplist)
+}
+
+func TestDeleteNodeElementWithStaleKey(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	for _, name := range []string{"a", "b", "d", "e", "f", "g"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	nodes := countNodes(t, nl)
+
+	// a reference whose key drifted from the key of its element
+	first := nl.skipList.StartLevels[0]
+	stale := &skiplist.SkipListElementReference{ElementPointer: first.ElementPointer, Key: []byte("a0")}
+	assert.NoError(t, nl.deleteNodeElement(context.Background(), stale))
+	assert.Equal(t, nodes-1, countNodes(t, nl))
+	assert.NotEqual(t, first.ElementPointer, nl.skipList.StartLevels[0].ElementPointer)
+
+	// a node whose leading key drifted from its smallest member is removed when its last member is deleted
+	nl, _ = newTestItemList(t, 3)
+	for _, name := range []string{"a", "b", "d", "e", "f", "g"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	nodes = countNodes(t, nl)
+	first = nl.skipList.StartLevels[0]
+	firstNames := mustNodeNames(t, nl, first)
+	assert.Equal(t, "a", firstNames[0])
+	for _, name := range firstNames[1:] {
+		assert.NoError(t, nl.DeleteName(context.Background(), name))
+	}
+	assert.NoError(t, nl.NodeDeleteMember(first, "a"))
+	assert.NoError(t, nl.NodeAddMember(first, "c"))
+	assert.NoError(t, nl.DeleteName(context.Background(), "c"))
+	assert.Equal(t, nodes-1, countNodes(t, nl))
+	assert.Equal(t, []string{"a", "b", "d", "e", "f", "g"}[len(firstNames):], listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+}
+
+func TestItemAddRejectsBogusNodeId(t *testing.T) {
+	nl, _ := newTestItemList(t, 3, WithNodeReuseCheck())
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	first := nl.skipList.StartLevels[0]
+	names := mustNodeNames(t, nl, first)
+	assert.Greater(t, countNodes(t, nl), 1)
+
+	// the id of a node still in the skiplist
+	assert.ErrorIs(t, nl.ItemAdd([]byte("z"), first.ElementPointer, "z"), ErrNodeIdInUse)
+	assert.Equal(t, names, mustNodeNames(t, nl, first))
+
+	// a removed node whose names do not start at the key
+	_, err := nl.skipList.DeleteByKey(first.Key)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, nl.ItemAdd([]byte("0"), first.ElementPointer), ErrNodeIdInUse)
+	assert.ErrorIs(t, nl.ItemAdd([]byte("b0"), first.ElementPointer, "b0"), ErrNodeIdInUse)
+	assert.Equal(t, names, mustNodeNames(t, nl, first))
+
+	// re-keying to a new smallest name, as addToNextNode does
+	assert.NoError(t, nl.ItemAdd([]byte("0"), first.ElementPointer, "0"))
+	assert.Equal(t, append([]string{"0"}, names...), listAllNames(t, nl, "")[:len(names)+1])
+	assertItemListConsistent(t, nl)
+
+	// unchecked by default, without the reads of the check
+	unchecked, _ := newTestItemList(t, 3)
+	assert.NoError(t, unchecked.WriteName(context.Background(), "a"))
+	lexRanges := &countCommands{name: "zrangebylex"}
+	unchecked.client.AddHook(lexRanges)
+	assert.NoError(t, unchecked.ItemAdd([]byte("z"), unchecked.skipList.StartLevels[0].ElementPointer))
+	assert.Zero(t, lexRanges.count.Load())
+}
+
+func TestWriteBeforeStaleKeyPrefersPreviousNode(t *testing.T) {
+	nl, _ := newTestItemList(t, 3)
+	_, err := nl.itemAdd(context.Background(), []byte("a"), 0, "a")
+	assert.NoError(t, err)
+	// a full node keyed before its smallest name
+	_, err = nl.itemAdd(context.Background(), []byte("c"), 0, "e", "f", "g")
+	assert.NoError(t, err)
+
+	for _, name := range []string{"d", "c"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	// both went to the previous node, rather than to two new nodes of one name
+	assert.Equal(t, 2, countNodes(t, nl))
+	assert.Equal(t, []string{"a", "c", "d", "e", "f", "g"}, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+}
+
+func TestListNamesFromNodeBoundary(t *testing.T) {
+	for _, opts := range [][]ItemListOption{nil, {WithListingPrefetch(2)}} {
+		nl, _ := newTestItemList(t, 3, opts...)
+		var names []string
+		for i := 0; i < 20; i++ {
+			name := fmt.Sprintf("%02d", i)
+			names = append(names, name)
+			assert.NoError(t, nl.WriteName(context.Background(), name))
+		}
+		boundaries, err := nl.ListNodeBoundaries(context.Background())
+		assert.NoError(t, err)
+		assert.Greater(t, len(boundaries), 3)
+		for _, boundary := range boundaries {
+			i := sort.SearchStrings(names, boundary)
+			assert.Equal(t, names[i:], listAllNames(t, nl, boundary), "from %s", boundary)
+		}
+
+		// a capped listing resumes from a node boundary
+		capped := mustNewItemList(t, nl.client, nl.prefix, nl.skipList.ListStore, 3, append(opts, WithMaxListingNodes(2))...)
+		capped.skipList = nl.skipList
+		var listed []string
+		startFrom := ""
+		for {
+			err := capped.ListNames(context.Background(), startFrom, func(name string) bool {
+				listed = append(listed, name)
+				return true
+			})
+			var tooLarge *ListingTooLargeError
+			if !errors.As(err, &tooLarge) {
+				assert.NoError(t, err)
+				break
+			}
+			assert.Contains(t, boundaries, tooLarge.ResumeFrom)
+			startFrom = tooLarge.ResumeFrom
+		}
+		assert.Equal(t, names, listed)
+	}
+}
+
+func TestDeleteLeadingNameKeepsNodeNames(t *testing.T) {
+	for _, opts := range [][]ItemListOption{nil, {WithLexFallback()}, {WithNodeShards(1)}} {
+		nl, _ := newTestItemList(t, 5, opts...)
+		names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+		for _, name := range names {
+			assert.NoError(t, nl.WriteName(context.Background(), name))
+		}
+		first := nl.skipList.StartLevels[0]
+		size := nl.NodeSize(first)
+		assert.Greater(t, size, 2)
+		// reading the smallest name leaves the node as it is
+		assert.Equal(t, "a", nl.NodeMin(first))
+		assert.Equal(t, "a", nl.NodeMin(first))
+		assert.Equal(t, size, nl.NodeSize(first))
+
+		// the leading name of a node of several names, then of the next node
+		assert.NoError(t, nl.DeleteName(context.Background(), "a"))
+		assert.Equal(t, names[1:], listAllNames(t, nl, ""))
+		last, err := nl.skipList.GetLargestNode()
+		assert.NoError(t, err)
+		leading := string(last.Key)
+		assert.NoError(t, nl.DeleteName(context.Background(), leading))
+		want := slices.DeleteFunc(slices.Clone(names[1:]), func(name string) bool { return name == leading })
+		assert.Equal(t, want, listAllNames(t, nl, ""))
+		assertItemListConsistent(t, nl)
+	}
+}
+
+var errInjected = errors.New("injected failure")
+
+// failNthCommand fails the nth command, or pipeline, sent while armed
+type failNthCommand struct {
+	n      int
+	seen   int
+	armed  bool
+	failed bool
+	// sent are the names of the commands sent while armed, before the failure
+	sent []string
+}
+
+func (f *failNthCommand) fail() bool {
+	if !f.armed {
+		return false
+	}
+	f.seen++
+	f.failed = f.failed || f.seen == f.n
+	return f.seen == f.n
+}
+
+func (f *failNthCommand) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (f *failNthCommand) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if f.fail() {
+			cmd.SetErr(errInjected)
+			return errInjected
+		}
+		if f.armed && !f.failed {
+			f.sent = append(f.sent, cmd.Name())
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (f *failNthCommand) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if f.fail() {
+			for _, cmd := range cmds {
+				cmd.SetErr(errInjected)
+			}
+			return errInjected
+		}
+		if f.armed && !f.failed {
+			for _, cmd := range cmds {
+				f.sent = append(f.sent, cmd.Name())
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func TestWriteNameSplitReportsFailures(t *testing.T) {
+	for _, split := range []struct {
+		names []string
+		name  string
+	}{
+		{[]string{"a", "c", "d", "e"}, "b"},
+		{[]string{"a", "b", "d", "e"}, "c"},
+		{[]string{"a", "b", "c", "e"}, "d"},
+	} {
+		for n := 1; ; n++ {
+			nl, _ := newTestItemList(t, 4)
+			for _, name := range split.names {
+				assert.NoError(t, nl.WriteName(context.Background(), name))
+			}
+			hook := &failNthCommand{n: n}
+			nl.client.AddHook(hook)
+			hook.armed = true
+			outcome, err := nl.addName(context.Background(), split.name)
+			hook.armed = false
+			if !hook.failed {
+				assert.NoError(t, err)
+				assert.True(t, outcome.split, "split %s", split.name)
+				break
+			}
+			assert.ErrorIs(t, err, errInjected, "split %s, failing command %d", split.name, n)
+			changed := slices.ContainsFunc(hook.sent, func(name string) bool {
+				return slices.Contains([]string{"zadd", "zrem", "zremrangebylex", "set", "del"}, name)
+			})
+			if !changed {
+				// failed before changing the list, so the caller can retry
+				assert.NoError(t, nl.WriteName(context.Background(), split.name), "split %s, failing command %d", split.name, n)
+				want := append(slices.Clone(split.names), split.name)
+				sort.Strings(want)
+				assert.Equal(t, want, listAllNames(t, nl, ""))
+			}
+		}
+	}
+}
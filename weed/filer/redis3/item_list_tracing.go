@@ -0,0 +1,59 @@
+package redis3
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceSpan is one operation traced with WithTracer or timed with WithSlowOperationLog, nil when both are off.
+type traceSpan struct {
+	// span is nil without a tracer
+	span       trace.Span
+	op         string
+	start      time.Time
+	attrs      []attribute.KeyValue
+	roundTrips int64
+}
+
+// startSpan starts a span for operation op, returning nil without a tracer or a slow operation threshold
+// so untraced lists pay nothing.
+func (nl *ItemList) startSpan(ctx context.Context, op string, attrs ...attribute.KeyValue) *traceSpan {
+	if nl.tracer == nil && nl.slowOperationThreshold <= 0 {
+		return nil
+	}
+	s := &traceSpan{op: op, start: time.Now(), attrs: attrs}
+	if nl.tracer != nil {
+		_, s.span = nl.tracer.Start(ctx, "redis3.ItemList."+op, trace.WithAttributes(attrs...))
+	}
+	if nl.commandStats != nil {
+		s.roundTrips = nl.commandStats.roundTrips.Load()
+	}
+	return s
+}
+
+// end ends the span, recording err and, with WithCommandStats, the round trips made since startSpan.
+// The round trips are counted per client, so they include those of concurrent operations sharing it.
+func (s *traceSpan) end(nl *ItemList, err error, attrs ...attribute.KeyValue) {
+	if s == nil {
+		return
+	}
+	if nl.commandStats != nil {
+		attrs = append(attrs, attribute.Int64("redis3.round_trips", nl.commandStats.roundTrips.Load()-s.roundTrips))
+	}
+	if elapsed := time.Since(s.start); nl.slowOperationThreshold > 0 && elapsed >= nl.slowOperationThreshold {
+		nl.logSlowOperation(s.op, elapsed, err, append(s.attrs, attrs...))
+	}
+	if s.span == nil {
+		return
+	}
+	s.span.SetAttributes(attrs...)
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,41 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracing(t *testing.T) {
+	tracer := &recordingTracer{}
+	nl, _ := newTestItemList(t, 2, WithTracer(tracer), WithCommandStats())
+	for _, name := range []string{"a", "c", "b"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	assert.NoError(t, nl.DeleteName(context.Background(), "b"))
+	assert.Equal(t, []string{"a", "c"}, listAllNames(t, nl, ""))
+	assert.ErrorIs(t, nl.WriteName(context.Background(), ""), ErrEmptyName)
+
+	if !assert.Len(t, tracer.ended, 6) {
+		return
+	}
+	split := tracer.ended[2]
+	assert.Equal(t, "redis3.ItemList.WriteName", split.name)
+	assert.Equal(t, "b", split.attrs["redis3.name"].AsString())
+	assert.True(t, split.attrs["redis3.split"].AsBool())
+	assert.Equal(t, string(WriteCaseSplit), split.attrs["redis3.case"].AsString())
+	assert.Positive(t, split.attrs["redis3.node_id"].AsInt64())
+	assert.Positive(t, split.attrs["redis3.round_trips"].AsInt64())
+
+	deleted := tracer.ended[3]
+	assert.Equal(t, "redis3.ItemList.DeleteName", deleted.name)
+	assert.True(t, deleted.attrs["redis3.removed"].AsBool())
+
+	listed := tracer.ended[4]
+	assert.Equal(t, "redis3.ItemList.ListNames", listed.name)
+	assert.Equal(t, int64(2), listed.attrs["redis3.visited_names"].AsInt64())
+	assert.Positive(t, listed.attrs["redis3.nodes_scanned"].AsInt64())
+
+	assert.ErrorIs(t, tracer.ended[5].err, ErrEmptyName)
+}
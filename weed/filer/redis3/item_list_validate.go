@@ -0,0 +1,49 @@
+package redis3
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxNameLength is the longest name in bytes WriteName stores, keeping a node of batchSize names readable in one reply
+const MaxNameLength = 4096
+
+// ValidateName tells whether WriteName can store name, without touching Redis, so callers can check names up front.
+// The empty name fails with ErrEmptyName, and a name that is too long fails with ErrInvalidName.
+// Any other name is stored as is, see ValidateNameStrict and WithStrictNames for a stricter check.
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return ErrEmptyName
+	case len(name) > MaxNameLength:
+		return fmt.Errorf("%w: %d bytes, longer than %d", ErrInvalidName, len(name), MaxNameLength)
+	}
+	return nil
+}
+
+// ValidateNameStrict is ValidateName, also failing with ErrInvalidName a name that is "-" or "+", the unbounded ends
+// of a lex range, contains a NUL byte, the separator of the order key, or is not valid UTF-8. Such names are stored
+// correctly, but are easily mistaken by other tools reading the sorted sets.
+func ValidateNameStrict(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	switch {
+	case name == "-" || name == "+":
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidName, name)
+	case strings.IndexByte(name, 0) >= 0:
+		return fmt.Errorf("%w: contains a NUL byte", ErrInvalidName)
+	case !utf8.ValidString(name):
+		return fmt.Errorf("%w: not valid UTF-8", ErrInvalidName)
+	}
+	return nil
+}
+
+// validateName is ValidateName, or ValidateNameStrict with WithStrictNames
+func (nl *ItemList) validateName(name string) error {
+	if nl.strictNames {
+		return ValidateNameStrict(name)
+	}
+	return ValidateName(name)
+}
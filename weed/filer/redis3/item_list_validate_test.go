@@ -0,0 +1,81 @@
+package redis3
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redsync/redsync/v4"
+	"github.com/go-redsync/redsync/v4/redis/goredis/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		err, strict error
+	}{
+		{"a", nil, nil},
+		{"file.txt", nil, nil},
+		{"-a", nil, nil},
+		{"++", nil, nil},
+		{"名前", nil, nil},
+		{strings.Repeat("a", MaxNameLength), nil, nil},
+		{"-", nil, ErrInvalidName},
+		{"+", nil, ErrInvalidName},
+		{"a\x00b", nil, ErrInvalidName},
+		{"\xff", nil, ErrInvalidName},
+		{"", ErrEmptyName, ErrEmptyName},
+		{strings.Repeat("a", MaxNameLength+1), ErrInvalidName, ErrInvalidName},
+	} {
+		for _, check := range []struct {
+			validate func(string) error
+			err      error
+		}{{ValidateName, tc.err}, {ValidateNameStrict, tc.strict}} {
+			err := check.validate(tc.name)
+			if check.err == nil {
+				assert.NoError(t, err, tc.name)
+			} else {
+				assert.ErrorIs(t, err, check.err, tc.name)
+			}
+		}
+	}
+
+	// the names only the strict check rejects are stored and listed as any other
+	nl, _ := newTestItemList(t, 3)
+	names := []string{"+", "-", "a", "a\x00b", "\xff"}
+	for _, name := range names {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	assert.Equal(t, names, listAllNames(t, nl, ""))
+	assert.Equal(t, names[2:], listAllNames(t, nl, "a"))
+	assert.NoError(t, nl.DeleteName(context.Background(), "-"))
+	assert.Equal(t, []string{"+", "a", "a\x00b", "\xff"}, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+
+	strict, _ := newTestItemList(t, 3, WithStrictNames())
+	assert.ErrorIs(t, strict.WriteName(context.Background(), "-"), ErrInvalidName)
+	assert.ErrorIs(t, strict.ReplaceAll(context.Background(), []string{"a", "\xff"}), ErrInvalidName)
+	assert.ErrorIs(t, nl.WriteName(context.Background(), strings.Repeat("a", MaxNameLength+1)), ErrInvalidName)
+	assert.Empty(t, listAllNames(t, strict, ""))
+	assert.Zero(t, testing.AllocsPerRun(10, func() {
+		_ = ValidateNameStrict("file.txt")
+	}))
+
+	// the filer store checks the name before storing the entry
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	store := &UniversalRedis3Store{Client: client, redsync: redsync.New(goredis.NewPool(client))}
+	tooLong := util.FullPath("/dir/" + strings.Repeat("a", MaxNameLength+1))
+	assert.Error(t, store.InsertEntry(context.Background(), &filer.Entry{FullPath: tooLong}))
+	assert.False(t, server.Exists(string(tooLong)))
+	assert.NoError(t, store.InsertEntry(context.Background(), &filer.Entry{FullPath: "/dir/-"}))
+	assert.True(t, server.Exists("/dir/-"))
+}
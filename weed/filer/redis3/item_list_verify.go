@@ -0,0 +1,110 @@
+package redis3
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+)
+
+// above this fraction of nodes holding a single name, and singleNameNodesMinNodes nodes, Verify warns
+const (
+	singleNameNodesWarnFraction = 0.5
+	singleNameNodesMinNodes     = 10
+)
+
+type VerifyReport struct {
+	Nodes int
+	Names int64
+	// EmptyNodes are referenced by the skiplist but have no members
+	EmptyNodes []int64
+	// KeyMismatchNodes have a skiplist key different from their smallest member
+	KeyMismatchNodes []int64
+	// OverCapacityNodes have more than batchSize members
+	OverCapacityNodes []int64
+	// NodeCountFixed had a maintained node count that diverged from the member set
+	NodeCountFixed []int64
+	// NodeCountMismatch is the same as NodeCountFixed, for read only lists which are not fixed
+	NodeCountMismatch []int64
+	// PreSplitNodes are empty nodes created by PreSplit and not written yet, which are not EmptyNodes
+	PreSplitNodes []int64
+	// SingleNameNodes counts the nodes with one name, which bloat the skiplist when they are many
+	SingleNameNodes int
+}
+
+// Degenerate tells whether most nodes hold a single name, e.g. after inserts in a pathological order.
+// RebalanceToBatchSize with the same batch size packs the names into full nodes again.
+func (r *VerifyReport) Degenerate() bool {
+	return r.Nodes >= singleNameNodesMinNodes && float64(r.SingleNameNodes) > singleNameNodesWarnFraction*float64(r.Nodes)
+}
+
+func (r *VerifyReport) IsConsistent() bool {
+	return len(r.EmptyNodes) == 0 && len(r.KeyMismatchNodes) == 0 && len(r.OverCapacityNodes) == 0
+}
+
+// Verify walks all nodes and reports structural problems.
+// Maintained node counts that diverged from the member sets are corrected, unless the list is read only.
+func (nl *ItemList) Verify(ctx context.Context) (*VerifyReport, error) {
+	report := &VerifyReport{}
+	preSplit, err := nl.preSplitNodes(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	err = nl.walkNodes(ctx, func(node *skiplist.SkipListElement) (bool, error) {
+		report.Nodes++
+
+		key := nl.nodeKey(node.Id)
+		field := strconv.FormatInt(node.Id, 10)
+		pipe := nl.client.Pipeline()
+		countOperation := nl.zLexCountAll(ctx, pipe, key)
+		minOperation := nl.zLexMin(ctx, pipe, key)
+		var maintainedOperation *redis.StringCmd
+		if nl.maintainNodeCount {
+			maintainedOperation = pipe.HGet(ctx, nl.nodeCountKey(), field)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+			return false, err
+		}
+
+		count := countOperation.Val()
+		report.Names += count
+		if count == 0 && preSplit[node.Id] {
+			report.PreSplitNodes = append(report.PreSplitNodes, node.Id)
+		} else if count == 0 {
+			report.EmptyNodes = append(report.EmptyNodes, node.Id)
+		} else if minNames := minOperation.Val(); len(minNames) == 0 || minNames[0] != string(node.Key) {
+			report.KeyMismatchNodes = append(report.KeyMismatchNodes, node.Id)
+		}
+		if count == 1 {
+			report.SingleNameNodes++
+		}
+		if count > int64(nl.batchSize) {
+			report.OverCapacityNodes = append(report.OverCapacityNodes, node.Id)
+		}
+		if maintainedOperation != nil {
+			if maintained, err := maintainedOperation.Int64(); err == nil && maintained != count {
+				if nl.readOnly {
+					report.NodeCountMismatch = append(report.NodeCountMismatch, node.Id)
+				} else {
+					if err := nl.client.HSet(ctx, nl.nodeCountKey(), field, count).Err(); err != nil {
+						return false, err
+					}
+					report.NodeCountFixed = append(report.NodeCountFixed, node.Id)
+				}
+			}
+		}
+
+		return true, nil
+	})
+	if err != nil {
+		return report, err
+	}
+	if nl.batchSize > 1 && report.Degenerate() {
+		glog.Warningf("verify %s: %d of %d nodes hold a single name, run RebalanceToBatchSize(%d)",
+			nl.prefix, report.SingleNameNodes, report.Nodes, nl.batchSize)
+	}
+	return report, nil
+}
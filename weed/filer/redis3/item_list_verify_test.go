@@ -0,0 +1,45 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleNameNodes(t *testing.T) {
+	// descending names into the gap after a full node, as written by a list loaded per write, without a trend,
+	// made a node per name
+	nl, _ := newTestItemList(t, 5, WithInsertDirection(InsertBalanced))
+	for _, name := range []string{"a", "b", "c", "d", "e", "zz"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	for i := 40; i > 0; i-- {
+		assert.NoError(t, nl.WriteName(context.Background(), fmt.Sprintf("m%02d", i)))
+	}
+	report, err := nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, report.IsConsistent())
+	assert.False(t, report.Degenerate())
+	assert.LessOrEqual(t, report.Nodes, 11)
+	assert.Equal(t, int64(46), report.Names)
+
+	// single name nodes left by such writes before the next node was preferred
+	degenerate, _ := newTestItemList(t, 5)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, degenerate.WriteName(context.Background(), name))
+	}
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, degenerate.ItemAdd([]byte(fmt.Sprintf("m%02d", i)), 0, fmt.Sprintf("m%02d", i)))
+	}
+	report, err = degenerate.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 20, report.SingleNameNodes)
+	assert.True(t, report.Degenerate())
+	assert.NoError(t, degenerate.RebalanceToBatchSize(context.Background(), 5))
+	report, err = degenerate.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, report.Degenerate())
+	assert.Equal(t, 5, report.Nodes)
+}
@@ -0,0 +1,106 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+/*
+With WithListingVersionCheck, the hash "<prefix>version" detects structural changes during a listing:
+splits, merges, and nodes removed or re-keyed, which move names between nodes or out of the skiplist
+for a moment, so a concurrent listing can see a name twice or not at all.
+
+A structural change increments "changing" before it starts, and after it ends decrements "changing"
+and increments "version". A listing reads both before and after, and fails with ErrConcurrentModification
+if a change was in progress at either read, or if the version moved.
+This is optimistic: the listing is not isolated, only a possibly inconsistent result is detected,
+and the caller retries. Adding a name to a node with room is not a structural change.
+If a writer dies during a change, "changing" stays set until the hash expires,
+versionChangingTTL after the last change started, and listings fail until then.
+*/
+
+const (
+	listingVersionSuffix = "version"
+	versionChangingTTL   = time.Minute
+)
+
+var beginStructuralChangeScript = redis.NewScript(`
+redis.call('HINCRBY', KEYS[1], 'changing', 1)
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+`)
+
+var endStructuralChangeScript = redis.NewScript(`
+if redis.call('HINCRBY', KEYS[1], 'changing', -1) <= 0 then
+	redis.call('HDEL', KEYS[1], 'changing')
+end
+redis.call('HINCRBY', KEYS[1], 'version', 1)
+`)
+
+func (nl *ItemList) listingVersionKey() string {
+	return nl.prefix + listingVersionSuffix
+}
+
+// beginStructuralChange marks a structural change as in progress, until the returned function is called
+func (nl *ItemList) beginStructuralChange(ctx context.Context) (end func(), err error) {
+	if !nl.listingVersionCheck {
+		return func() {}, nil
+	}
+	key := nl.listingVersionKey()
+	err = beginStructuralChangeScript.Run(ctx, nl.client, []string{key}, versionChangingTTL.Milliseconds()).Err()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("begin structural change: %w", wrapRedisError(err))
+	}
+	return func() {
+		err := endStructuralChangeScript.Run(ctx, nl.client, []string{key}).Err()
+		if err != nil && err != redis.Nil {
+			// the listings fail until the hash expires
+			glog.Errorf("end structural change %s: %v", key, err)
+		}
+	}, nil
+}
+
+func (nl *ItemList) deleteListingVersion(ctx context.Context) error {
+	if !nl.listingVersionCheck {
+		return nil
+	}
+	return wrapRedisError(nl.client.Del(ctx, nl.listingVersionKey()).Err())
+}
+
+// listingVersion is the version stamp of the structure, changing is set during a structural change
+type listingVersion struct {
+	version  int64
+	changing bool
+}
+
+func (nl *ItemList) readListingVersion(ctx context.Context) (listingVersion, error) {
+	values, err := nl.client.HMGet(ctx, nl.listingVersionKey(), "version", "changing").Result()
+	if err != nil {
+		return listingVersion{}, fmt.Errorf("read listing version: %w", wrapRedisError(err))
+	}
+	var v listingVersion
+	if s, ok := values[0].(string); ok {
+		v.version, _ = strconv.ParseInt(s, 10, 64)
+	}
+	if s, ok := values[1].(string); ok {
+		changing, _ := strconv.ParseInt(s, 10, 64)
+		v.changing = changing > 0
+	}
+	return v, nil
+}
+
+// checkListingVersion fails with ErrConcurrentModification if the structure changed since before
+func (nl *ItemList) checkListingVersion(ctx context.Context, before listingVersion) error {
+	after, err := nl.readListingVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if before.changing || after.changing || before.version != after.version {
+		return fmt.Errorf("%w: %s changed during the listing", ErrConcurrentModification, nl.prefix)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListingVersionCheck(t *testing.T) {
+	nl, server := newTestItemList(t, 4, WithListingVersionCheck())
+	for _, name := range []string{"a", "c", "e"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	assert.Equal(t, []string{"a", "c", "e"}, listAllNames(t, nl, ""))
+
+	// adding to a node with room is not a structural change
+	assert.NoError(t, nl.ListNames(context.Background(), "", func(name string) bool {
+		if name == "a" {
+			assert.NoError(t, nl.WriteName(context.Background(), "b"))
+		}
+		return true
+	}))
+
+	// a split during the listing is detected
+	err := nl.ListNames(context.Background(), "", func(name string) bool {
+		if name == "a" {
+			split, err := nl.WriteNameWithSplit(context.Background(), "bb")
+			assert.NoError(t, err)
+			assert.True(t, split)
+		}
+		return true
+	})
+	assert.ErrorIs(t, err, ErrConcurrentModification)
+	assert.Equal(t, []string{"a", "b", "bb", "c", "e"}, listAllNames(t, nl, ""))
+
+	// removing and merging nodes too
+	err = nl.ListNames(context.Background(), "", func(name string) bool {
+		if name == "a" {
+			assert.NoError(t, nl.DeleteName(context.Background(), "b"))
+			assert.NoError(t, nl.DeleteName(context.Background(), "bb"))
+		}
+		return true
+	})
+	assert.ErrorIs(t, err, ErrConcurrentModification)
+
+	// a change in progress, e.g. left by a dead writer
+	server.HSet(nl.listingVersionKey(), "changing", "1")
+	assert.ErrorIs(t, nl.ListNames(context.Background(), "", func(name string) bool { return true }), ErrConcurrentModification)
+	server.HDel(nl.listingVersionKey(), "changing")
+	assert.Equal(t, []string{"a", "c", "e"}, listAllNames(t, nl, ""))
+
+	assert.NoError(t, nl.RemoteAllListElement(context.Background()))
+	assert.NoError(t, nl.AssertEmpty(context.Background()))
+}
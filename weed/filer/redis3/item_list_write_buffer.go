@@ -0,0 +1,235 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/util/skiplist"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+/*
+With WithWriteBuffer, WriteName only remembers the name, and names heading for the same node are written together:
+each node gets one ZADD for all its buffered names, and one split of the whole node when they overflow it,
+instead of a split every batchSize names while a loop keeps filling the same region.
+The buffer is flushed once it holds maxNames names, by the first write at least window after the oldest buffered name,
+and by Flush. Nothing flushes it in the background, the ItemList is not safe for concurrent use.
+
+The trade-off is latency and durability for throughput: a buffered name is not listed, counted or persisted
+until flushed, and is lost if the process stops first, although WriteName returned nil.
+A flush interrupted after adding names to a node but before splitting it leaves a node over batchSize,
+as a failed split does, which the next writes split, or SplitOversizedNodes repairs.
+*/
+
+type writeBuffer struct {
+	window   time.Duration
+	maxNames int
+	names    map[string]struct{}
+	since    time.Time
+}
+
+func newWriteBuffer(window time.Duration, maxNames int) *writeBuffer {
+	return &writeBuffer{
+		window:   window,
+		maxNames: maxNames,
+		names:    make(map[string]struct{}),
+	}
+}
+
+// take empties the buffer, returning its names in order
+func (b *writeBuffer) take() []string {
+	names := make([]string, 0, len(b.names))
+	for name := range b.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b.names = make(map[string]struct{})
+	return names
+}
+
+// holds tells whether the stored name is buffered, a nil buffer holds none
+func (b *writeBuffer) holds(name string) bool {
+	if b == nil {
+		return false
+	}
+	_, found := b.names[name]
+	return found
+}
+
+// bufferName buffers a stored name, flushing the buffer when full or expired
+func (nl *ItemList) bufferName(ctx context.Context, name string, now time.Time) error {
+	b := nl.writeBuffer
+	if len(b.names) == 0 {
+		b.since = now
+	}
+	b.names[name] = struct{}{}
+	if len(b.names) >= b.maxNames || now.Sub(b.since) >= b.window {
+		return nl.flush(ctx)
+	}
+	return nil
+}
+
+// Flush writes the names buffered by WithWriteBuffer. On failure, the names not written yet stay buffered.
+// Persist the list header after it, as after WriteName.
+func (nl *ItemList) Flush(ctx context.Context) error {
+	return nl.flush(ctx)
+}
+
+// flush is Flush, its commands using ctx
+func (nl *ItemList) flush(ctx context.Context) error {
+	if nl.writeBuffer == nil || len(nl.writeBuffer.names) == 0 {
+		return nil
+	}
+	if rest, err := nl.writeSorted(ctx, nl.writeBuffer.take()); err != nil {
+		for _, name := range rest {
+			nl.writeBuffer.names[name] = struct{}{}
+		}
+		return fmt.Errorf("flush %s: %w", nl.prefix, err)
+	}
+	return nil
+}
+
+// writeSorted writes the sorted stored names, the names of each node at once, returning the names not written on failure
+func (nl *ItemList) writeSorted(ctx context.Context, names []string) (rest []string, err error) {
+	for len(names) > 0 {
+		prev, next, found, err := nl.resolveNeighbors(ctx, names[0])
+		if err == nil && found && string(next.Key) == names[0] {
+			// case 1, the leading name of a node
+			names = names[1:]
+			continue
+		}
+		n := len(names)
+		if err == nil && found {
+			n = sort.SearchStrings(names, string(next.Key))
+		}
+		if err == nil {
+			err = nl.flushNode(ctx, prev, names[:n])
+		}
+		if err != nil {
+			return names, err
+		}
+		names = names[n:]
+	}
+	return nil, nil
+}
+
+// flushNode adds the names to node, or to a new node when nil, splitting it once if they overflow it.
+// As in WriteName, a node whose smallest name changes, e.g. a pre-split one, is re-keyed to it,
+// and WithSafeMode refuses to add to a node already over batchSize.
+func (nl *ItemList) flushNode(ctx context.Context, node *skiplist.SkipListElementReference, names []string) (err error) {
+	var added []string
+	split := false
+	span := nl.startSpan(ctx, "WriteNodeNames", attribute.String("redis3.name", nl.realNameOf(names[0])), attribute.Int("redis3.names", len(names)))
+	defer func() {
+		span.end(nl, err, attribute.Int("redis3.added", len(added)), attribute.Bool("redis3.split", split))
+	}()
+	if node == nil {
+		id, err := nl.itemAdd(ctx, []byte(names[0]), 0, names...)
+		if err != nil {
+			return err
+		}
+		node = &skiplist.SkipListElementReference{ElementPointer: id, Key: []byte(names[0])}
+		added = names
+	} else {
+		key := nl.nodeKey(node.ElementPointer)
+		if nl.safeMode {
+			size, err := nl.zLexCountAll(ctx, nl.client, key).Result()
+			if err != nil {
+				return wrapNodeKeyError(key, err)
+			}
+			if size > int64(nl.batchSize) {
+				return nl.refuseOverCapacity(node.ElementPointer, int(size))
+			}
+		}
+		existing, err := nl.zRangeByLex(ctx, key, &redis.ZRangeBy{Min: "[" + names[0], Max: "[" + names[len(names)-1]}).Result()
+		if err != nil {
+			return wrapNodeKeyError(key, err)
+		}
+		if added = withoutSorted(names, existing); len(added) == 0 {
+			return nil
+		}
+		smallest, err := nl.nodeMin(ctx, node)
+		if err != nil {
+			return err
+		}
+		if (smallest == "" || added[0] < smallest) && added[0] != string(node.Key) {
+			if node, err = nl.rekeyNodeAdding(ctx, node, smallest == "", added); err != nil {
+				return err
+			}
+		} else if err := nl.nodeAddMember(ctx, node, added...); err != nil {
+			return err
+		}
+	}
+	for _, name := range added {
+		if err := nl.adjustChecksum(ctx, name, 1); err != nil {
+			return err
+		}
+	}
+	if err := nl.bumpDirectoryVersion(ctx); err != nil {
+		return err
+	}
+	nl.mirror(ctx, "write", nl.realNameOf(added[0]), func(mirror *ItemList) error {
+		for _, name := range added {
+			if err := mirror.WriteName(ctx, nl.realNameOf(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	key := nl.nodeKey(node.ElementPointer)
+	size, err := nl.zLexCountAll(ctx, nl.client, key).Result()
+	if err != nil {
+		return wrapNodeKeyError(key, err)
+	}
+	if size <= int64(nl.batchSize) {
+		return nil
+	}
+	split = true
+	return nl.splitOversizedNode(ctx, node)
+}
+
+// rekeyNodeAdding adds the sorted names to node, which takes the first of them, its new smallest name, as its key,
+// as fillPreSplitNode does for one name. The node was empty, e.g. pre-split, when wasEmpty is set.
+func (nl *ItemList) rekeyNodeAdding(ctx context.Context, node *skiplist.SkipListElementReference, wasEmpty bool, names []string) (*skiplist.SkipListElementReference, error) {
+	end, err := nl.beginStructuralChange(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer end()
+	if err := nl.deleteNodeElement(ctx, node); err != nil {
+		return nil, err
+	}
+	if _, err := nl.itemAdd(ctx, []byte(names[0]), node.ElementPointer, names...); err != nil {
+		return nil, err
+	}
+	if wasEmpty {
+		if err := nl.client.SRem(ctx, nl.preSplitKey(), strconv.FormatInt(node.ElementPointer, 10)).Err(); err != nil {
+			return nil, wrapRedisError(err)
+		}
+	}
+	return &skiplist.SkipListElementReference{ElementPointer: node.ElementPointer, Key: []byte(names[0])}, nil
+}
+
+// withoutSorted returns the names not in existing, both sorted
+func withoutSorted(names, existing []string) []string {
+	if len(existing) == 0 {
+		return names
+	}
+	var rest []string
+	i := 0
+	for _, name := range names {
+		for i < len(existing) && existing[i] < name {
+			i++
+		}
+		if i < len(existing) && existing[i] == name {
+			continue
+		}
+		rest = append(rest, name)
+	}
+	return rest
+}
@@ -0,0 +1,69 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteBuffer(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	nl, _ := newTestItemList(t, 5, WithWriteBuffer(time.Second, 20), WithClock(clock), WithChecksum())
+	adds := &countCommands{name: "zadd"}
+	nl.client.AddHook(adds)
+
+	var expected []string
+	for i := 0; i < 19; i++ {
+		name := fmt.Sprintf("name%02d", i)
+		expected = append(expected, name)
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	// buffered, not written yet
+	assert.Empty(t, listAllNames(t, nl, ""))
+	assert.Zero(t, adds.count.Load())
+
+	// the buffer is full, 20 names are written into 4 nodes with one ZADD each
+	expected = append(expected, "name19")
+	assert.NoError(t, nl.WriteName(context.Background(), "name19"))
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assert.Equal(t, 4, countNodes(t, nl))
+	assert.LessOrEqual(t, adds.count.Load(), int64(5))
+	assertItemListConsistent(t, nl)
+
+	// names into existing nodes, an existing name, and a name deleted while buffered
+	assert.NoError(t, nl.WriteName(context.Background(), "name035"))
+	assert.NoError(t, nl.WriteName(context.Background(), "name10"))
+	assert.NoError(t, nl.WriteName(context.Background(), "gone"))
+	assert.NoError(t, nl.DeleteName(context.Background(), "gone"))
+	clock.now = clock.now.Add(time.Second)
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	expected = append([]string{"a"}, expected...)
+	expected = slices.Insert(expected, 5, "name035")
+	assert.Equal(t, expected, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+	valid, err := nl.VerifyChecksum(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	assert.NoError(t, nl.WriteName(context.Background(), "z"))
+	assert.NoError(t, nl.Flush(context.Background()))
+	assert.Equal(t, append(expected, "z"), listAllNames(t, nl, ""))
+	assert.NoError(t, nl.Flush(context.Background()))
+}
+
+func TestWriteBufferUpsertName(t *testing.T) {
+	nl, _ := newTestItemList(t, 5, WithWriteBuffer(time.Hour, 20), WithNameScores())
+	assert.NoError(t, nl.WriteName(context.Background(), "a"))
+	assert.NoError(t, nl.UpsertName(context.Background(), "b", 2))
+	// the upserted name is flushed with the names buffered before it, then scored
+	assert.Empty(t, nl.writeBuffer.names)
+	assert.Equal(t, []string{"a", "b"}, listAllNames(t, nl, ""))
+	score, found, err := nl.NameScore(context.Background(), "b")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, float64(2), score)
+}
@@ -0,0 +1,86 @@
+package redis3
+
+import (
+	"context"
+	"sort"
+)
+
+// WriteNames adds names as WriteName does, sorted and grouped by the node they fall into, walking the skiplist
+// once per group: a group of several names gets one ZADD, and when it overflows the node, one split of the node,
+// as Flush writes them, while a name alone in its node takes the path of WriteName.
+// All names are validated before any is written. On a failure, a *WriteNamesError tells the names written before it.
+// Persist the list header afterwards, as after WriteName.
+func (nl *ItemList) WriteNames(ctx context.Context, names []string) error {
+	if nl.readOnly {
+		return ErrReadOnly
+	}
+	if err := nl.ensureNamespace(ctx); err != nil {
+		return err
+	}
+	if nl.writeBuffer != nil || nl.caseInsensitive {
+		// the buffer groups the names itself, and case collisions are resolved one name at a time
+		for i, name := range names {
+			if err := nl.WriteName(ctx, name); err != nil {
+				return &WriteNamesError{Written: names[:i], Err: err}
+			}
+		}
+		return nil
+	}
+
+	stored := make(map[string]string, len(names)) // stored name -> name
+	for _, name := range names {
+		if err := nl.validateName(name); err != nil {
+			return err
+		}
+		if err := nl.checkOrderKey(name); err != nil {
+			return err
+		}
+		stored[nl.storedName(name)] = name
+	}
+	now := nl.clock.Now()
+	sorted := make([]string, 0, len(stored))
+	for name := range stored {
+		if nl.recentWrites == nil || !nl.recentWrites.contains(name, now) {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+
+	written := 0
+	fail := func(err error) error {
+		writtenNames := make([]string, 0, written)
+		for _, name := range sorted[:written] {
+			writtenNames = append(writtenNames, stored[name])
+		}
+		return &WriteNamesError{Written: writtenNames, Err: err}
+	}
+	for written < len(sorted) {
+		rest := sorted[written:]
+		prev, next, found, err := nl.resolveNeighbors(ctx, rest[0])
+		if err != nil {
+			return fail(err)
+		}
+		n := len(rest)
+		if found {
+			n = sort.SearchStrings(rest, string(next.Key))
+		}
+		if n <= 1 {
+			// alone in its node, or the leading name of the next node
+			if _, err := nl.addName(ctx, stored[rest[0]]); err != nil {
+				return fail(err)
+			}
+			written++
+			continue
+		}
+		if err := nl.flushNode(ctx, prev, rest[:n]); err != nil {
+			return fail(err)
+		}
+		if nl.recentWrites != nil {
+			for _, name := range rest[:n] {
+				nl.recentWrites.add(name, now)
+			}
+		}
+		written += n
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package redis3
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteNames(t *testing.T) {
+	nl, server := newTestItemList(t, 10, WithChecksum())
+	var names []string
+	for i := 0; i < 50; i++ {
+		names = append(names, fmt.Sprintf("%03d", i))
+	}
+	shuffled := slices.Clone(names)
+	rand.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	adds := &countCommands{name: "zadd"}
+	nl.client.AddHook(adds)
+	assert.NoError(t, nl.WriteNames(context.Background(), shuffled))
+	// one node for all names, then the pieces of its split
+	assert.Less(t, adds.count.Load(), int64(10))
+	assert.Equal(t, names, listAllNames(t, nl, ""))
+	assert.Equal(t, 5, countNodes(t, nl))
+	assertItemListConsistent(t, nl)
+
+	// names scattered one per node, and names already stored
+	assert.NoError(t, nl.WriteNames(context.Background(), []string{"005a", "015a", "025a", "000", "049"}))
+	assert.Len(t, listAllNames(t, nl, ""), 53)
+	assertItemListConsistent(t, nl)
+	ok, err := nl.VerifyChecksum(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.ErrorIs(t, nl.WriteNames(context.Background(), []string{"x", ""}), ErrEmptyName)
+	assert.NotContains(t, listAllNames(t, nl, ""), "x")
+
+	// a failure reports the names written before it
+	last, err := nl.skipList.GetLargestNode()
+	assert.NoError(t, err)
+	server.Del(nl.nodeKey(last.Id))
+	assert.NoError(t, server.Set(nl.nodeKey(last.Id), "not a set"))
+	err = nl.WriteNames(context.Background(), []string{"999", "001a", "001b"})
+	var writeErr *WriteNamesError
+	if assert.ErrorAs(t, err, &writeErr) {
+		assert.Equal(t, []string{"001a", "001b"}, writeErr.Written)
+	}
+	assert.ErrorIs(t, err, ErrWrongType)
+}
+
+func TestWriteNamesRekeysNodes(t *testing.T) {
+	tracer := &recordingTracer{}
+	nl, _ := newTestItemList(t, 5, WithTracer(tracer))
+	assert.NoError(t, nl.PreSplit(context.Background(), []string{"g", "m", "t"}))
+	assert.NoError(t, nl.WriteNames(context.Background(), []string{"n", "o", "p"}))
+	assert.Equal(t, []string{"n", "o", "p"}, listAllNames(t, nl, ""))
+	report, err := nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, report.IsConsistent(), "%+v", report)
+	assert.Len(t, report.PreSplitNodes, 2)
+	if assert.NotEmpty(t, tracer.ended) {
+		flushed := tracer.ended[0]
+		assert.Equal(t, "redis3.ItemList.WriteNodeNames", flushed.name)
+		assert.Equal(t, "n", flushed.attrs["redis3.name"].AsString())
+		assert.Equal(t, int64(3), flushed.attrs["redis3.added"].AsInt64())
+	}
+
+	// names before the smallest name of a node, whose key is stale
+	nl, _ = newTestItemList(t, 5)
+	assert.NoError(t, nl.WriteNames(context.Background(), []string{"a", "b", "c"}))
+	first := nl.skipList.StartLevels[0]
+	assert.NoError(t, nl.NodeDeleteBeforeExclusive(first, "b"))
+	assert.NoError(t, nl.WriteNames(context.Background(), []string{"a1", "a2"}))
+	assert.Equal(t, []string{"a1", "a2", "b", "c"}, listAllNames(t, nl, ""))
+	report, err = nl.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, report.IsConsistent(), "%+v", report)
+
+	// safe mode refuses to add to a node over batch size
+	safe, _ := newTestItemList(t, 3, WithSafeMode())
+	assert.NoError(t, safe.WriteName(context.Background(), "a"))
+	assert.NoError(t, safe.NodeAddMember(safe.skipList.StartLevels[0], "b", "c", "d"))
+	assert.ErrorIs(t, safe.WriteNames(context.Background(), []string{"e", "f"}), ErrOverCapacity)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, listAllNames(t, safe, ""))
+}
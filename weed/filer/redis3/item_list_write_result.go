@@ -0,0 +1,55 @@
+package redis3
+
+import "context"
+
+// WriteCase is the branch of WriteName that placed a name, named after the cases in ItemList.go.
+type WriteCase string
+
+const (
+	// WriteCaseExists is case 1, the name is already the leading key of a node
+	WriteCaseExists WriteCase = "1"
+	// WriteCaseInNode is case 2.1, the name is already in its node
+	WriteCaseInNode WriteCase = "2.1"
+	// WriteCaseAddToNode is case 2.2, the name was added to a node with room
+	WriteCaseAddToNode WriteCase = "2.2"
+	// WriteCaseSplit is case 2.3, the node was full and was split, or the name went to a new node at either end of it
+	WriteCaseSplit WriteCase = "2.3"
+	// WriteCaseAddToNextNode is case 2.4, the name was added to the next node, becoming its leading key
+	WriteCaseAddToNextNode WriteCase = "2.4"
+	// WriteCaseNewNode is case 2.5, the name was added to a new node
+	WriteCaseNewNode WriteCase = "2.5"
+)
+
+// WriteResult tells what one WriteNameDetailed did.
+type WriteResult struct {
+	// Case is the branch taken, empty if the name was skipped as a recent write
+	Case WriteCase
+	// Added is set when the name was not in the list before
+	Added bool
+	// Split is set when a full node was split
+	Split bool
+	// NodeId is the node holding the name, 0 if unknown
+	NodeId int64
+	// RoundTrips is the number of Redis round trips, counted only with WithCommandStats.
+	// The count is per client, so it includes those of concurrent operations sharing it.
+	RoundTrips int64
+}
+
+// WriteNameDetailed is WriteName, also telling which branch placed the name and what it cost.
+func (nl *ItemList) WriteNameDetailed(ctx context.Context, name string) (result WriteResult, err error) {
+	var roundTrips int64
+	if nl.commandStats != nil {
+		roundTrips = nl.commandStats.roundTrips.Load()
+	}
+	outcome, err := nl.addName(ctx, name)
+	result = WriteResult{
+		Case:   outcome.writeCase,
+		Added:  outcome.added,
+		Split:  outcome.split,
+		NodeId: outcome.nodeId,
+	}
+	if nl.commandStats != nil {
+		result.RoundTrips = nl.commandStats.roundTrips.Load() - roundTrips
+	}
+	return result, err
+}
@@ -0,0 +1,39 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteNameDetailed(t *testing.T) {
+	nl, _ := newTestItemList(t, 3, WithCommandStats())
+	for _, tc := range []struct {
+		name      string
+		writeCase WriteCase
+		added     bool
+		split     bool
+	}{
+		{"b", WriteCaseNewNode, true, false},
+		{"a", WriteCaseAddToNextNode, true, false},
+		{"c", WriteCaseAddToNode, true, false},
+		{"c", WriteCaseInNode, false, false},
+		{"a", WriteCaseExists, false, false},
+		{"d", WriteCaseSplit, true, false},
+		{"bb", WriteCaseSplit, true, true},
+	} {
+		result, err := nl.WriteNameDetailed(context.Background(), tc.name)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.writeCase, result.Case, tc.name)
+		assert.Equal(t, tc.added, result.Added, tc.name)
+		assert.Equal(t, tc.split, result.Split, tc.name)
+		assert.NotZero(t, result.NodeId, tc.name)
+		assert.Positive(t, result.RoundTrips, tc.name)
+	}
+	assert.Equal(t, []string{"a", "b", "bb", "c", "d"}, listAllNames(t, nl, ""))
+	assertItemListConsistent(t, nl)
+
+	_, err := nl.WriteNameDetailed(context.Background(), "")
+	assert.ErrorIs(t, err, ErrEmptyName)
+}
@@ -20,6 +20,7 @@ func insertChild(ctx context.Context, redisStore *UniversalRedis3Store, key stri
 		mutex.Unlock()
 	}()
 
+	redisStore.touchDirectory(key)
 	client := redisStore.Client
 	data, err := client.Get(ctx, key).Result()
 	if err != nil {
@@ -28,9 +29,12 @@ func insertChild(ctx context.Context, redisStore *UniversalRedis3Store, key stri
 		}
 	}
 	store := newSkipListElementStore(key, client)
-	nameList := LoadItemList([]byte(data), key, client, store, maxNameBatchSizeLimit)
+	nameList, err := LoadItemList([]byte(data), key, client, store, maxNameBatchSizeLimit)
+	if err != nil {
+		return err
+	}
 
-	if err := nameList.WriteName(name); err != nil {
+	if err := nameList.WriteName(ctx, name); err != nil {
 		glog.Errorf("add %s %s: %v", key, name, err)
 		return err
 	}
@@ -55,6 +59,7 @@ func removeChild(ctx context.Context, redisStore *UniversalRedis3Store, key stri
 	}
 	defer mutex.Unlock()
 
+	redisStore.touchDirectory(key)
 	client := redisStore.Client
 	data, err := client.Get(ctx, key).Result()
 	if err != nil {
@@ -63,9 +68,12 @@ func removeChild(ctx context.Context, redisStore *UniversalRedis3Store, key stri
 		}
 	}
 	store := newSkipListElementStore(key, client)
-	nameList := LoadItemList([]byte(data), key, client, store, maxNameBatchSizeLimit)
+	nameList, err := LoadItemList([]byte(data), key, client, store, maxNameBatchSizeLimit)
+	if err != nil {
+		return err
+	}
 
-	if err := nameList.DeleteName(name); err != nil {
+	if err := nameList.DeleteName(ctx, name); err != nil {
 		return err
 	}
 	if !nameList.HasChanges() {
@@ -88,6 +96,7 @@ func removeChildren(ctx context.Context, redisStore *UniversalRedis3Store, key s
 	}
 	defer mutex.Unlock()
 
+	redisStore.touchDirectory(key)
 	client := redisStore.Client
 	data, err := client.Get(ctx, key).Result()
 	if err != nil {
@@ -96,27 +105,32 @@ func removeChildren(ctx context.Context, redisStore *UniversalRedis3Store, key s
 		}
 	}
 	store := newSkipListElementStore(key, client)
-	nameList := LoadItemList([]byte(data), key, client, store, maxNameBatchSizeLimit)
+	nameList, err := LoadItemList([]byte(data), key, client, store, maxNameBatchSizeLimit)
+	if err != nil {
+		return err
+	}
 
-	if err = nameList.ListNames("", func(name string) bool {
+	if err = nameList.ListNamesWithError(ctx, "", func(name string) (bool, error) {
 		if err := onDeleteFn(name); err != nil {
 			glog.Errorf("delete %s child %s: %v", key, name, err)
-			return false
+			return false, err
 		}
-		return true
+		return true, nil
 	}); err != nil {
 		return err
 	}
 
-	if err = nameList.RemoteAllListElement(); err != nil {
+	if err = nameList.RemoteAllListElement(ctx); err != nil {
 		return err
 	}
+	redisStore.forgetDirectory(key)
 
 	return nil
 
 }
 
 func listChildren(ctx context.Context, redisStore *UniversalRedis3Store, key string, startFileName string, eachFn func(name string) bool) error {
+	redisStore.touchDirectory(key)
 	client := redisStore.Client
 	data, err := client.Get(ctx, key).Result()
 	if err != nil {
@@ -125,9 +139,12 @@ func listChildren(ctx context.Context, redisStore *UniversalRedis3Store, key str
 		}
 	}
 	store := newSkipListElementStore(key, client)
-	nameList := LoadItemList([]byte(data), key, client, store, maxNameBatchSizeLimit)
+	nameList, err := LoadItemList([]byte(data), key, client, store, maxNameBatchSizeLimit)
+	if err != nil {
+		return err
+	}
 
-	if err = nameList.ListNames(startFileName, func(name string) bool {
+	if err = nameList.ListNames(ctx, startFileName, func(name string) bool {
 		return eachFn(name)
 	}); err != nil {
 		return err
@@ -54,10 +54,10 @@ func yTestNameList(t *testing.T) {
 	store := newSkipListElementStore("/yyy/bin", client)
 	var data []byte
 	for _, name := range names {
-		nameList := LoadItemList(data, "/yyy/bin", client, store, maxNameBatchSizeLimit)
-		nameList.WriteName(name)
+		nameList, _ := LoadItemList(data, "/yyy/bin", client, store, maxNameBatchSizeLimit)
+		nameList.WriteName(context.Background(), name)
 
-		nameList.ListNames("", func(name string) bool {
+		nameList.ListNames(context.Background(), "", func(name string) bool {
 			println(name)
 			return true
 		})
@@ -68,8 +68,8 @@ func yTestNameList(t *testing.T) {
 		println()
 	}
 
-	nameList := LoadItemList(data, "/yyy/bin", client, store, maxNameBatchSizeLimit)
-	nameList.ListNames("", func(name string) bool {
+	nameList, _ := LoadItemList(data, "/yyy/bin", client, store, maxNameBatchSizeLimit)
+	nameList.ListNames(context.Background(), "", func(name string) bool {
 		println(name)
 		return true
 	})
@@ -92,9 +92,9 @@ func yBenchmarkNameList(b *testing.B) {
 	store := newSkipListElementStore("/yyy/bin", client)
 	var data []byte
 	for i := 0; i < b.N; i++ {
-		nameList := LoadItemList(data, "/yyy/bin", client, store, maxNameBatchSizeLimit)
+		nameList, _ := LoadItemList(data, "/yyy/bin", client, store, maxNameBatchSizeLimit)
 
-		nameList.WriteName(strconv.Itoa(i) + "namexxxxxxxxxxxxxxxxxxx")
+		nameList.WriteName(context.Background(), strconv.Itoa(i)+"namexxxxxxxxxxxxxxxxxxx")
 
 		if nameList.HasChanges() {
 			data = nameList.ToBytes()
@@ -141,9 +141,9 @@ func xTestNameListAdd(t *testing.T) {
 	ts0 := time.Now()
 	store := newSkipListElementStore("/y", client)
 	var data []byte
-	nameList := LoadItemList(data, "/y", client, store, 100000)
+	nameList, _ := LoadItemList(data, "/y", client, store, 100000)
 	for i := 0; i < N; i++ {
-		nameList.WriteName(fmt.Sprintf("%8d", i))
+		nameList.WriteName(context.Background(), fmt.Sprintf("%8d", i))
 	}
 
 	ts1 := time.Now()
@@ -186,9 +186,9 @@ func xBenchmarkNameList(b *testing.B) {
 	store := newSkipListElementStore("/yyy/bin", client)
 	var data []byte
 	for i := 0; i < b.N; i++ {
-		nameList := LoadItemList(data, "/yyy/bin", client, store, maxNameBatchSizeLimit)
+		nameList, _ := LoadItemList(data, "/yyy/bin", client, store, maxNameBatchSizeLimit)
 
-		nameList.WriteName(fmt.Sprintf("name %8d", i))
+		nameList.WriteName(context.Background(), fmt.Sprintf("name %8d", i))
 
 		if nameList.HasChanges() {
 			data = nameList.ToBytes()
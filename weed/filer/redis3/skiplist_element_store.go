@@ -10,11 +10,12 @@ import (
 )
 
 type SkipListElementStore struct {
-	Prefix string
-	client redis.UniversalClient
+	Prefix  string
+	client  redis.UniversalClient
+	compact bool
 }
 
-var _ = skiplist.ListStore(&SkipListElementStore{})
+var _ = skiplist.ContextListStore(&SkipListElementStore{})
 
 func newSkipListElementStore(prefix string, client redis.UniversalClient) *SkipListElementStore {
 	return &SkipListElementStore{
@@ -23,31 +24,59 @@ func newSkipListElementStore(prefix string, client redis.UniversalClient) *SkipL
 	}
 }
 
+// WithCompactEncoding saves elements in the compact binary format instead of protobuf.
+// Both formats are read, and protobuf elements are rewritten in the compact format when loaded.
+func (m *SkipListElementStore) WithCompactEncoding() *SkipListElementStore {
+	m.compact = true
+	return m
+}
+
 func (m *SkipListElementStore) SaveElement(id int64, element *skiplist.SkipListElement) error {
+	return m.SaveElementContext(context.Background(), id, element)
+}
+
+func (m *SkipListElementStore) SaveElementContext(ctx context.Context, id int64, element *skiplist.SkipListElement) error {
 	key := fmt.Sprintf("%s%d", m.Prefix, id)
+	if m.compact {
+		return wrapRedisError(m.client.Set(ctx, key, skiplist.MarshalElementCompact(element), 0).Err())
+	}
 	data, err := proto.Marshal(element)
 	if err != nil {
 		glog.Errorf("marshal %s: %v", key, err)
 	}
-	return m.client.Set(context.Background(), key, data, 0).Err()
+	return wrapRedisError(m.client.Set(ctx, key, data, 0).Err())
 }
 
 func (m *SkipListElementStore) DeleteElement(id int64) error {
+	return m.DeleteElementContext(context.Background(), id)
+}
+
+func (m *SkipListElementStore) DeleteElementContext(ctx context.Context, id int64) error {
 	key := fmt.Sprintf("%s%d", m.Prefix, id)
-	return m.client.Del(context.Background(), key).Err()
+	return wrapRedisError(m.client.Del(ctx, key).Err())
 }
 
 func (m *SkipListElementStore) LoadElement(id int64) (*skiplist.SkipListElement, error) {
+	return m.LoadElementContext(context.Background(), id)
+}
+
+func (m *SkipListElementStore) LoadElementContext(ctx context.Context, id int64) (*skiplist.SkipListElement, error) {
 	key := fmt.Sprintf("%s%d", m.Prefix, id)
-	data, err := m.client.Get(context.Background(), key).Result()
+	data, err := m.client.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, nil
 		}
-		return nil, err
+		return nil, wrapRedisError(err)
 	}
 	t := &skiplist.SkipListElement{}
-	err = proto.Unmarshal([]byte(data), t)
+	isCompact, err := skiplist.UnmarshalElement([]byte(data), t)
+	if err == nil && m.compact && !isCompact {
+		// lazily migrate to the compact format
+		if err := m.client.Set(ctx, key, skiplist.MarshalElementCompact(t), 0).Err(); err != nil {
+			glog.V(1).Infof("rewrite %s in compact format: %v", key, err)
+		}
+	}
 	if err == nil {
 		for i := 0; i < len(t.Next); i++ {
 			if t.Next[i].IsNil() {
@@ -0,0 +1,50 @@
+package redis3
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemListCompactElementStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{
+		Addr: server.Addr(),
+	})
+	defer client.Close()
+
+	nl := mustNewItemList(t, client, "/dir", newSkipListElementStore("/dir", client), 2)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		assert.NoError(t, nl.WriteName(context.Background(), name))
+	}
+	var elementKeys []string
+	for _, key := range server.Keys() {
+		if _, isNode := nl.parseNodeKey(key); !isNode {
+			elementKeys = append(elementKeys, key)
+		}
+	}
+	oldSize := 0
+	for _, key := range elementKeys {
+		data, _ := server.Get(key)
+		oldSize += len(data)
+	}
+
+	// existing protobuf elements are readable, and rewritten when loaded
+	compact, err := LoadItemList(nl.ToBytes(), "/dir", client, newSkipListElementStore("/dir", client).WithCompactEncoding(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, listAllNames(t, compact, ""))
+	newSize := 0
+	for _, key := range elementKeys {
+		data, _ := server.Get(key)
+		assert.Equal(t, byte(0), data[0], key)
+		newSize += len(data)
+	}
+	assert.Less(t, newSize, oldSize)
+
+	assert.NoError(t, compact.WriteName(context.Background(), "f"))
+	assert.NoError(t, compact.DeleteName(context.Background(), "a"))
+	assert.Equal(t, []string{"b", "c", "d", "e", "f"}, listAllNames(t, compact, ""))
+}
@@ -21,6 +21,8 @@ const (
 type UniversalRedis3Store struct {
 	Client  redis.UniversalClient
 	redsync *redsync.Redsync
+	// access is set by EnableColdDirectoryEviction
+	access *directoryAccess
 }
 
 func (store *UniversalRedis3Store) BeginTransaction(ctx context.Context) (context.Context, error) {
@@ -35,12 +37,18 @@ func (store *UniversalRedis3Store) RollbackTransaction(ctx context.Context) erro
 
 func (store *UniversalRedis3Store) InsertEntry(ctx context.Context, entry *filer.Entry) (err error) {
 
+	dir, name := entry.FullPath.DirAndName()
+
+	if name != "" {
+		if err = ValidateName(name); err != nil {
+			return fmt.Errorf("persisting %s in parent dir: %v", entry.FullPath, err)
+		}
+	}
+
 	if err = store.doInsertEntry(ctx, entry); err != nil {
 		return err
 	}
 
-	dir, name := entry.FullPath.DirAndName()
-
 	if name != "" {
 		if err = insertChild(ctx, store, genDirectoryListKey(dir), name); err != nil {
 			return fmt.Errorf("persisting %s in parent dir: %v", entry.FullPath, err)
@@ -99,6 +107,7 @@ func (store *UniversalRedis3Store) DeleteEntry(ctx context.Context, fullpath uti
 	if err != nil {
 		return fmt.Errorf("delete dir list %s : %v", fullpath, err)
 	}
+	store.forgetDirectory(genDirectoryListKey(string(fullpath)))
 
 	_, err = store.Client.Del(ctx, string(fullpath)).Result()
 	if err != nil {
@@ -0,0 +1,181 @@
+package redis3
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+)
+
+/*
+Cold directory eviction is an opt-in cache mode for filers whose metadata can be recreated, e.g. a cache
+in front of another store. With EnableColdDirectoryEviction, the store records when this filer last read or wrote
+each directory list, and EvictColdest truncates the least recently used ones to free Redis memory.
+An evicted directory lists as empty, while the entries of its children are kept, so the caller must be able to
+rebuild the directories it is told were evicted, e.g. by inserting their children again.
+Access times are kept in memory, so only directories used by this filer since it started are candidates,
+and directories used by other filers may be evicted while still in use there. Only the most recently used
+directories are tracked, up to a bound, so the memory of the store does not grow with every directory ever used.
+*/
+
+// defaultMaxTrackedDirectories bounds the access times kept by EnableColdDirectoryEviction without a bound
+const defaultMaxTrackedDirectories = 1 << 20
+
+// directoryAccess records when each directory list was last read or written, for at most maxTracked directories,
+// forgetting the least recently used beyond
+type directoryAccess struct {
+	mu         sync.Mutex
+	lastUsed   map[string]*list.Element // directory list key -> its directoryUse in uses
+	uses       *list.List               // most recently used first
+	maxTracked int
+	now        func() time.Time
+}
+
+type directoryUse struct {
+	key string
+	at  time.Time
+}
+
+func newDirectoryAccess(maxTracked int) *directoryAccess {
+	if maxTracked <= 0 {
+		maxTracked = defaultMaxTrackedDirectories
+	}
+	return &directoryAccess{
+		lastUsed:   make(map[string]*list.Element),
+		uses:       list.New(),
+		maxTracked: maxTracked,
+		now:        time.Now,
+	}
+}
+
+func (a *directoryAccess) touch(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	use := directoryUse{key: key, at: a.now()}
+	if element, found := a.lastUsed[key]; found {
+		element.Value = use
+		a.uses.MoveToFront(element)
+		return
+	}
+	a.lastUsed[key] = a.uses.PushFront(use)
+	if a.uses.Len() > a.maxTracked {
+		oldest := a.uses.Back()
+		a.uses.Remove(oldest)
+		delete(a.lastUsed, oldest.Value.(directoryUse).key)
+	}
+}
+
+func (a *directoryAccess) forget(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if element, found := a.lastUsed[key]; found {
+		a.uses.Remove(element)
+		delete(a.lastUsed, key)
+	}
+}
+
+// unusedSince reports whether key was not accessed after at
+func (a *directoryAccess) unusedSince(key string, at time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	element, found := a.lastUsed[key]
+	return found && !element.Value.(directoryUse).at.After(at)
+}
+
+// coldest is the directories by their last access, least recently used first
+func (a *directoryAccess) coldest() []directoryUse {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	uses := make([]directoryUse, 0, a.uses.Len())
+	for element := a.uses.Back(); element != nil; element = element.Prev() {
+		uses = append(uses, element.Value.(directoryUse))
+	}
+	return uses
+}
+
+// EnableColdDirectoryEviction starts recording directory accesses for EvictColdest. Without it, nothing is recorded.
+// At most maxDirectories are recorded, 0 for a million, and the least recently used beyond are no longer evicted.
+func (store *UniversalRedis3Store) EnableColdDirectoryEviction(maxDirectories int) {
+	store.access = newDirectoryAccess(maxDirectories)
+}
+
+func (store *UniversalRedis3Store) touchDirectory(key string) {
+	if store.access != nil {
+		store.access.touch(key)
+	}
+}
+
+func (store *UniversalRedis3Store) forgetDirectory(key string) {
+	if store.access != nil {
+		store.access.forget(key)
+	}
+}
+
+// EvictColdest truncates the least recently used directory lists until the MEMORY USAGE estimates of those truncated
+// add up to targetFreeBytes, and returns the evicted directories, for the caller to rebuild, and the bytes freed.
+// Each directory is truncated holding its lock, and is kept if it was used after EvictColdest started.
+// ErrInvalidOptions is returned without EnableColdDirectoryEviction, ErrUnsupported without MEMORY USAGE.
+func (store *UniversalRedis3Store) EvictColdest(ctx context.Context, targetFreeBytes int64) (evicted []string, freed int64, err error) {
+	if store.access == nil {
+		return nil, 0, fmt.Errorf("evict: %w: cold directory eviction is not enabled", ErrInvalidOptions)
+	}
+	for _, use := range store.access.coldest() {
+		if freed >= targetFreeBytes {
+			break
+		}
+		usage, ok, err := store.evictDirectory(ctx, use)
+		if err != nil {
+			return evicted, freed, err
+		}
+		if !ok {
+			continue
+		}
+		evicted = append(evicted, strings.TrimSuffix(use.key, DIR_LIST_MARKER))
+		freed += usage
+		stats.FilerStoreCounter.WithLabelValues("redis3", "evict_directory").Inc()
+	}
+	if len(evicted) > 0 {
+		glog.V(0).Infof("evict: truncated %d cold directories, freeing about %d bytes", len(evicted), freed)
+	}
+	return evicted, freed, nil
+}
+
+// evictDirectory truncates the directory list of use unless it was used since, returning its memory usage
+func (store *UniversalRedis3Store) evictDirectory(ctx context.Context, use directoryUse) (int64, bool, error) {
+	mutex := store.redsync.NewMutex(use.key + "lock")
+	if err := mutex.Lock(); err != nil {
+		return 0, false, fmt.Errorf("lock %s: %v", use.key, err)
+	}
+	defer mutex.Unlock()
+	if !store.access.unusedSince(use.key, use.at) {
+		return 0, false, nil
+	}
+
+	client := store.Client
+	data, err := client.Get(ctx, use.key).Result()
+	if err != nil && err != redis.Nil {
+		return 0, false, fmt.Errorf("read %s: %v", use.key, err)
+	}
+	nameList, err := LoadItemList([]byte(data), use.key, client, newSkipListElementStore(use.key, client), maxNameBatchSizeLimit)
+	if err != nil {
+		return 0, false, err
+	}
+	usage, err := nameList.MemoryUsage(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	if err := nameList.RemoteAllListElement(ctx); err != nil {
+		return 0, false, err
+	}
+	if err := client.Del(ctx, use.key).Err(); err != nil {
+		return 0, false, fmt.Errorf("delete %s: %v", use.key, err)
+	}
+	store.access.forget(use.key)
+	return usage, true, nil
+}
@@ -0,0 +1,74 @@
+package redis3
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redsync/redsync/v4"
+	"github.com/go-redsync/redsync/v4/redis/goredis/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvictColdest(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	store := &UniversalRedis3Store{Client: client, redsync: redsync.New(goredis.NewPool(client))}
+	_, _, err := store.EvictColdest(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrInvalidOptions)
+
+	store.EnableColdDirectoryEviction(0)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	store.access.now = clock.Now
+	ctx := context.Background()
+	for _, dir := range []string{"/a", "/b", "/c"} {
+		for _, name := range []string{"x", "y", "z"} {
+			assert.NoError(t, insertChild(ctx, store, genDirectoryListKey(dir), name))
+		}
+		clock.now = clock.now.Add(time.Minute)
+	}
+	listDir := func(dir string) (names []string) {
+		assert.NoError(t, listChildren(ctx, store, genDirectoryListKey(dir), "", func(name string) bool {
+			names = append(names, name)
+			return true
+		}))
+		return
+	}
+	// /a is used again, so /b is the coldest
+	assert.Equal(t, []string{"x", "y", "z"}, listDir("/a"))
+
+	evicted, freed, err := store.EvictColdest(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/b"}, evicted)
+	assert.Positive(t, freed)
+	assert.False(t, server.Exists(genDirectoryListKey("/b")))
+	assert.Equal(t, []string{"x", "y", "z"}, listDir("/c"))
+
+	evicted, _, err = store.EvictColdest(context.Background(), math.MaxInt64)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/a", "/c"}, evicted)
+	assert.Empty(t, listDir("/a"))
+	assert.Empty(t, server.Keys())
+}
+
+func TestDirectoryAccessBound(t *testing.T) {
+	access := newDirectoryAccess(2)
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	access.now = clock.Now
+	for _, key := range []string{"/a", "/b", "/a", "/c"} {
+		clock.now = clock.now.Add(time.Minute)
+		access.touch(key)
+	}
+	// /b was the least recently used of three
+	assert.Len(t, access.lastUsed, 2)
+	assert.Equal(t, []directoryUse{{key: "/a", at: clock.now.Add(-time.Minute)}, {key: "/c", at: clock.now}}, access.coldest())
+	access.forget("/a")
+	assert.Equal(t, []directoryUse{{key: "/c", at: clock.now}}, access.coldest())
+	assert.Equal(t, 1, access.uses.Len())
+}
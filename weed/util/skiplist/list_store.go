@@ -1,16 +1,27 @@
 package skiplist
 
+import "context"
+
 type ListStore interface {
 	SaveElement(id int64, element *SkipListElement) error
 	DeleteElement(id int64) error
 	LoadElement(id int64) (*SkipListElement, error)
 }
 
+// ContextListStore is a ListStore whose operations can also take a context, passed by the ...Context methods of the SkipList
+type ContextListStore interface {
+	ListStore
+	SaveElementContext(ctx context.Context, id int64, element *SkipListElement) error
+	DeleteElementContext(ctx context.Context, id int64) error
+	LoadElementContext(ctx context.Context, id int64) (*SkipListElement, error)
+}
+
 type MemStore struct {
 	m map[int64]*SkipListElement
 }
 
-func newMemStore() *MemStore {
+// NewMemStore is a ListStore keeping the elements in memory, e.g. for tests and short lived lists
+func NewMemStore() *MemStore {
 	return &MemStore{
 		m: make(map[int64]*SkipListElement),
 	}
@@ -4,6 +4,7 @@ package skiplist
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/bits"
 	"math/rand"
@@ -78,7 +79,7 @@ func (t *SkipList) findEntryIndex(key []byte, minLevel int) int {
 	return 0
 }
 
-func (t *SkipList) findExtended(key []byte, findGreaterOrEqual bool) (prevElementIfVisited *SkipListElement, foundElem *SkipListElement, ok bool, err error) {
+func (t *SkipList) findExtended(ctx context.Context, key []byte, findGreaterOrEqual bool) (prevElementIfVisited *SkipListElement, foundElem *SkipListElement, ok bool, err error) {
 
 	foundElem = nil
 	ok = false
@@ -90,7 +91,7 @@ func (t *SkipList) findExtended(key []byte, findGreaterOrEqual bool) (prevElemen
 	index := t.findEntryIndex(key, 0)
 	var currentNode *SkipListElement
 
-	currentNode, err = t.LoadElement(t.StartLevels[index])
+	currentNode, err = t.LoadElementContext(ctx, t.StartLevels[index])
 	if err != nil {
 		return
 	}
@@ -115,7 +116,7 @@ func (t *SkipList) findExtended(key []byte, findGreaterOrEqual bool) (prevElemen
 		// Which direction are we continuing next time?
 		if currentNode.Next[index] != nil && bytes.Compare(currentNode.Next[index].Key, key) <= 0 {
 			// Go right
-			currentNode, err = t.LoadElement(currentNode.Next[index])
+			currentNode, err = t.LoadElementContext(ctx, currentNode.Next[index])
 			if err != nil {
 				return
 			}
@@ -129,7 +130,7 @@ func (t *SkipList) findExtended(key []byte, findGreaterOrEqual bool) (prevElemen
 				if currentNode.Next[0] != nil && bytes.Compare(currentNode.Next[0].Key, key) == 0 {
 					prevElementIfVisited = currentNode
 					var currentNodeNext *SkipListElement
-					currentNodeNext, err = t.LoadElement(currentNode.Next[0])
+					currentNodeNext, err = t.LoadElementContext(ctx, currentNode.Next[0])
 					if err != nil {
 						return
 					}
@@ -145,7 +146,7 @@ func (t *SkipList) findExtended(key []byte, findGreaterOrEqual bool) (prevElemen
 			} else {
 				// Element is not found and we reached the bottom.
 				if findGreaterOrEqual {
-					foundElem, err = t.LoadElement(currentNode.Next[index])
+					foundElem, err = t.LoadElementContext(ctx, currentNode.Next[index])
 					if err != nil {
 						return
 					}
@@ -162,12 +163,17 @@ func (t *SkipList) findExtended(key []byte, findGreaterOrEqual bool) (prevElemen
 // elem can be used, if ok is true.
 // Find runs in approx. O(log(n))
 func (t *SkipList) Find(key []byte) (prevIfVisited *SkipListElement, elem *SkipListElement, ok bool, err error) {
+	return t.FindContext(context.Background(), key)
+}
+
+// FindContext is Find, passing ctx to the operations of a ContextListStore.
+func (t *SkipList) FindContext(ctx context.Context, key []byte) (prevIfVisited *SkipListElement, elem *SkipListElement, ok bool, err error) {
 
 	if t == nil || key == nil {
 		return
 	}
 
-	prevIfVisited, elem, ok, err = t.findExtended(key, false)
+	prevIfVisited, elem, ok, err = t.findExtended(ctx, key, false)
 	return
 }
 
@@ -175,12 +181,17 @@ func (t *SkipList) Find(key []byte) (prevIfVisited *SkipListElement, elem *SkipL
 // The comparison is done on the keys (So on ExtractKey()).
 // FindGreaterOrEqual runs in approx. O(log(n))
 func (t *SkipList) FindGreaterOrEqual(key []byte) (prevIfVisited *SkipListElement, elem *SkipListElement, ok bool, err error) {
+	return t.FindGreaterOrEqualContext(context.Background(), key)
+}
+
+// FindGreaterOrEqualContext is FindGreaterOrEqual, passing ctx to the operations of a ContextListStore.
+func (t *SkipList) FindGreaterOrEqualContext(ctx context.Context, key []byte) (prevIfVisited *SkipListElement, elem *SkipListElement, ok bool, err error) {
 
 	if t == nil || key == nil {
 		return
 	}
 
-	prevIfVisited, elem, ok, err = t.findExtended(key, true)
+	prevIfVisited, elem, ok, err = t.findExtended(ctx, key, true)
 	return
 }
 
@@ -189,6 +200,11 @@ func (t *SkipList) FindGreaterOrEqual(key []byte) (prevIfVisited *SkipListElemen
 // (Which one will change based on the actual skiplist layout)
 // Delete runs in approx. O(log(n))
 func (t *SkipList) DeleteByKey(key []byte) (id int64, err error) {
+	return t.DeleteByKeyContext(context.Background(), key)
+}
+
+// DeleteByKeyContext is DeleteByKey, passing ctx to the operations of a ContextListStore.
+func (t *SkipList) DeleteByKeyContext(ctx context.Context, key []byte) (id int64, err error) {
 
 	if t == nil || t.IsEmpty() || key == nil {
 		return
@@ -202,9 +218,9 @@ func (t *SkipList) DeleteByKey(key []byte) (id int64, err error) {
 	for {
 
 		if currentNode == nil {
-			nextNode, err = t.LoadElement(t.StartLevels[index])
+			nextNode, err = t.LoadElementContext(ctx, t.StartLevels[index])
 		} else {
-			nextNode, err = t.LoadElement(currentNode.Next[index])
+			nextNode, err = t.LoadElementContext(ctx, currentNode.Next[index])
 		}
 		if err != nil {
 			return id, err
@@ -215,27 +231,27 @@ func (t *SkipList) DeleteByKey(key []byte) (id int64, err error) {
 
 			if currentNode != nil {
 				currentNode.Next[index] = nextNode.Next[index]
-				if err = t.SaveElement(currentNode); err != nil {
+				if err = t.SaveElementContext(ctx, currentNode); err != nil {
 					return id, err
 				}
 			}
 
 			if index == 0 {
 				if nextNode.Next[index] != nil {
-					nextNextNode, err := t.LoadElement(nextNode.Next[index])
+					nextNextNode, err := t.LoadElementContext(ctx, nextNode.Next[index])
 					if err != nil {
 						return id, err
 					}
 					if nextNextNode != nil {
 						nextNextNode.Prev = currentNode.Reference()
-						if err = t.SaveElement(nextNextNode); err != nil {
+						if err = t.SaveElementContext(ctx, nextNextNode); err != nil {
 							return id, err
 						}
 					}
 				}
 				// t.elementCount--
 				id = nextNode.Id
-				if err = t.DeleteElement(nextNode); err != nil {
+				if err = t.DeleteElementContext(ctx, nextNode); err != nil {
 					return id, err
 				}
 			}
@@ -276,6 +292,11 @@ func (t *SkipList) DeleteByKey(key []byte) (id int64, err error) {
 // Insert inserts the given ListElement into the skiplist.
 // Insert runs in approx. O(log(n))
 func (t *SkipList) InsertByKey(key []byte, idIfKnown int64, value []byte) (id int64, err error) {
+	return t.InsertByKeyContext(context.Background(), key, idIfKnown, value)
+}
+
+// InsertByKeyContext is InsertByKey, passing ctx to the operations of a ContextListStore.
+func (t *SkipList) InsertByKeyContext(ctx context.Context, key []byte, idIfKnown int64, value []byte) (id int64, err error) {
 
 	if t == nil || key == nil {
 		return
@@ -336,19 +357,19 @@ func (t *SkipList) InsertByKey(key []byte, idIfKnown int64, value []byte) (id in
 				elem.Next[index] = nextNodeRef
 				if currentNode != nil {
 					currentNode.Next[index] = elem.Reference()
-					if err = t.SaveElement(currentNode); err != nil {
+					if err = t.SaveElementContext(ctx, currentNode); err != nil {
 						return
 					}
 				}
 				if index == 0 {
 					elem.Prev = currentNode.Reference()
 					if nextNodeRef != nil {
-						if nextNode, err = t.LoadElement(nextNodeRef); err != nil {
+						if nextNode, err = t.LoadElementContext(ctx, nextNodeRef); err != nil {
 							return
 						}
 						if nextNode != nil {
 							nextNode.Prev = elem.Reference()
-							if err = t.SaveElement(nextNode); err != nil {
+							if err = t.SaveElementContext(ctx, nextNode); err != nil {
 								return
 							}
 						}
@@ -360,7 +381,7 @@ func (t *SkipList) InsertByKey(key []byte, idIfKnown int64, value []byte) (id in
 				// Go right
 				if nextNode == nil {
 					// reuse nextNode when index == 0
-					if nextNode, err = t.LoadElement(nextNodeRef); err != nil {
+					if nextNode, err = t.LoadElementContext(ctx, nextNodeRef); err != nil {
 						return
 					}
 				}
@@ -387,13 +408,13 @@ func (t *SkipList) InsertByKey(key []byte, idIfKnown int64, value []byte) (id in
 
 			if t.StartLevels[i] == nil || bytes.Compare(t.StartLevels[i].Key, key) > 0 {
 				if i == 0 && t.StartLevels[i] != nil {
-					startLevelElement, err := t.LoadElement(t.StartLevels[i])
+					startLevelElement, err := t.LoadElementContext(ctx, t.StartLevels[i])
 					if err != nil {
 						return id, err
 					}
 					if startLevelElement != nil {
 						startLevelElement.Prev = elem.Reference()
-						if err = t.SaveElement(startLevelElement); err != nil {
+						if err = t.SaveElementContext(ctx, startLevelElement); err != nil {
 							return id, err
 						}
 					}
@@ -417,13 +438,13 @@ func (t *SkipList) InsertByKey(key []byte, idIfKnown int64, value []byte) (id in
 			// This is very important, so we are not linking the very first element (newFirst AND newLast) to itself!
 			if !newFirst {
 				if t.EndLevels[i] != nil {
-					endLevelElement, err := t.LoadElement(t.EndLevels[i])
+					endLevelElement, err := t.LoadElementContext(ctx, t.EndLevels[i])
 					if err != nil {
 						return id, err
 					}
 					if endLevelElement != nil {
 						endLevelElement.Next[i] = elem.Reference()
-						if err = t.SaveElement(endLevelElement); err != nil {
+						if err = t.SaveElementContext(ctx, endLevelElement); err != nil {
 							return id, err
 						}
 					}
@@ -449,7 +470,7 @@ func (t *SkipList) InsertByKey(key []byte, idIfKnown int64, value []byte) (id in
 		}
 	}
 
-	if err = t.SaveElement(elem); err != nil {
+	if err = t.SaveElementContext(ctx, elem); err != nil {
 		return id, err
 	}
 	return id, nil
@@ -459,13 +480,23 @@ func (t *SkipList) InsertByKey(key []byte, idIfKnown int64, value []byte) (id in
 // GetSmallestNode returns the very first/smallest node in the skiplist.
 // GetSmallestNode runs in O(1)
 func (t *SkipList) GetSmallestNode() (*SkipListElement, error) {
-	return t.LoadElement(t.StartLevels[0])
+	return t.GetSmallestNodeContext(context.Background())
+}
+
+// GetSmallestNodeContext is GetSmallestNode, passing ctx to the operations of a ContextListStore.
+func (t *SkipList) GetSmallestNodeContext(ctx context.Context) (*SkipListElement, error) {
+	return t.LoadElementContext(ctx, t.StartLevels[0])
 }
 
 // GetLargestNode returns the very last/largest node in the skiplist.
 // GetLargestNode runs in O(1)
 func (t *SkipList) GetLargestNode() (*SkipListElement, error) {
-	return t.LoadElement(t.EndLevels[0])
+	return t.GetLargestNodeContext(context.Background())
+}
+
+// GetLargestNodeContext is GetLargestNode, passing ctx to the operations of a ContextListStore.
+func (t *SkipList) GetLargestNodeContext(ctx context.Context) (*SkipListElement, error) {
+	return t.LoadElementContext(ctx, t.EndLevels[0])
 }
 func (t *SkipList) GetLargestNodeReference() *SkipListElementReference {
 	return t.EndLevels[0]
@@ -474,19 +505,29 @@ func (t *SkipList) GetLargestNodeReference() *SkipListElementReference {
 // Next returns the next element based on the given node.
 // Next will loop around to the first node, if you call it on the last!
 func (t *SkipList) Next(e *SkipListElement) (*SkipListElement, error) {
+	return t.NextContext(context.Background(), e)
+}
+
+// NextContext is Next, passing ctx to the operations of a ContextListStore.
+func (t *SkipList) NextContext(ctx context.Context, e *SkipListElement) (*SkipListElement, error) {
 	if e.Next[0] == nil {
-		return t.LoadElement(t.StartLevels[0])
+		return t.LoadElementContext(ctx, t.StartLevels[0])
 	}
-	return t.LoadElement(e.Next[0])
+	return t.LoadElementContext(ctx, e.Next[0])
 }
 
 // Prev returns the previous element based on the given node.
 // Prev will loop around to the last node, if you call it on the first!
 func (t *SkipList) Prev(e *SkipListElement) (*SkipListElement, error) {
+	return t.PrevContext(context.Background(), e)
+}
+
+// PrevContext is Prev, passing ctx to the operations of a ContextListStore.
+func (t *SkipList) PrevContext(ctx context.Context, e *SkipListElement) (*SkipListElement, error) {
 	if e.Prev == nil {
-		return t.LoadElement(t.EndLevels[0])
+		return t.LoadElementContext(ctx, t.EndLevels[0])
 	}
-	return t.LoadElement(e.Prev)
+	return t.LoadElementContext(ctx, e.Prev)
 }
 
 // ChangeValue can be used to change the actual value of a node in the skiplist
@@ -494,9 +535,14 @@ func (t *SkipList) Prev(e *SkipListElement) (*SkipListElement, error) {
 // Be advised, that ChangeValue only works, if the actual key from ExtractKey() will stay the same!
 // ok is an indicator, wether the value is actually changed.
 func (t *SkipList) ChangeValue(e *SkipListElement, newValue []byte) (err error) {
+	return t.ChangeValueContext(context.Background(), e, newValue)
+}
+
+// ChangeValueContext is ChangeValue, passing ctx to the operations of a ContextListStore.
+func (t *SkipList) ChangeValueContext(ctx context.Context, e *SkipListElement, newValue []byte) (err error) {
 	// The key needs to stay correct, so this is very important!
 	e.Value = newValue
-	return t.SaveElement(e)
+	return t.SaveElementContext(ctx, e)
 }
 
 // String returns a string format of the skiplist. Useful to get a graphical overview and/or debugging.
@@ -0,0 +1,142 @@
+package skiplist
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+/*
+The compact element layout is
+
+	0x00 version:byte id:varint level:uvarint key:bytes value:bytes prev:ref len(next):uvarint next:ref...
+
+where bytes are a uvarint length followed by the data, and a ref is uvarint(len(key)+1), key, pointer:varint,
+or a single 0 for a nil reference. A leading 0x00 is never a valid protobuf tag,
+so both formats can be told apart when reading.
+*/
+
+const (
+	compactElementMarker  = 0x00
+	compactElementVersion = 1
+)
+
+var errCompactElementCorrupted = errors.New("corrupted compact skiplist element")
+
+func MarshalElementCompact(element *SkipListElement) []byte {
+	buf := make([]byte, 0, 32+len(element.Key)+len(element.Value)+len(element.Next)*(len(element.Key)+4))
+	buf = append(buf, compactElementMarker, compactElementVersion)
+	buf = binary.AppendVarint(buf, element.Id)
+	buf = binary.AppendUvarint(buf, uint64(element.Level))
+	buf = appendCompactBytes(buf, element.Key)
+	buf = appendCompactBytes(buf, element.Value)
+	buf = appendCompactReference(buf, element.Prev)
+	buf = binary.AppendUvarint(buf, uint64(len(element.Next)))
+	for _, ref := range element.Next {
+		buf = appendCompactReference(buf, ref)
+	}
+	return buf
+}
+
+// UnmarshalElement reads both the compact and the protobuf element formats.
+func UnmarshalElement(data []byte, element *SkipListElement) (isCompact bool, err error) {
+	if len(data) == 0 || data[0] != compactElementMarker {
+		return false, proto.Unmarshal(data, element)
+	}
+	if len(data) < 2 || data[1] != compactElementVersion {
+		return true, fmt.Errorf("unknown compact skiplist element version")
+	}
+	r := &compactReader{data: data[2:]}
+	element.Id = r.varint()
+	element.Level = int32(r.uvarint())
+	element.Key = r.bytes()
+	element.Value = r.bytes()
+	element.Prev = r.reference()
+	nextCount := r.uvarint()
+	if r.err == nil && nextCount > uint64(len(r.data)) {
+		r.err = errCompactElementCorrupted
+	}
+	if r.err == nil {
+		element.Next = make([]*SkipListElementReference, nextCount)
+		for i := range element.Next {
+			element.Next[i] = r.reference()
+		}
+	}
+	return true, r.err
+}
+
+func appendCompactBytes(buf []byte, data []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+func appendCompactReference(buf []byte, ref *SkipListElementReference) []byte {
+	if ref.IsNil() {
+		return binary.AppendUvarint(buf, 0)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(ref.Key))+1)
+	buf = append(buf, ref.Key...)
+	return binary.AppendVarint(buf, ref.ElementPointer)
+}
+
+type compactReader struct {
+	data []byte
+	err  error
+}
+
+func (r *compactReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.err = errCompactElementCorrupted
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *compactReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errCompactElementCorrupted
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *compactReader) take(n uint64) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n > uint64(len(r.data)) {
+		r.err = errCompactElementCorrupted
+		return nil
+	}
+	v := r.data[:n:n]
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *compactReader) bytes() []byte {
+	return r.take(r.uvarint())
+}
+
+func (r *compactReader) reference() *SkipListElementReference {
+	keyLen := r.uvarint()
+	if keyLen == 0 || r.err != nil {
+		return nil
+	}
+	key := r.take(keyLen - 1)
+	return &SkipListElementReference{
+		Key:            key,
+		ElementPointer: r.varint(),
+	}
+}
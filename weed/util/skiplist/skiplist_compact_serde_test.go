@@ -0,0 +1,83 @@
+package skiplist
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCompactElementRoundTrip(t *testing.T) {
+	list := NewSeed(100, NewMemStore())
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("name%05d", i))
+		list.InsertByKey(key, 0, key)
+	}
+
+	for id, element := range list.ListStore.(*MemStore).m {
+		data := MarshalElementCompact(element)
+
+		loaded := &SkipListElement{}
+		isCompact, err := UnmarshalElement(data, loaded)
+		if err != nil || !isCompact {
+			t.Fatalf("unmarshal %d: %v", id, err)
+		}
+		if !compactEqual(element, loaded) {
+			t.Fatalf("element %d: %+v != %+v", id, element, loaded)
+		}
+
+		protoData, _ := proto.Marshal(element)
+		loaded = &SkipListElement{}
+		if isCompact, err := UnmarshalElement(protoData, loaded); err != nil || isCompact {
+			t.Fatalf("unmarshal proto %d: %v", id, err)
+		}
+		if len(data) >= len(protoData) {
+			t.Errorf("element %d: compact %d bytes, proto %d bytes", id, len(data), len(protoData))
+		}
+	}
+
+	if _, err := UnmarshalElement(MarshalElementCompact(&SkipListElement{Key: []byte("x")})[:4], &SkipListElement{}); err == nil {
+		t.Errorf("expect error on truncated data")
+	}
+}
+
+func compactEqual(a, b *SkipListElement) bool {
+	if a.Id != b.Id || a.Level != b.Level || string(a.Key) != string(b.Key) || string(a.Value) != string(b.Value) {
+		return false
+	}
+	if !referenceEqual(a.Prev, b.Prev) || len(a.Next) != len(b.Next) {
+		return false
+	}
+	for i := range a.Next {
+		if !referenceEqual(a.Next[i], b.Next[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func referenceEqual(a, b *SkipListElementReference) bool {
+	if a.IsNil() || b.IsNil() {
+		return a.IsNil() == b.IsNil()
+	}
+	return a.ElementPointer == b.ElementPointer && string(a.Key) == string(b.Key)
+}
+
+func BenchmarkElementEncodingSize(b *testing.B) {
+	list := NewSeed(100, NewMemStore())
+	for i := 0; i < 100000; i++ {
+		key := []byte(fmt.Sprintf("/buckets/some-bucket/path/to/file-%08d.jpg", i))
+		list.InsertByKey(key, 0, nil)
+	}
+	var protoSize, compactSize int
+	for i := 0; i < b.N; i++ {
+		protoSize, compactSize = 0, 0
+		for _, element := range list.ListStore.(*MemStore).m {
+			data, _ := proto.Marshal(element)
+			protoSize += len(data)
+			compactSize += len(MarshalElementCompact(element))
+		}
+	}
+	b.ReportMetric(float64(protoSize), "proto_bytes")
+	b.ReportMetric(float64(compactSize), "compact_bytes")
+}
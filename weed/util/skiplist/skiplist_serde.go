@@ -1,6 +1,9 @@
 package skiplist
 
-import "bytes"
+import (
+	"bytes"
+	"context"
+)
 
 func compareElement(a *SkipListElement, key []byte) int {
 	if len(a.Key) == 0 {
@@ -20,23 +23,47 @@ func (node *SkipListElement) Reference() *SkipListElementReference {
 }
 
 func (t *SkipList) SaveElement(element *SkipListElement) error {
+	return t.SaveElementContext(context.Background(), element)
+}
+
+// SaveElementContext is SaveElement, passing ctx to a ContextListStore
+func (t *SkipList) SaveElementContext(ctx context.Context, element *SkipListElement) error {
 	if element == nil {
 		return nil
 	}
+	if store, ok := t.ListStore.(ContextListStore); ok {
+		return store.SaveElementContext(ctx, element.Id, element)
+	}
 	return t.ListStore.SaveElement(element.Id, element)
 }
 
 func (t *SkipList) DeleteElement(element *SkipListElement) error {
+	return t.DeleteElementContext(context.Background(), element)
+}
+
+// DeleteElementContext is DeleteElement, passing ctx to a ContextListStore
+func (t *SkipList) DeleteElementContext(ctx context.Context, element *SkipListElement) error {
 	if element == nil {
 		return nil
 	}
+	if store, ok := t.ListStore.(ContextListStore); ok {
+		return store.DeleteElementContext(ctx, element.Id)
+	}
 	return t.ListStore.DeleteElement(element.Id)
 }
 
 func (t *SkipList) LoadElement(ref *SkipListElementReference) (*SkipListElement, error) {
+	return t.LoadElementContext(context.Background(), ref)
+}
+
+// LoadElementContext is LoadElement, passing ctx to a ContextListStore
+func (t *SkipList) LoadElementContext(ctx context.Context, ref *SkipListElementReference) (*SkipListElement, error) {
 	if ref.IsNil() {
 		return nil, nil
 	}
+	if store, ok := t.ListStore.(ContextListStore); ok {
+		return store.LoadElementContext(ctx, ref.ElementPointer)
+	}
 	return t.ListStore.LoadElement(ref.ElementPointer)
 }
 
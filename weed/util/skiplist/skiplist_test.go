@@ -12,7 +12,7 @@ const (
 )
 
 var (
-	memStore = newMemStore()
+	memStore = NewMemStore()
 )
 
 func TestReverseInsert(t *testing.T) {